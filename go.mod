@@ -22,6 +22,7 @@ require (
 	github.com/jarcoal/httpmock v1.0.4
 	github.com/jinzhu/now v0.0.0-20181116074157-8ec929ed50c3
 	github.com/jtblin/go-ldap-client v0.0.0-20170223121919-b73f66626b33
+	github.com/mitchellh/mapstructure v1.1.2
 	github.com/mpeter/go-towerapi v0.0.0-20160920185410-301c48b65cf7
 	github.com/mpeter/sling v0.0.0-20160821062127-52e88a7b75a5
 	github.com/patrickmn/go-cache v2.1.0+incompatible
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	apiTokenHeader = "X-API-Token"
+	// context key of the name of the API token, used for the audit log
+	apiTokenNameKey = "api_token_name"
+)
+
+// apiToken is a pre-shared token for automation (e.g. CI pipelines) which can't log in with Keycloak
+type apiToken struct {
+	// Name of the token for the audit log
+	Name  string
+	Token string
+	// Pseudo-user which is returned by common.GetUserName
+	Username string
+	// Allowed clusters. Empty allows all clusters
+	Clusters []string
+	// Allowed actions as "METHOD /api/path". A path ending with * matches all paths with this prefix
+	Actions []string
+}
+
+func getAPITokens() []apiToken {
+	tokens := []apiToken{}
+	config.UnmarshalKey("api_tokens", &tokens)
+	return tokens
+}
+
+// findAPIToken returns the configured token with a constant time comparison
+func findAPIToken(tokens []apiToken, token string) (apiToken, bool) {
+	for _, t := range tokens {
+		if t.Token != "" && subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1 {
+			return t, true
+		}
+	}
+	return apiToken{}, false
+}
+
+func (t apiToken) allowsAction(method, path string) bool {
+	for _, action := range t.Actions {
+		parts := strings.Fields(action)
+		if len(parts) != 2 {
+			continue
+		}
+		if parts[0] != "*" && !strings.EqualFold(parts[0], method) {
+			continue
+		}
+		if strings.HasSuffix(parts[1], "*") {
+			if strings.HasPrefix(path, strings.TrimSuffix(parts[1], "*")) {
+				return true
+			}
+		} else if parts[1] == path {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsCluster denies requests without a clusterid if the token is restricted to clusters,
+// because the handler might act on any cluster
+func (t apiToken) allowsCluster(clusterId string) bool {
+	if len(t.Clusters) == 0 {
+		return true
+	}
+	return clusterId != "" && common.ContainsStringI(t.Clusters, clusterId)
+}
+
+// requestClusterId returns the clusterid of the query or the JSON body. Both are read, because
+// handlers use either of them. ok is false if the query and the body name different clusters.
+func requestClusterId(c *gin.Context) (clusterId string, ok bool) {
+	queryClusterId := c.Query("clusterid")
+	bodyClusterId := ""
+	if c.Request.Body != nil && c.Request.Body != http.NoBody {
+		body, err := ioutil.ReadAll(c.Request.Body)
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if err == nil {
+			var data struct {
+				ClusterId string `json:"clusterid"`
+			}
+			json.Unmarshal(body, &data)
+			bodyClusterId = data.ClusterId
+		}
+	}
+	if queryClusterId != "" && bodyClusterId != "" && queryClusterId != bodyClusterId {
+		return queryClusterId, false
+	}
+	if queryClusterId != "" {
+		return queryClusterId, true
+	}
+	return bodyClusterId, true
+}
+
+// authenticate accepts an API token in the X-API-Token header as an alternative to the
+// Keycloak JWT. Requests with an API token are only allowed for the configured clusters and actions.
+func authenticate(jwtAuth gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.GetHeader(apiTokenHeader)
+		if token == "" {
+			jwtAuth(c)
+			return
+		}
+
+		t, ok := findAPIToken(getAPITokens(), token)
+		if !ok || t.Username == "" {
+			log.Printf("Invalid API token for %v %v", c.Request.Method, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, common.ApiResponse{Message: "Invalid API token"})
+			return
+		}
+
+		auditLog := log.WithFields(log.Fields{
+			"apiToken": t.Name,
+			"username": t.Username,
+			"method":   c.Request.Method,
+			"path":     c.Request.URL.Path,
+		})
+		clusterId, ok := requestClusterId(c)
+		if !ok || !t.allowsAction(c.Request.Method, c.Request.URL.Path) || !t.allowsCluster(clusterId) {
+			auditLog.WithField("clusterid", clusterId).Warn("API token request denied")
+			c.AbortWithStatusJSON(http.StatusForbidden, common.ApiResponse{
+				Message: "The API token is not allowed to perform this action",
+				Code:    common.ErrorCodeForbidden,
+			})
+			return
+		}
+
+		auditLog.WithField("clusterid", clusterId).Info("API token request")
+		c.Set(keycloak.APITokenUserKey, t.Username)
+		c.Set(apiTokenNameKey, t.Name)
+	}
+}
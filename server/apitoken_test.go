@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAuthenticateWithAPIToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Init("bla")
+	config.Config().Set("api_tokens", []map[string]interface{}{
+		{
+			"name":     "ci",
+			"token":    "secret",
+			"username": "svc-ci",
+			"clusters": []string{"awsdev"},
+			"actions":  []string{"GET /api/ose/*", "POST /api/ose/project"},
+		},
+	})
+
+	jwtCalled := false
+	router := gin.New()
+	router.Use(authenticate(func(c *gin.Context) {
+		jwtCalled = true
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}))
+	var username string
+	handler := func(c *gin.Context) {
+		username = common.GetUserName(c)
+	}
+	router.GET("/api/ose/projects", handler)
+	router.POST("/api/ose/project", handler)
+	router.DELETE("/api/ose/project/groups", handler)
+
+	var testsets = []struct {
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{"GET", "/api/ose/projects?clusterid=awsdev", "secret", "", http.StatusOK},
+		{"GET", "/api/ose/projects", "secret", "", http.StatusForbidden},
+		{"GET", "/api/ose/projects?clusterid=awsprod", "secret", "", http.StatusForbidden},
+		{"POST", "/api/ose/project", "secret", `{"clusterid": "awsdev", "project": "p"}`, http.StatusOK},
+		{"POST", "/api/ose/project", "secret", `{"clusterid": "awsprod", "project": "p"}`, http.StatusForbidden},
+		{"POST", "/api/ose/project?clusterid=awsdev", "secret", `{"clusterid": "awsdev", "project": "p"}`, http.StatusOK},
+		// the handler might use the cluster of the body, so both must be allowed and the same
+		{"POST", "/api/ose/project?clusterid=awsdev", "secret", `{"clusterid": "awsprod", "project": "p"}`, http.StatusForbidden},
+		{"POST", "/api/ose/project?clusterid=awsprod", "secret", `{"clusterid": "awsdev", "project": "p"}`, http.StatusForbidden},
+		{"DELETE", "/api/ose/project/groups", "secret", "", http.StatusForbidden},
+		{"GET", "/api/ose/projects", "wrong", "", http.StatusUnauthorized},
+	}
+
+	for _, set := range testsets {
+		username = ""
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(set.method, set.path, strings.NewReader(set.body))
+		req.Header.Set(apiTokenHeader, set.token)
+		router.ServeHTTP(w, req)
+
+		if w.Code != set.status {
+			t.Errorf("ERROR: %v %v %v should return %v, but returned %v", set.method, set.path, set.body, set.status, w.Code)
+		}
+		if set.status == http.StatusOK && username != "svc-ci" {
+			t.Errorf("ERROR: username should be the pseudo-user of the token, got: '%v'", username)
+		}
+	}
+	if jwtCalled {
+		t.Error("ERROR: requests with an API token should not be checked for a JWT")
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/ose/projects", nil)
+	router.ServeHTTP(w, req)
+	if !jwtCalled || w.Code != http.StatusUnauthorized {
+		t.Errorf("ERROR: requests without an API token should be checked for a JWT, got %v", w.Code)
+	}
+}
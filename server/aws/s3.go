@@ -42,11 +42,11 @@ func validateNewS3Bucket(projectname string, bucketname string, billing string,
 
 	if len(bucketname) > 63 {
 		// http://docs.aws.amazon.com/AmazonS3/latest/dev/BucketRestrictions.html
-		return errors.New("Generated Bucketname " + bucketname + " is too long")
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "Generated Bucketname "+bucketname+" is too long")
 	}
 	var validName = regexp.MustCompile(`^[a-zA-Z0-9\-]+$`).MatchString
 	if !validName(bucketname) {
-		return errors.New("Bucketname can only contain alphanumeric characters or -")
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "Bucketname can only contain alphanumeric characters or -")
 	}
 
 	svc, err := GetS3Client(stage)
@@ -96,7 +96,7 @@ func newS3BucketHandler(c *gin.Context) {
 		}
 
 		if err := validateNewS3Bucket(data.Project, newbucketname, data.Billing, data.Stage); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -35,8 +35,41 @@ type GrowVolumeCommand struct {
 
 type NewProjectCommand struct {
 	OpenshiftBase
-	Billing string `json:"billing"`
-	MegaId  string `json:"megaId"`
+	Billing   string   `json:"billing"`
+	MegaId    string   `json:"megaId"`
+	Operators []string `json:"operators"`
+	// OnBehalfOf is only allowed for members of the service desk group
+	OnBehalfOf string `json:"onBehalfOf"`
+	// LDAP group of the team which owns the project
+	OwnerGroup string `json:"ownerGroup"`
+	// Name of a project template in the config. The objects of the template are created in the project
+	Template string `json:"template"`
+	// Name of a quota tier in the config (e.g. small, medium, large)
+	QuotaTier string `json:"quotaTier"`
+	// Data classification, one of data_classifications in the config
+	Classification string `json:"classification"`
+	// Overrides the default_node_selector of the cluster. Only allowed for members of the power_user_group
+	NodeSelector string `json:"nodeSelector"`
+}
+
+// NewProjectManifestCommand creates a project from a raw ProjectRequest manifest.
+// The project name is taken from metadata.name of the manifest.
+type NewProjectManifestCommand struct {
+	ClusterId      string   `json:"clusterid"`
+	Billing        string   `json:"billing"`
+	MegaId         string   `json:"megaId"`
+	Operators      []string `json:"operators"`
+	OwnerGroup     string   `json:"ownerGroup"`
+	Classification string   `json:"classification"`
+	// ProjectRequest of the OpenShift API (project.openshift.io/v1)
+	Manifest map[string]interface{} `json:"manifest"`
+}
+
+// ProjectRequestCommand approves or rejects a pending project request
+type ProjectRequestCommand struct {
+	ID string `json:"id"`
+	// Only used for rejections, the reason is sent to the user
+	Reason string `json:"reason"`
 }
 
 type NewTestProjectCommand struct {
@@ -48,10 +81,30 @@ type EditLogseneBillingDataCommand struct {
 	Billing string `json:"billing"`
 }
 
+// UpdateProjectInformationCommand is a partial update: fields which are missing or empty
+// keep the current value of the project
 type UpdateProjectInformationCommand struct {
 	OpenshiftBase
-	Billing string `json:"billing"`
-	MegaID  string `json:"megaid"`
+	Billing        string `json:"billing"`
+	MegaID         string `json:"megaid"`
+	OwnerGroup     string `json:"ownerGroup"`
+	Classification string `json:"classification"`
+}
+
+type UpdateProjectDisplayNameCommand struct {
+	OpenshiftBase
+	DisplayName string `json:"displayName"`
+}
+
+type ProjectInformationBatchCommand struct {
+	Projects []OpenshiftBase `json:"projects"`
+}
+
+type ArchiveIdleProjectsCommand struct {
+	ClusterId string   `json:"clusterid"`
+	Projects  []string `json:"projects"`
+	// Projects which were active in the last minDays days are not archived
+	MinDays int `json:"minDays"`
 }
 
 type AddProjectAdminCommand struct {
@@ -59,6 +112,20 @@ type AddProjectAdminCommand struct {
 	Username string `json:"username"`
 }
 
+type CopyPermissionsCommand struct {
+	Source OpenshiftBase `json:"source"`
+	Target OpenshiftBase `json:"target"`
+	// merge (default) or replace
+	Mode string `json:"mode"`
+}
+
+type ProjectGroupCommand struct {
+	OpenshiftBase
+	Group string `json:"group"`
+	// admin or operator
+	Role string `json:"role"`
+}
+
 type CreateLogseneAppCommand struct {
 	AppName      string `json:"appName"`
 	DiscountCode string `json:"discountCode"`
@@ -77,12 +144,25 @@ type EditQuotasCommand struct {
 	Memory int `json:"memory"`
 }
 
+// QuotaIncreaseCommand requests a higher quota for a project. CPU in cores, memory in GiB
+type QuotaIncreaseCommand struct {
+	OpenshiftBase
+	CPU           int    `json:"cpu"`
+	Memory        int    `json:"memory"`
+	Justification string `json:"justification"`
+}
+
 type NewServiceAccountCommand struct {
 	OpenshiftBase
 	ServiceAccount  string `json:"serviceAccount"`
 	OrganizationKey string `json:"organizationKey"`
 }
 
+type NewServiceAccountTokenCommand struct {
+	OpenshiftBase
+	ServiceAccount string `json:"serviceAccount"`
+}
+
 type NewPullSecretCommand struct {
 	OpenshiftBase
 	Username string
@@ -124,6 +204,10 @@ type WorkflowExecutionProgress struct {
 
 type ApiResponse struct {
 	Message string `json:"message"`
+	// Code is only set for errors, see the ErrorCode constants
+	Code string `json:"code,omitempty"`
+	// Warnings of a successful request, e.g. conditions which didn't block a new project
+	Warnings []string `json:"warnings,omitempty"`
 }
 
 type SnapshotApiResponse struct {
@@ -183,6 +267,11 @@ type S3CredentialsResponse struct {
 	Password    string `json:"password"`
 }
 
+type ServiceAccountTokenResponse struct {
+	ServiceAccount string `json:"serviceAccount"`
+	Token          string `json:"token"`
+}
+
 type AdminList struct {
 	Admins []string `json:"admins"`
 }
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"sync"
+	"time"
+)
+
+// Cache is a map whose entries expire after their TTL. It holds at most maxEntries entries,
+// if it is full the expired entries and then the entry which expires first are removed.
+// It is safe for concurrent use.
+type Cache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	value      interface{}
+	validUntil time.Time
+}
+
+// NewCache returns an empty cache with at most maxEntries entries
+func NewCache(maxEntries int) *Cache {
+	return &Cache{maxEntries: maxEntries, entries: map[string]cacheEntry{}}
+}
+
+// Get returns the value of the key, if it is cached and not expired
+func (c *Cache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !time.Now().Before(entry.validUntil) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set caches the value for ttl
+func (c *Cache) Set(key string, value interface{}, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.evict(now)
+	}
+	c.entries[key] = cacheEntry{value: value, validUntil: now.Add(ttl)}
+}
+
+// Clear removes all entries
+func (c *Cache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]cacheEntry{}
+}
+
+// evict makes room for one entry. Must be called with mu held.
+func (c *Cache) evict(now time.Time) {
+	var (
+		oldestKey string
+		oldest    time.Time
+		found     bool
+	)
+	for key, entry := range c.entries {
+		if !now.Before(entry.validUntil) {
+			delete(c.entries, key)
+			continue
+		}
+		if !found || entry.validUntil.Before(oldest) {
+			oldestKey, oldest, found = key, entry.validUntil, true
+		}
+	}
+	if found && len(c.entries) >= c.maxEntries {
+		delete(c.entries, oldestKey)
+	}
+}
@@ -0,0 +1,39 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	c := NewCache(2)
+	c.Set("a", 1, time.Minute)
+	c.Set("b", 2, 2*time.Minute)
+	if value, ok := c.Get("a"); !ok || value != 1 {
+		t.Errorf("ERROR: a should be cached, got %v %v", value, ok)
+	}
+
+	// the entry which expires first is removed if the cache is full
+	c.Set("c", 3, 3*time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Error("ERROR: a should have been removed")
+	}
+	if _, ok := c.Get("b"); !ok {
+		t.Error("ERROR: b should still be cached")
+	}
+
+	// expired entries are removed first
+	c.Set("b", 2, -time.Second)
+	if _, ok := c.Get("b"); ok {
+		t.Error("ERROR: b should be expired")
+	}
+	c.Set("d", 4, time.Second)
+	if _, ok := c.Get("c"); !ok {
+		t.Error("ERROR: c should still be cached")
+	}
+
+	c.Clear()
+	if _, ok := c.Get("c"); ok {
+		t.Error("ERROR: the cache should be empty")
+	}
+}
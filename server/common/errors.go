@@ -0,0 +1,100 @@
+package common
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Error codes returned in ApiResponse.Code. The codes are stable and can be
+// used by the frontend to localize messages or to decide if a retry makes sense.
+const (
+	// The request is malformed or required parameters are missing.
+	// Returned with status 422 if the request is well-formed but a value is invalid (see NewValidationError)
+	ErrorCodeInvalidRequest = "INVALID_REQUEST"
+	// The accounting number is missing or invalid. Returned with status 422
+	ErrorCodeInvalidBilling = "INVALID_BILLING"
+	// The user doesn't have the required permissions
+	ErrorCodeForbidden = "FORBIDDEN"
+	// The project doesn't exist
+	ErrorCodeProjectNotFound = "PROJECT_NOT_FOUND"
+	// A project with the same name already exists. Returned with status 409
+	ErrorCodeProjectExists = "PROJECT_EXISTS"
+	// Another object with the same name already exists. Returned with status 409
+	ErrorCodeConflict = "CONFLICT"
+	// The call to a backend API (e.g. OpenShift) failed. A retry might help
+	ErrorCodeBackendError = "BACKEND_ERROR"
+	// The portal is in maintenance mode and doesn't accept changes
+	ErrorCodeMaintenance = "MAINTENANCE"
+	// A backend API rate limited the request. Retry after the Retry-After header
+	ErrorCodeRateLimited = "RATE_LIMITED"
+	// Too many concurrent requests to a backend API. A retry might help
+	ErrorCodeBackendBusy = "BACKEND_BUSY"
+	// The API of the cluster failed repeatedly and is not called for a while. Returned with status 503
+	ErrorCodeClusterUnavailable = "CLUSTER_UNAVAILABLE"
+	// The object was changed since the client read it (If-Match). Returned with status 412
+	ErrorCodePreconditionFailed = "PRECONDITION_FAILED"
+	// The object doesn't exist in the backend (e.g. a DNS zone). Returned with status 404
+	ErrorCodeNotFound = "NOT_FOUND"
+	// A quota of the backend (e.g. OTC) doesn't allow more objects. Returned with status 409
+	ErrorCodeQuotaExceeded = "QUOTA_EXCEEDED"
+)
+
+// ApiError is an error with a code for the ApiResponse
+type ApiError struct {
+	Code    string
+	Message string
+	// Value of the Retry-After header, only set for ErrorCodeRateLimited
+	RetryAfter string
+	// The request is well-formed but a value is invalid, returned with status 422 instead of 400
+	Validation bool
+}
+
+func NewApiError(code, message string) error {
+	return &ApiError{Code: code, Message: message}
+}
+
+// NewValidationError returns an error for a well-formed request with an invalid value, e.g. a project name
+// with uppercase letters. RespondError returns it with status 422 instead of 400.
+func NewValidationError(code, message string) error {
+	return &ApiError{Code: code, Message: message, Validation: true}
+}
+
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+// ErrorResponse returns the ApiResponse for the error.
+// The code is only set for ApiErrors.
+func ErrorResponse(err error) ApiResponse {
+	if apiErr, ok := err.(*ApiError); ok {
+		return ApiResponse{Message: apiErr.Message, Code: apiErr.Code}
+	}
+	return ApiResponse{Message: err.Error()}
+}
+
+// RespondError writes the ApiResponse for the error with the given status.
+// Rate limited errors are always returned with 429 and the Retry-After header,
+// busy or unavailable backends with 503 and conflicts with 409.
+// Validation errors and invalid accounting numbers are returned with 422 instead of 400.
+func RespondError(c *gin.Context, status int, err error) {
+	if apiErr, ok := err.(*ApiError); ok {
+		if status == http.StatusBadRequest && (apiErr.Validation || apiErr.Code == ErrorCodeInvalidBilling) {
+			status = http.StatusUnprocessableEntity
+		}
+		switch apiErr.Code {
+		case ErrorCodeRateLimited:
+			if apiErr.RetryAfter != "" {
+				c.Header("Retry-After", apiErr.RetryAfter)
+			}
+			status = http.StatusTooManyRequests
+		case ErrorCodeBackendBusy, ErrorCodeClusterUnavailable:
+			status = http.StatusServiceUnavailable
+		case ErrorCodeProjectExists, ErrorCodeConflict:
+			status = http.StatusConflict
+		case ErrorCodePreconditionFailed:
+			status = http.StatusPreconditionFailed
+		}
+	}
+	c.JSON(status, ErrorResponse(err))
+}
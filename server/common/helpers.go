@@ -37,6 +37,15 @@ func ContainsEmptyString(ss ...string) bool {
 	return false
 }
 
+func ContainsString(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
+
 func ContainsStringI(s []string, e string) bool {
 	for _, a := range s {
 		if strings.ToLower(a) == strings.ToLower(e) {
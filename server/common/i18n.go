@@ -0,0 +1,127 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Message IDs of the catalogs
+const (
+	MsgProjectCreated          = "project.created"
+	MsgTestProjectCreated      = "project.test.created"
+	MsgTestProjectExtended     = "project.test.extended"
+	MsgQuotaTierFailed         = "project.quota.failed"
+	MsgTemplateFailed          = "project.template.failed"
+	MsgTemplateObjectsFailed   = "project.template.objects.failed"
+	MsgTooManyProjects         = "project.info.too.many"
+	MsgProjectInformationSaved = "project.info.saved"
+	MsgProjectDisplayNameSaved = "project.displayname.saved"
+	MsgClusterIdMissing        = "request.clusterid.missing"
+	MsgProjectMissing          = "request.project.missing"
+	MsgUsernameMissing         = "request.username.missing"
+	MsgProjectAdminAdded       = "project.admin.added"
+	MsgProjectPending          = "project.pending"
+	MsgProjectRejected         = "project.rejected"
+	MsgQuotaRequested          = "quota.requested"
+	MsgQuotaApproved           = "quota.approved"
+	MsgQuotaRejected           = "quota.rejected"
+)
+
+// DefaultLanguage is used if the client doesn't send a supported Accept-Language
+const DefaultLanguage = "de"
+
+var catalogs = map[string]map[string]string{
+	"de": {
+		MsgProjectCreated:          "Das Projekt %v wurde erstellt auf Cluster %v",
+		MsgTestProjectCreated:      "Das Test-Projekt %v wurde erstellt auf Cluster %v",
+		MsgTestProjectExtended:     "Das Test-Projekt %v wird am %v gelöscht",
+		MsgQuotaTierFailed:         ". Die Quota %v konnte nicht gesetzt werden: %v",
+		MsgTemplateFailed:          ". Die Vorlage %v konnte nicht angewendet werden: %v",
+		MsgTemplateObjectsFailed:   ". Folgende Objekte der Vorlage konnten nicht erstellt werden: %v",
+		MsgTooManyProjects:         "Zu viele Projekte, das Maximum ist %v",
+		MsgProjectInformationSaved: "Die Angaben zum Projekt %v auf Cluster %v wurden gespeichert",
+		MsgProjectDisplayNameSaved: "Der Anzeigename des Projekts %v auf Cluster %v wurde gespeichert",
+		MsgClusterIdMissing:        "ClusterId muss angegeben werden",
+		MsgProjectMissing:          "Projekt muss angegeben werden",
+		MsgUsernameMissing:         "Benutzername muss angegeben werden",
+		MsgProjectAdminAdded:       "Der Benutzer %v wurde als Admin zum Projekt %v hinzugefügt",
+		MsgProjectPending:          "Das Projekt %v auf Cluster %v wurde beantragt und muss noch bewilligt werden",
+		MsgProjectRejected:         "Der Antrag für das Projekt %v auf Cluster %v wurde abgelehnt",
+		MsgQuotaRequested:          "Die Erhöhung der Quota von Projekt %v auf Cluster %v wurde beantragt und muss noch bewilligt werden",
+		MsgQuotaApproved:           "Die neue Quota von Projekt %v auf Cluster %v wurde gesetzt",
+		MsgQuotaRejected:           "Der Antrag für die Quota von Projekt %v auf Cluster %v wurde abgelehnt",
+	},
+	"en": {
+		MsgProjectCreated:          "The project %v has been created on cluster %v",
+		MsgTestProjectCreated:      "The test project %v has been created on cluster %v",
+		MsgTestProjectExtended:     "The test project %v will be deleted on %v",
+		MsgQuotaTierFailed:         ". The quota %v could not be set: %v",
+		MsgTemplateFailed:          ". The template %v could not be applied: %v",
+		MsgTemplateObjectsFailed:   ". The following objects of the template could not be created: %v",
+		MsgTooManyProjects:         "Too many projects, the maximum is %v",
+		MsgProjectInformationSaved: "The details for project %v on cluster %v have been saved",
+		MsgProjectDisplayNameSaved: "The display name of project %v on cluster %v has been saved",
+		MsgClusterIdMissing:        "ClusterId must be provided",
+		MsgProjectMissing:          "Project must be provided",
+		MsgUsernameMissing:         "Username must be provided",
+		MsgProjectAdminAdded:       "The user %v has been successfully added to the %v project",
+		MsgProjectPending:          "The project %v on cluster %v has been requested and must be approved",
+		MsgProjectRejected:         "The request for project %v on cluster %v has been rejected",
+		MsgQuotaRequested:          "The quota increase of project %v on cluster %v has been requested and must be approved",
+		MsgQuotaApproved:           "The new quota of project %v on cluster %v has been set",
+		MsgQuotaRejected:           "The quota request of project %v on cluster %v has been rejected",
+	},
+}
+
+// GetLanguage returns the supported language with the highest weight in the Accept-Language header,
+// e.g. "en-US,en;q=0.9,de;q=0.8" returns en. Without a supported language, DefaultLanguage is returned.
+func GetLanguage(c *gin.Context) string {
+	type weightedLanguage struct {
+		lang   string
+		weight float64
+	}
+	var languages []weightedLanguage
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.ToLower(strings.SplitN(fields[0], "-", 2)[0])
+		if _, ok := catalogs[lang]; !ok {
+			continue
+		}
+		weight := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if q, err := strconv.ParseFloat(param[2:], 64); err == nil {
+					weight = q
+				}
+			}
+		}
+		if weight > 0 {
+			languages = append(languages, weightedLanguage{lang, weight})
+		}
+	}
+	if len(languages) == 0 {
+		return DefaultLanguage
+	}
+	// Stable, so that the order of the header decides between equal weights
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].weight > languages[j].weight
+	})
+	return languages[0].lang
+}
+
+// T returns the message in the language of the request. The args are inserted like with fmt.Sprintf.
+func T(c *gin.Context, id string, args ...interface{}) string {
+	message, ok := catalogs[GetLanguage(c)][id]
+	if !ok {
+		message, ok = catalogs[DefaultLanguage][id]
+	}
+	if !ok {
+		return id
+	}
+	return fmt.Sprintf(message, args...)
+}
@@ -0,0 +1,61 @@
+package common
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetLanguage(t *testing.T) {
+	var testsets = []struct {
+		acceptLanguage string
+		lang           string
+	}{
+		{"", "de"},
+		{"fr-CH, fr;q=0.9", "de"},
+		{"en-US,en;q=0.9,de;q=0.8", "en"},
+		{"de-CH,de;q=0.9,en;q=0.8", "de"},
+		{"fr, en;q=0.5, de;q=0.7", "de"},
+		{"en;q=0", "de"},
+	}
+	for _, set := range testsets {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/", nil)
+		c.Request.Header.Set("Accept-Language", set.acceptLanguage)
+		if lang := GetLanguage(c); lang != set.lang {
+			t.Errorf("ERROR: language of '%v' should be %v, but is %v", set.acceptLanguage, set.lang, lang)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/", nil)
+	if msg := T(c, MsgProjectCreated, "p", "awsdev"); msg != "Das Projekt p wurde erstellt auf Cluster awsdev" {
+		t.Errorf("ERROR: unexpected German message: %v", msg)
+	}
+
+	c.Request.Header.Set("Accept-Language", "en")
+	if msg := T(c, MsgProjectCreated, "p", "awsdev"); msg != "The project p has been created on cluster awsdev" {
+		t.Errorf("ERROR: unexpected English message: %v", msg)
+	}
+	if msg := T(c, "unknown"); msg != "unknown" {
+		t.Errorf("ERROR: unknown IDs should be returned unchanged, got: %v", msg)
+	}
+}
+
+func TestCatalogsHaveTheSameMessages(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for id := range catalogs[DefaultLanguage] {
+			if _, ok := catalog[id]; !ok {
+				t.Errorf("ERROR: message %v is missing in the %v catalog", id, lang)
+			}
+		}
+		for id := range catalog {
+			if _, ok := catalogs[DefaultLanguage][id]; !ok {
+				t.Errorf("ERROR: message %v of the %v catalog is missing in the default catalog", id, lang)
+			}
+		}
+	}
+}
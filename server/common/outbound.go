@@ -0,0 +1,49 @@
+package common
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultOutboundTimeout is used for calls to other services if no timeout is configured
+const DefaultOutboundTimeout = 5 * time.Second
+
+var (
+	// The transport is created on the first call, so the outbound_proxy is read once
+	outboundTransportOnce sync.Once
+	outboundTransport     *http.Transport
+	outboundTransportErr  error
+
+	outboundClientsMu sync.Mutex
+	outboundClients   = map[time.Duration]*http.Client{}
+)
+
+// GetOutboundClient returns the client for calls to other services (SAP, CMDB, webhooks...) through the outbound proxy.
+// A timeout <= 0 is replaced by DefaultOutboundTimeout, so a service which doesn't answer can't block a request.
+// There is one client per timeout and all clients share the transport, so the connections are reused.
+func GetOutboundClient(timeout time.Duration) (*http.Client, error) {
+	outboundTransportOnce.Do(func() {
+		proxy, err := GetProxyFunc("")
+		if err != nil {
+			outboundTransportErr = err
+			return
+		}
+		outboundTransport = &http.Transport{Proxy: proxy}
+	})
+	if outboundTransportErr != nil {
+		return nil, outboundTransportErr
+	}
+	if timeout <= 0 {
+		timeout = DefaultOutboundTimeout
+	}
+
+	outboundClientsMu.Lock()
+	defer outboundClientsMu.Unlock()
+	client, ok := outboundClients[timeout]
+	if !ok {
+		client = &http.Client{Timeout: timeout, Transport: outboundTransport}
+		outboundClients[timeout] = client
+	}
+	return client, nil
+}
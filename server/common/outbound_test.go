@@ -0,0 +1,29 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestGetOutboundClient(t *testing.T) {
+	config.Init("bla")
+	client, err := GetOutboundClient(0)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if client.Timeout != DefaultOutboundTimeout {
+		t.Errorf("ERROR: the default timeout should be used, got: %v", client.Timeout)
+	}
+	if same, _ := GetOutboundClient(DefaultOutboundTimeout); same != client {
+		t.Error("ERROR: the client of a timeout should be reused")
+	}
+	other, err := GetOutboundClient(time.Second)
+	if err != nil || other == client || other.Timeout != time.Second {
+		t.Errorf("ERROR: another timeout should get its own client, got: %v (error: %v)", other, err)
+	}
+	if other.Transport != client.Transport {
+		t.Error("ERROR: the clients should share the transport")
+	}
+}
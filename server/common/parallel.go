@@ -0,0 +1,37 @@
+package common
+
+import "sync"
+
+// Parallel calls fn for every index from 0 to n-1 with at most workers calls at the same time.
+// Indexes which haven't been started are skipped once stop is closed (stop may be nil).
+// Parallel returns when all started calls have returned.
+func Parallel(n, workers int, stop <-chan struct{}, fn func(i int)) {
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int, n)
+	for i := 0; i < n; i++ {
+		jobs <- i
+	}
+	close(jobs)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
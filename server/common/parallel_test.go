@@ -0,0 +1,44 @@
+package common
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParallel(t *testing.T) {
+	var running, maxRunning int32
+	done := make([]bool, 10)
+	Parallel(len(done), 3, nil, func(i int) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		// every call writes its own index
+		done[i] = true
+	})
+	for i, ok := range done {
+		if !ok {
+			t.Errorf("ERROR: index %v was not called", i)
+		}
+	}
+	if maxRunning > 3 {
+		t.Errorf("ERROR: at most 3 calls should run at the same time, but %v did", maxRunning)
+	}
+
+	stop := make(chan struct{})
+	var calls int32
+	Parallel(10, 1, stop, func(i int) {
+		if atomic.AddInt32(&calls, 1) == 2 {
+			close(stop)
+		}
+	})
+	if calls != 2 {
+		t.Errorf("ERROR: no calls should start after stop, got %v calls", calls)
+	}
+}
@@ -0,0 +1,48 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+// ParseProxyURL parses and validates the URL of an HTTP proxy
+func ParseProxyURL(proxy string) (*url.URL, error) {
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid proxy URL %v: %v", proxy, err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" {
+		return nil, fmt.Errorf("Invalid proxy URL %v: scheme must be http or https", proxy)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("Invalid proxy URL %v: host is missing", proxy)
+	}
+	return proxyURL, nil
+}
+
+// GetProxyFunc returns the proxy for outbound calls to use in http.Transport.
+// The proxy is taken from the first of: the given override (e.g. of a cluster),
+// the outbound_proxy config and the HTTPS_PROXY/HTTP_PROXY/NO_PROXY environment variables.
+func GetProxyFunc(override string) (func(*http.Request) (*url.URL, error), error) {
+	proxy := override
+	if proxy == "" {
+		proxy = config.Config().GetString("outbound_proxy")
+	}
+	if proxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxyURL, err := ParseProxyURL(proxy)
+	if err != nil {
+		return nil, err
+	}
+	return http.ProxyURL(proxyURL), nil
+}
+
+// ValidateProxy checks the outbound_proxy config at startup
+func ValidateProxy() error {
+	_, err := GetProxyFunc("")
+	return err
+}
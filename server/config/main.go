@@ -1,27 +1,148 @@
 package config
 
 import (
-	"log"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/mitchellh/mapstructure"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/viper"
 )
 
 var config *viper.Viper
 
+var envKeyReplacer = strings.NewReplacer(".", "_")
+
+// ProfileEnv selects the profile overlay, e.g. APP_PROFILE=prod loads config.prod.yaml
+// on top of config.yaml
+const ProfileEnv = "APP_PROFILE"
+
 // Init is an exported method that takes the environment starts the viper
-// (external lib) and returns the configuration struct.
+// (external lib) and returns the configuration struct. It exits if the
+// overlay of an active profile can't be loaded.
 func Init(env string) {
-	config = viper.New()
-	config.SetConfigType("yaml")
+	config = newViper()
 	config.SetConfigName("config")
 	config.AddConfigPath(".")
 	config.AddConfigPath("/etc/")
-	config.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
-	config.AutomaticEnv()
 	if err := config.ReadInConfig(); err != nil {
 		log.Println("WARNING: could not load configuration file. Using ENV variables")
 	}
+	if err := loadProfile(); err != nil {
+		log.Fatalf("Could not load profile %v: %v", Profile(), err)
+	}
+}
+
+// loadProfile merges the overlay of the active profile next to the loaded config file,
+// or from the search paths of Init if no config file was found
+func loadProfile() error {
+	profile := Profile()
+	if profile == "" {
+		return nil
+	}
+	paths := []string{"config." + profile + ".yaml", "/etc/config." + profile + ".yaml"}
+	if base := config.ConfigFileUsed(); base != "" {
+		paths = []string{profilePath(base, profile)}
+	}
+	return mergeProfile(paths)
+}
+
+// InitFile reads the configuration from the given yaml file and the overlay of the profile
+// next to it. ENV variables still take precedence over the values in the files.
+func InitFile(path string) error {
+	config = newViper()
+	config.SetConfigFile(path)
+	if err := config.ReadInConfig(); err != nil {
+		return err
+	}
+	if profile := Profile(); profile != "" {
+		return mergeProfile([]string{profilePath(path, profile)})
+	}
+	return nil
+}
+
+// Profile returns the active profile from APP_PROFILE or an empty string
+func Profile() string {
+	return os.Getenv(ProfileEnv)
+}
+
+// profilePath returns the path of the profile overlay, e.g. /etc/config.prod.yaml for /etc/config.yaml
+func profilePath(base, profile string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + profile + ext
+}
+
+// mergeProfile merges the first existing file of paths into the config
+func mergeProfile(paths []string) error {
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		return config.MergeConfig(f)
+	}
+	return fmt.Errorf("profile configuration %v not found", strings.Join(paths, ", "))
+}
+
+// Keys which must be set if a profile is active
+var requiredKeys = []string{"sso_url", "sso_realm", "ldap.host", "ldap.base", "ldap.dn", "ldap.password", "openshift"}
+
+// ValidateRequired checks that all required keys are set
+func ValidateRequired() error {
+	var missing []string
+	for _, key := range requiredKeys {
+		if !config.IsSet(key) {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("Required configuration missing: %v", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func newViper() *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.SetEnvKeyReplacer(envKeyReplacer)
+	v.AutomaticEnv()
+	return v
+}
+
+// LogSources logs for every key if it was set from the ENV or the config file.
+// The values are not logged, because they contain secrets.
+func LogSources() {
+	for _, key := range config.AllKeys() {
+		source := "file"
+		if _, ok := os.LookupEnv(strings.ToUpper(envKeyReplacer.Replace(key))); ok {
+			source = "env"
+		}
+		log.Debugf("Config key %v was set from %v", key, source)
+	}
+}
+
+// UnmarshalKey decodes the value of key into rawVal like viper's UnmarshalKey.
+// Unlike viper it doesn't rewrite the keys of the config maps afterwards, so it
+// can be called concurrently, e.g. from the workers of a batch request.
+func UnmarshalKey(key string, rawVal interface{}) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		Result:           rawVal,
+		WeaklyTypedInput: true,
+		DecodeHook: mapstructure.ComposeDecodeHookFunc(
+			mapstructure.StringToTimeDurationHookFunc(),
+			mapstructure.StringToSliceHookFunc(","),
+		),
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(config.Get(key))
 }
 
 func Config() *viper.Viper {
@@ -0,0 +1,104 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestInitFileWithProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	ioutil.WriteFile(path, []byte("server_port: 8000\ngin_mode: debug\nsso_realm: base\n"), 0600)
+	ioutil.WriteFile(filepath.Join(dir, "config.prod.yaml"), []byte("gin_mode: release\nsso_realm: prod\n"), 0600)
+
+	os.Setenv(ProfileEnv, "prod")
+	os.Setenv("SSO_REALM", "env")
+	defer os.Unsetenv(ProfileEnv)
+	defer os.Unsetenv("SSO_REALM")
+
+	if err := InitFile(path); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if v := Config().GetString("server_port"); v != "8000" {
+		t.Errorf("ERROR: server_port should be from the base config, but is: '%v'", v)
+	}
+	if v := Config().GetString("gin_mode"); v != "release" {
+		t.Errorf("ERROR: gin_mode should be from the profile, but is: '%v'", v)
+	}
+	if v := Config().GetString("sso_realm"); v != "env" {
+		t.Errorf("ERROR: sso_realm should be from the ENV, but is: '%v'", v)
+	}
+	if err := ValidateRequired(); err == nil {
+		t.Error("ERROR: sso_url, ldap and openshift are missing, ValidateRequired should fail")
+	}
+	for _, key := range []string{"sso_url", "ldap.host", "ldap.base", "ldap.dn", "ldap.password", "openshift"} {
+		Config().Set(key, "value")
+	}
+	if err := ValidateRequired(); err != nil {
+		t.Errorf("ERROR: all required keys are set, but ValidateRequired failed: %v", err)
+	}
+
+	os.Setenv(ProfileEnv, "unknown")
+	if err := InitFile(path); err == nil {
+		t.Error("ERROR: a missing profile configuration should fail")
+	}
+}
+
+func TestInitWithMissingProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	wd, _ := os.Getwd()
+	defer os.Chdir(wd)
+	os.Chdir(dir)
+	ioutil.WriteFile("config.yaml", []byte("gin_mode: debug\n"), 0600)
+	ioutil.WriteFile("config.prod.yaml", []byte("gin_mode: release\n"), 0600)
+	defer os.Unsetenv(ProfileEnv)
+
+	os.Setenv(ProfileEnv, "prod")
+	Init("test")
+	if v := Config().GetString("gin_mode"); v != "release" {
+		t.Errorf("ERROR: gin_mode should be from the profile, but is: '%v'", v)
+	}
+
+	os.Setenv(ProfileEnv, "unknown")
+	if err := loadProfile(); err == nil {
+		t.Error("ERROR: a missing profile configuration should fail")
+	}
+}
+
+func TestUnmarshalKey(t *testing.T) {
+	config = newViper()
+	config.Set("breaker", map[string]interface{}{"Open_Timeout": "30s", "threshold": "3"})
+
+	type breakerConfig struct {
+		OpenTimeout time.Duration `mapstructure:"open_timeout"`
+		Threshold   int
+	}
+	// The workers of the batch requests decode the config concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var cfg breakerConfig
+			if err := UnmarshalKey("breaker", &cfg); err != nil {
+				t.Errorf("ERROR: unexpected error: %v", err)
+			}
+			if cfg.OpenTimeout != 30*time.Second || cfg.Threshold != 3 {
+				t.Errorf("ERROR: unexpected config: %+v", cfg)
+			}
+		}()
+	}
+	wg.Wait()
+}
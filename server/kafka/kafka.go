@@ -14,7 +14,7 @@ type KafkaConfig struct {
 
 func getKafkaConfig() KafkaConfig {
 	kafkaConfig := KafkaConfig{}
-	err := config.Config().UnmarshalKey("kafka", &kafkaConfig)
+	err := config.UnmarshalKey("kafka", &kafkaConfig)
 
 	if err != nil {
 		log.Println("Error unmarshalling kafka config.", err.Error())
@@ -28,6 +28,9 @@ var VarianceTimer time.Duration = 30000 * time.Millisecond
 var Transport = http.Transport{}
 var publicKeyCache = cache.New(8*time.Hour, 8*time.Hour)
 
+// errNoKeyFound is returned if the SSO server doesn't know the key of the token
+var errNoKeyFound = errors.New("no key found")
+
 // TokenContainer stores all relevant token information
 type TokenContainer struct {
 	Token         *oauth2.Token
@@ -80,7 +83,13 @@ func extractToken(r *http.Request) (*oauth2.Token, error) {
 	return &oauth2.Token{AccessToken: th[1], TokenType: th[0]}, nil
 }
 
+// APITokenUserKey is the context key of the pseudo-user of a request authenticated with an API token
+const APITokenUserKey = "api_token_user"
+
 func GetUserName(ctx *gin.Context) string {
+	if user := ctx.GetString(APITokenUserKey); user != "" {
+		return user
+	}
 	tokenContainer, ok := getTokenContainer(ctx)
 	if !ok {
 		return ""
@@ -185,21 +194,42 @@ func getPublicKey(keyId string) (string, string, error) {
 
 	}
 
-	return "", "", errors.New("no key found")
+	return "", "", errNoKeyFound
 }
 
 func decodeToken(token *oauth2.Token) (*KeyCloakToken, error) {
+	keyCloakToken, _, err := parseToken(token.AccessToken)
+	return keyCloakToken, err
+}
+
+// Reasons why a token is invalid
+const (
+	tokenMalformed    = "malformed"
+	tokenBadSignature = "bad_signature"
+	tokenExpired      = "expired"
+	tokenKeyLookup    = "key_lookup_failed"
+)
+
+// parseToken verifies the signature of the token and returns the claims.
+// If the token is invalid, the reason is one of the token* constants.
+func parseToken(accessToken string) (*KeyCloakToken, string, error) {
 	keyCloakToken := KeyCloakToken{}
 	var err error
-	parsedJWT, err := jwt.ParseSigned(token.AccessToken)
+	parsedJWT, err := jwt.ParseSigned(accessToken)
 	if err != nil {
 		log.Errorf("[Gin-OAuth] jwt not decodable: %s", err)
-		return nil, err
+		return nil, tokenMalformed, err
+	}
+	if len(parsedJWT.Headers) == 0 {
+		return nil, tokenMalformed, errors.New("jwt has no header")
 	}
 	n, e, err := getPublicKey(parsedJWT.Headers[0].KeyID)
 	if err != nil {
 		log.Errorf("Failed to get publickey %v", err)
-		return nil, err
+		if err == errNoKeyFound {
+			return nil, tokenBadSignature, err
+		}
+		return nil, tokenKeyLookup, err
 	}
 	num, _ := base64.RawURLEncoding.DecodeString(n)
 
@@ -213,9 +243,9 @@ func decodeToken(token *oauth2.Token) (*KeyCloakToken, error) {
 	err = parsedJWT.Claims(&key, &keyCloakToken)
 	if err != nil {
 		log.Errorf("Failed to get claims JWT:%+v", err)
-		return nil, err
+		return nil, tokenBadSignature, err
 	}
-	return &keyCloakToken, nil
+	return &keyCloakToken, "", nil
 }
 
 func isExpired(token *KeyCloakToken) bool {
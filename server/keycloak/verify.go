@@ -0,0 +1,63 @@
+package keycloak
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+type verifyTokenCommand struct {
+	Token string `json:"token"`
+}
+
+type verifyTokenResponse struct {
+	Valid bool `json:"valid"`
+	// Only set for invalid tokens: malformed, bad_signature, expired or key_lookup_failed
+	Reason    string         `json:"reason,omitempty"`
+	ExpiresAt string         `json:"expiresAt,omitempty"`
+	Claims    *KeyCloakToken `json:"claims,omitempty"`
+}
+
+// VerifyTokenHandler verifies a token for other services, e.g. an API gateway.
+// The token is taken from the body ({"token": "..."}) or the Authorization header.
+func VerifyTokenHandler(c *gin.Context) {
+	var data verifyTokenCommand
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&data); err != nil {
+			c.JSON(http.StatusUnauthorized, verifyTokenResponse{Reason: tokenMalformed})
+			return
+		}
+	}
+	if data.Token == "" {
+		if token, err := extractToken(c.Request); err == nil {
+			data.Token = token.AccessToken
+		}
+	}
+	if data.Token == "" {
+		c.JSON(http.StatusUnauthorized, verifyTokenResponse{Reason: tokenMalformed})
+		return
+	}
+
+	token, reason, err := parseToken(data.Token)
+	if err != nil {
+		log.Debugf("Token verification failed: %v", err)
+		status := http.StatusUnauthorized
+		if reason == tokenKeyLookup {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, verifyTokenResponse{Reason: reason})
+		return
+	}
+	if isExpired(token) {
+		c.JSON(http.StatusUnauthorized, verifyTokenResponse{Reason: tokenExpired})
+		return
+	}
+
+	response := verifyTokenResponse{Valid: true, Claims: token}
+	if token.Exp != 0 {
+		response.ExpiresAt = time.Unix(token.Exp, 0).UTC().Format(time.RFC3339)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,97 @@
+package keycloak
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+func signTestToken(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", kid))
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	return token
+}
+
+func TestVerifyTokenHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Init("test")
+	config.Config().Set("sso_url", "")
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	keys := []map[string]string{{
+		"kid": "known",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}
+	publicKeyCache.Set("known", keys, 0)
+	publicKeyCache.Set("unknown", keys, 0)
+	defer publicKeyCache.Flush()
+
+	valid := signTestToken(t, key, "known", map[string]interface{}{"preferred_username": "u123456", "exp": time.Now().Add(time.Hour).Unix()})
+	expired := signTestToken(t, key, "known", map[string]interface{}{"preferred_username": "u123456", "exp": time.Now().Add(-time.Hour).Unix()})
+	unknownKey := signTestToken(t, key, "unknown", map[string]interface{}{"preferred_username": "u123456"})
+	uncachedKey := signTestToken(t, key, "uncached", map[string]interface{}{"preferred_username": "u123456"})
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantReason string
+	}{
+		{name: "valid", body: `{"token": "` + valid + `"}`, wantStatus: http.StatusOK},
+		{name: "malformed body", body: `{"token": `, wantStatus: http.StatusUnauthorized, wantReason: tokenMalformed},
+		{name: "missing token", body: `{}`, wantStatus: http.StatusUnauthorized, wantReason: tokenMalformed},
+		{name: "malformed token", body: `{"token": "abc"}`, wantStatus: http.StatusUnauthorized, wantReason: tokenMalformed},
+		{name: "no key found", body: `{"token": "` + unknownKey + `"}`, wantStatus: http.StatusUnauthorized, wantReason: tokenBadSignature},
+		{name: "expired", body: `{"token": "` + expired + `"}`, wantStatus: http.StatusUnauthorized, wantReason: tokenExpired},
+		{name: "key lookup failed", body: `{"token": "` + uncachedKey + `"}`, wantStatus: http.StatusServiceUnavailable, wantReason: tokenKeyLookup},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest("POST", "/verify_token", strings.NewReader(tt.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			VerifyTokenHandler(c)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("ERROR: status should be %v, but is: %v", tt.wantStatus, w.Code)
+			}
+			var response verifyTokenResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Fatalf("ERROR: invalid response: %v", err)
+			}
+			if response.Reason != tt.wantReason {
+				t.Errorf("ERROR: reason should be %q, but is: %q", tt.wantReason, response.Reason)
+			}
+			if response.Valid != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("ERROR: wrong valid: %v", response.Valid)
+			}
+			if response.Valid && response.Claims.PreferredUsername != "u123456" {
+				t.Errorf("ERROR: wrong claims: %+v", response.Claims)
+			}
+		})
+	}
+}
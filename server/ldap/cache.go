@@ -0,0 +1,31 @@
+package ldap
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// Group memberships per username. The TTL is set per entry from ldap.cache_ttl
+var groupCache = cache.New(5*time.Minute, 10*time.Minute)
+
+func getCachedGroups(username string) ([]string, bool) {
+	groups, ok := groupCache.Get(username)
+	if !ok {
+		return nil, false
+	}
+	return append([]string{}, groups.([]string)...), true
+}
+
+func setCachedGroups(username string, groups []string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	groupCache.Set(username, append([]string{}, groups...), ttl)
+}
+
+// InvalidateGroupCache removes the cached group memberships of the user,
+// e.g. after the user was added to a group
+func InvalidateGroupCache(username string) {
+	groupCache.Delete(username)
+}
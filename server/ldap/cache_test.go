@@ -0,0 +1,61 @@
+package ldap
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestGroupCache(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttl        time.Duration
+		wait       time.Duration
+		invalidate bool
+		wantCached bool
+	}{
+		{name: "cached", ttl: time.Minute, wantCached: true},
+		{name: "expired", ttl: 20 * time.Millisecond, wait: 40 * time.Millisecond},
+		{name: "ttl 0 disables the cache", ttl: 0},
+		{name: "invalidated", ttl: time.Minute, invalidate: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			username := "cache-" + tt.name
+			groups := []string{"group1", "group2"}
+			setCachedGroups(username, groups, tt.ttl)
+			// The cache keeps its own copy
+			groups[0] = "changed"
+
+			time.Sleep(tt.wait)
+			if tt.invalidate {
+				InvalidateGroupCache(username)
+			}
+
+			cached, ok := getCachedGroups(username)
+			if ok != tt.wantCached {
+				t.Fatalf("ERROR: cached should be %v, but is: %v", tt.wantCached, ok)
+			}
+			if ok && !reflect.DeepEqual(cached, []string{"group1", "group2"}) {
+				t.Errorf("ERROR: wrong cached groups: %v", cached)
+			}
+		})
+	}
+}
+
+func TestCacheTTLDefault(t *testing.T) {
+	config.Init("bla")
+	ldapConfig := map[string]interface{}{"host": "ldap", "base": "dc=ch", "dn": "cn=reader", "password": "secret"}
+	config.Config().Set("ldap", ldapConfig)
+	if l, err := New(); err != nil || l.CacheTTL != defaultCacheTTL {
+		t.Errorf("ERROR: the cache_ttl should default to %v, got: %+v (error: %v)", defaultCacheTTL, l, err)
+	}
+
+	ldapConfig["cache_ttl"] = "0s"
+	config.Config().Set("ldap", ldapConfig)
+	if l, err := New(); err != nil || l.CacheTTL != 0 {
+		t.Errorf("ERROR: cache_ttl 0 should disable the cache, got: %+v (error: %v)", l, err)
+	}
+}
@@ -2,7 +2,10 @@ package ldap
 
 import (
 	"crypto/tls"
+	"errors"
 	"fmt"
+	"time"
+
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
 	log "github.com/sirupsen/logrus"
@@ -10,6 +13,9 @@ import (
 	"gopkg.in/ldap.v2"
 )
 
+// ErrUserNotFound is returned if there is no LDAP user with the given name
+var ErrUserNotFound = errors.New("LDAP user not found")
+
 type LDAPClient struct {
 	Conn         *ldap.Conn
 	Host         string
@@ -18,107 +24,152 @@ type LDAPClient struct {
 	BindPassword string `mapstructure:"password"`
 	GroupFilter  string // e.g. "(memberUid=%s)"
 	UserFilter   string // e.g. "(uid=%s)"
-	Base         string
-	Attributes   []string
-	ADDomainName string // ActiveDirectory domain name "example.com"
+	// GroupNameFilter is used to look up a group by its name, e.g. "(&(objectClass=group)(cn=%s))"
+	GroupNameFilter string
+	Base            string
+	Attributes      []string
+	ADDomainName    string // ActiveDirectory domain name "example.com"
 
 	UseSSL             bool
 	InsecureSkipVerify bool
 	ServerName         string
 	SkipTLS            bool
 	ClientCertificates []tls.Certificate
+	// Number of idle connections that are kept for reuse
+	PoolSize int `mapstructure:"pool_size"`
+	// How long the group memberships of a user are cached (default 1m). 0 disables the cache
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
 }
 
+const defaultCacheTTL = time.Minute
+
 func New() (*LDAPClient, error) {
-	var ldapclient LDAPClient
-	l := config.Config().Sub("ldap")
-	if l == nil {
+	if config.Config().Get("ldap") == nil {
 		return nil, fmt.Errorf("LDAP configuration missing. Must set host, base, dn and password!")
 	}
-	l.SetDefault("Port", 389)
-	l.SetDefault("UseSSL", false)
-	l.SetDefault("SkipTLS", true)
-	l.SetDefault("UserFilter", "(cn=%s)")
-
-	if !(l.IsSet("host") && l.IsSet("base") && l.IsSet("dn") && l.IsSet("password")) {
-		return nil, fmt.Errorf("LDAP configuration incomplete. Must set host, base, dn and password!")
+	ldapclient := LDAPClient{
+		Port:            389,
+		UseSSL:          false,
+		SkipTLS:         true,
+		UserFilter:      "(cn=%s)",
+		GroupNameFilter: "(&(objectClass=group)(cn=%s))",
+		PoolSize:        5,
+		CacheTTL:        defaultCacheTTL,
 	}
-	if err := l.Unmarshal(&ldapclient); err != nil {
+	if err := config.UnmarshalKey("ldap", &ldapclient); err != nil {
 		return nil, err
 	}
+	if ldapclient.Host == "" || ldapclient.Base == "" || ldapclient.BindDN == "" || ldapclient.BindPassword == "" {
+		return nil, fmt.Errorf("LDAP configuration incomplete. Must set host, base, dn and password!")
+	}
 	return &ldapclient, nil
 }
 
-// Connect connects to the ldap backend
+// Connect takes a connection from the pool or connects to the ldap backend
 func (lc *LDAPClient) Connect() error {
 	if lc.Conn == nil {
-		var l *ldap.Conn
-		var err error
-		address := fmt.Sprintf("%s:%d", lc.Host, lc.Port)
-		if !lc.UseSSL {
-			l, err = ldap.Dial("tcp", address)
-			if err != nil {
-				return err
-			}
+		if conn := getPooledConn(lc.address()); conn != nil {
+			lc.Conn = conn
+			return nil
+		}
+		l, err := lc.dial()
+		if err != nil {
+			return err
+		}
+		lc.Conn = l
+	}
+	return nil
+}
 
-			// Reconnect with TLS
-			if !lc.SkipTLS {
-				err = l.StartTLS(&tls.Config{InsecureSkipVerify: true})
-				if err != nil {
-					return err
-				}
-			}
-		} else {
-			config := &tls.Config{
-				InsecureSkipVerify: lc.InsecureSkipVerify,
-				ServerName:         lc.ServerName,
-			}
+func (lc *LDAPClient) address() string {
+	return fmt.Sprintf("%s:%d", lc.Host, lc.Port)
+}
 
-			if lc.ClientCertificates != nil && len(lc.ClientCertificates) > 0 {
-				config.Certificates = lc.ClientCertificates
-			}
+func (lc *LDAPClient) dial() (*ldap.Conn, error) {
+	var l *ldap.Conn
+	var err error
+	address := lc.address()
+	if !lc.UseSSL {
+		l, err = ldap.Dial("tcp", address)
+		if err != nil {
+			return nil, err
+		}
 
-			l, err = ldap.DialTLS("tcp", address, config)
+		// Reconnect with TLS
+		if !lc.SkipTLS {
+			err = l.StartTLS(&tls.Config{InsecureSkipVerify: true})
 			if err != nil {
-				return err
+				l.Close()
+				return nil, err
 			}
 		}
+	} else {
+		config := &tls.Config{
+			InsecureSkipVerify: lc.InsecureSkipVerify,
+			ServerName:         lc.ServerName,
+		}
 
-		lc.Conn = l
+		if lc.ClientCertificates != nil && len(lc.ClientCertificates) > 0 {
+			config.Certificates = lc.ClientCertificates
+		}
+
+		l, err = ldap.DialTLS("tcp", address, config)
+		if err != nil {
+			return nil, err
+		}
 	}
-	return nil
+	return l, nil
 }
 
-// Close closes the ldap backend connection
+// connectAndBind connects and binds with the read only user.
+// The bind also works as health check: pooled connections which were
+// dropped by the server are replaced with a new connection.
+func (lc *LDAPClient) connectAndBind() error {
+	if err := lc.Connect(); err != nil {
+		return err
+	}
+	if lc.BindDN == "" || lc.BindPassword == "" {
+		return nil
+	}
+	err := lc.Conn.Bind(lc.BindDN, lc.BindPassword)
+	if err != nil && ldap.IsErrorWithCode(err, ldap.ErrorNetwork) {
+		log.Warn("LDAP connection was closed, reconnecting")
+		lc.Conn.Close()
+		lc.Conn = nil
+		if err := lc.Connect(); err != nil {
+			return err
+		}
+		err = lc.Conn.Bind(lc.BindDN, lc.BindPassword)
+	}
+	if err != nil {
+		// don't return a connection in an unknown state to the pool
+		lc.Conn.Close()
+		lc.Conn = nil
+	}
+	return err
+}
+
+// Close returns the connection to the pool. If the pool is full, the connection is closed
 func (lc *LDAPClient) Close() {
 	if lc.Conn != nil {
-		lc.Conn.Close()
+		putPooledConn(lc.address(), lc.PoolSize, lc.Conn)
 		lc.Conn = nil
 	}
 }
 
 func getGroupBlacklist() []string {
-	cfg := config.Config()
 	var blacklist []string
 	// We can ignore the error here
-	if err := cfg.UnmarshalKey("ldap.group_blacklist", &blacklist); err != nil {
+	if err := config.UnmarshalKey("ldap.group_blacklist", &blacklist); err != nil {
 		log.Warn("No LDAP group blacklist found")
 	}
 	return blacklist
 }
 
 func (lc *LDAPClient) GetUser(username string) (*ldap.Entry, error) {
-	err := lc.Connect()
-	if err != nil {
-		return nil, err
-	}
-
 	// First bind with a read only user
-	if lc.BindDN != "" && lc.BindPassword != "" {
-		err = lc.Conn.Bind(lc.BindDN, lc.BindPassword)
-		if err != nil {
-			return nil, err
-		}
+	if err := lc.connectAndBind(); err != nil {
+		return nil, err
 	}
 
 	searchRequest := ldap.NewSearchRequest(
@@ -132,12 +183,110 @@ func (lc *LDAPClient) GetUser(username string) (*ldap.Entry, error) {
 	if err != nil {
 		return nil, err
 	}
+	if len(sr.Entries) == 0 {
+		return nil, ErrUserNotFound
+	}
 	if len(sr.Entries) > 1 {
 		return nil, fmt.Errorf("Something went wrong. Multiple LDAP users returned")
 	}
 	return sr.Entries[0], nil
 }
 
+// UserExists checks if a user with the given name exists
+func (lc *LDAPClient) UserExists(username string) (bool, error) {
+	// First bind with a read only user
+	if err := lc.connectAndBind(); err != nil {
+		return false, err
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		lc.Base,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(lc.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"cn"},
+		nil,
+	)
+	sr, err := lc.Conn.Search(searchRequest)
+	if err != nil {
+		return false, err
+	}
+	return len(sr.Entries) > 0, nil
+}
+
+// LookupUser returns the display name and email of the user.
+// Exists is false if there is no user with the given name
+func (lc *LDAPClient) LookupUser(username string) (UserInfo, error) {
+	info := UserInfo{Username: username}
+	// First bind with a read only user
+	if err := lc.connectAndBind(); err != nil {
+		return info, err
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		lc.Base,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(lc.UserFilter, ldap.EscapeFilter(username)),
+		[]string{"cn", "displayName", "mail"},
+		nil,
+	)
+	sr, err := lc.Conn.Search(searchRequest)
+	if err != nil {
+		return info, err
+	}
+	if len(sr.Entries) == 0 {
+		return info, nil
+	}
+	info.Exists = true
+	info.DisplayName = sr.Entries[0].GetAttributeValue("displayName")
+	info.Email = sr.Entries[0].GetAttributeValue("mail")
+	return info, nil
+}
+
+// GroupExists checks if a group with the given name exists
+func (lc *LDAPClient) GroupExists(group string) (bool, error) {
+	// First bind with a read only user
+	if err := lc.connectAndBind(); err != nil {
+		return false, err
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		lc.Base,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(lc.GroupNameFilter, ldap.EscapeFilter(group)),
+		[]string{"cn"},
+		nil,
+	)
+	sr, err := lc.Conn.Search(searchRequest)
+	if err != nil {
+		return false, err
+	}
+	return len(sr.Entries) > 0, nil
+}
+
+// GetGroupMail returns the email address of the group. It is empty if the group has no address
+func (lc *LDAPClient) GetGroupMail(group string) (string, error) {
+	// First bind with a read only user
+	if err := lc.connectAndBind(); err != nil {
+		return "", err
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		lc.Base,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(lc.GroupNameFilter, ldap.EscapeFilter(group)),
+		[]string{"mail"},
+		nil,
+	)
+	sr, err := lc.Conn.Search(searchRequest)
+	if err != nil {
+		return "", err
+	}
+	if len(sr.Entries) == 0 {
+		return "", nil
+	}
+	return sr.Entries[0].GetAttributeValue("mail"), nil
+}
+
 func getCN(dn string) string {
 	parsedDN, err := ldap.ParseDN(dn)
 	fields := log.Fields{"dn": dn}
@@ -157,7 +306,11 @@ func getCN(dn string) string {
 	return parsedDN.RDNs[0].Attributes[0].Value
 }
 
+// GetGroupsOfUser returns the groups of the user. The groups are cached for ldap.cache_ttl
 func (lc *LDAPClient) GetGroupsOfUser(username string) ([]string, error) {
+	if groups, ok := getCachedGroups(username); ok {
+		return groups, nil
+	}
 	var groups []string
 	user, err := lc.GetUser(username)
 	if err != nil {
@@ -172,5 +325,6 @@ func (lc *LDAPClient) GetGroupsOfUser(username string) ([]string, error) {
 		}
 		groups = append(groups, group)
 	}
+	setCachedGroups(username, groups, lc.CacheTTL)
 	return groups, nil
 }
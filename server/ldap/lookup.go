@@ -0,0 +1,98 @@
+package ldap
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/patrickmn/go-cache"
+)
+
+const (
+	defaultLookupWorkers = 5
+	defaultLookupTimeout = 10 * time.Second
+	// Maximum number of usernames per lookup
+	maxLookupUsers = 100
+)
+
+// UserInfo is the result of a user lookup
+type UserInfo struct {
+	Username    string `json:"username"`
+	Exists      bool   `json:"exists"`
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
+// Results of user lookups, kept briefly to reduce the load on LDAP
+var userCache = cache.New(time.Minute, 5*time.Minute)
+
+// LookupUsers looks up the users concurrently with ldap.lookup_workers workers.
+// The results are in the same order as the usernames.
+// An error is returned if a lookup fails or all lookups take longer than ldap.lookup_timeout
+func LookupUsers(usernames []string) ([]UserInfo, error) {
+	if len(usernames) > maxLookupUsers {
+		return nil, fmt.Errorf("Too many usernames, the maximum is %v", maxLookupUsers)
+	}
+	cfg := config.Config()
+	workers := cfg.GetInt("ldap.lookup_workers")
+	if workers <= 0 {
+		workers = defaultLookupWorkers
+	}
+	timeout := cfg.GetDuration("ldap.lookup_timeout")
+	if timeout <= 0 {
+		timeout = defaultLookupTimeout
+	}
+	return lookupUsers(usernames, workers, timeout, lookupUser)
+}
+
+func lookupUser(username string) (UserInfo, error) {
+	if info, ok := userCache.Get(username); ok {
+		return info.(UserInfo), nil
+	}
+	l, err := New()
+	if err != nil {
+		return UserInfo{}, err
+	}
+	defer l.Close()
+	info, err := l.LookupUser(username)
+	if err != nil {
+		return info, err
+	}
+	userCache.Set(username, info, cache.DefaultExpiration)
+	return info, nil
+}
+
+type lookupResult struct {
+	index int
+	info  UserInfo
+	err   error
+}
+
+func lookupUsers(usernames []string, workers int, timeout time.Duration, lookup func(string) (UserInfo, error)) ([]UserInfo, error) {
+	// buffered, so that workers can finish after a timeout
+	results := make(chan lookupResult, len(usernames))
+	// stops the workers after an error or timeout
+	done := make(chan struct{})
+	defer close(done)
+	go common.Parallel(len(usernames), workers, done, func(i int) {
+		info, err := lookup(usernames[i])
+		results <- lookupResult{index: i, info: info, err: err}
+	})
+
+	users := make([]UserInfo, len(usernames))
+	deadline := time.After(timeout)
+	for range usernames {
+		select {
+		case r := <-results:
+			if r.err != nil {
+				return nil, fmt.Errorf("Error looking up LDAP user %v: %v", usernames[r.index], r.err)
+			}
+			users[r.index] = r.info
+		case <-deadline:
+			return nil, errors.New("Timeout while looking up the LDAP users")
+		}
+	}
+	return users, nil
+}
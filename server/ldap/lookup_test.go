@@ -0,0 +1,58 @@
+package ldap
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupUsers(t *testing.T) {
+	var running, maxRunning int32
+	lookup := func(username string) (UserInfo, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return UserInfo{Username: username, Exists: username != "unknown"}, nil
+	}
+
+	usernames := []string{"u1", "u2", "unknown", "u4", "u5", "u6"}
+	users, err := lookupUsers(usernames, 2, time.Second, lookup)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	for i, user := range users {
+		if user.Username != usernames[i] {
+			t.Errorf("ERROR: user %v should be %v, but is: %v", i, usernames[i], user.Username)
+		}
+		if user.Exists != (user.Username != "unknown") {
+			t.Errorf("ERROR: wrong exists for %v", user.Username)
+		}
+	}
+	if maxRunning > 2 {
+		t.Errorf("ERROR: at most 2 lookups should run concurrently, but %v did", maxRunning)
+	}
+}
+
+func TestLookupUsersErrors(t *testing.T) {
+	failing := func(username string) (UserInfo, error) {
+		return UserInfo{}, errors.New("connection refused")
+	}
+	if _, err := lookupUsers([]string{"u1"}, 2, time.Second, failing); err == nil {
+		t.Error("ERROR: expected an error from the lookup")
+	}
+
+	slow := func(username string) (UserInfo, error) {
+		time.Sleep(100 * time.Millisecond)
+		return UserInfo{Username: username}, nil
+	}
+	if _, err := lookupUsers([]string{"u1", "u2"}, 1, 20*time.Millisecond, slow); err == nil {
+		t.Error("ERROR: expected a timeout")
+	}
+}
@@ -0,0 +1,53 @@
+package ldap
+
+import (
+	"sync"
+
+	"gopkg.in/ldap.v2"
+)
+
+// Idle connections per LDAP server (host:port)
+var (
+	pools      = make(map[string]chan *ldap.Conn)
+	poolsMutex sync.Mutex
+)
+
+func getPool(address string, size int) chan *ldap.Conn {
+	poolsMutex.Lock()
+	defer poolsMutex.Unlock()
+	pool, ok := pools[address]
+	if !ok {
+		pool = make(chan *ldap.Conn, size)
+		pools[address] = pool
+	}
+	return pool
+}
+
+// getPooledConn returns an idle connection or nil if there is none
+func getPooledConn(address string) *ldap.Conn {
+	poolsMutex.Lock()
+	pool, ok := pools[address]
+	poolsMutex.Unlock()
+	if !ok {
+		return nil
+	}
+	select {
+	case conn := <-pool:
+		return conn
+	default:
+		return nil
+	}
+}
+
+// putPooledConn keeps the connection for reuse or closes it if the pool is full
+func putPooledConn(address string, size int, conn *ldap.Conn) {
+	if size <= 0 {
+		conn.Close()
+		return
+	}
+	select {
+	case getPool(address, size) <- conn:
+	default:
+		conn.Close()
+	}
+}
@@ -1,6 +1,7 @@
 package ldap
 
 import (
+	"fmt"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
@@ -13,6 +14,50 @@ const (
 
 func RegisterRoutes(r *gin.RouterGroup) {
 	r.GET("/ldap/groups", listLdapGroupsHandler)
+	r.DELETE("/ldap/groups/cache", invalidateGroupCacheHandler)
+	r.POST("/ldap/users/exists", usersExistHandler)
+}
+
+type usersExistCommand struct {
+	Usernames []string `json:"usernames"`
+}
+
+// usersExistHandler checks if the users exist and returns their display name and email
+func usersExistHandler(c *gin.Context) {
+	var data usersExistCommand
+	if c.BindJSON(&data) != nil || len(data.Usernames) == 0 {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{
+			Message: "Usernames must be provided",
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+		return
+	}
+	if len(data.Usernames) > maxLookupUsers {
+		c.JSON(http.StatusUnprocessableEntity, common.ApiResponse{
+			Message: fmt.Sprintf("Too many usernames, the maximum is %v", maxLookupUsers),
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+		return
+	}
+
+	users, err := LookupUsers(data.Usernames)
+	if err != nil {
+		log.Errorf("%v", err)
+		c.JSON(http.StatusBadGateway, common.ApiResponse{
+			Message: "An Error has occured while looking up the LDAP users. Please create an Issue.",
+			Code:    common.ErrorCodeBackendError,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, users)
+}
+
+// invalidateGroupCacheHandler reloads the groups of the user on the next request,
+// e.g. after the user was added to a new group
+func invalidateGroupCacheHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	InvalidateGroupCache(username)
+	c.JSON(http.StatusOK, common.ApiResponse{Message: "The LDAP groups will be reloaded"})
 }
 
 func listLdapGroupsHandler(c *gin.Context) {
@@ -1,25 +1,63 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/aws"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/kafka"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/ldap"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/openshift"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/otc"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/publisher"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/sematext"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/swagger"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/tower"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"runtime"
+	"time"
+)
+
+// Set at build time with -ldflags "-X main.version=... -X main.commit=... -X main.buildDate=..."
+var (
+	version   = "dev"
+	commit    = "unknown"
+	buildDate = "unknown"
 )
 
 func main() {
-	config.Init("bla")
+	configFile := flag.String("config", "", "Path to a yaml config file. ENV variables take precedence")
+	flag.Parse()
+
+	if *configFile != "" {
+		if err := config.InitFile(*configFile); err != nil {
+			log.Fatalf("Could not load configuration file %v: %v", *configFile, err)
+		}
+	} else {
+		config.Init("bla")
+	}
 
 	log.SetReportCaller(true)
+	// Mask tokens and passwords, e.g. in the error bodies of the backends
+	common.RedactLogs()
+
+	if profile := config.Profile(); profile != "" {
+		log.Printf("Active config profile: %v", profile)
+		if err := config.ValidateRequired(); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		log.Println("No config profile active (APP_PROFILE)")
+	}
 
 	if config.Config().GetBool("debug") {
 		log.SetLevel(log.DebugLevel)
@@ -27,23 +65,68 @@ func main() {
 	} else {
 		gin.SetMode(gin.ReleaseMode)
 	}
+	config.LogSources()
+
+	if err := common.ValidateProxy(); err != nil {
+		log.Fatal(err)
+	}
+	if err := otc.ValidateProxy(); err != nil {
+		log.Fatal(err)
+	}
+	if err := openshift.ValidateClusters(); err != nil {
+		log.Fatal(err)
+	}
+	if err := publisher.ValidateConfig(); err != nil {
+		log.Fatal(err)
+	}
+	if err := openshift.ValidateBillingValidation(); err != nil {
+		log.Fatal(err)
+	}
+	if err := openshift.ValidateRequiredProjectFields(); err != nil {
+		log.Fatal(err)
+	}
+	if err := openshift.ValidateProjectPullSecret(); err != nil {
+		log.Fatal(err)
+	}
+	openshift.StartArchiveCleanup()
 
 	router := gin.New()
 	router.Use(gin.Recovery())
+	accessLogExcludePaths := defaultAccessLogExcludePaths
+	if config.Config().IsSet("access_log_exclude_paths") {
+		accessLogExcludePaths = config.Config().GetStringSlice("access_log_exclude_paths")
+	}
+	router.Use(accessLog(accessLogExcludePaths))
+	registerFallbackHandlers(router)
+
+	maxBodyBytes := config.Config().GetInt64("max_request_body_bytes")
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxRequestBodyBytes
+	}
+	router.Use(limitRequestBody(maxBodyBytes))
 
 	// Allow cors
 	corsConfig := cors.DefaultConfig()
 	corsConfig.AllowAllOrigins = true
 	corsConfig.AddAllowHeaders("authorization", "*")
 	corsConfig.AddAllowMethods("DELETE")
+	// Read by the frontend for If-Match on updates
+	corsConfig.AddExposeHeaders("ETag")
 	router.Use(cors.New(corsConfig))
 
 	// Public routes
 	router.GET("/features", featuresHandler)
+	router.GET("/version", versionHandler)
+	router.GET("/health", healthHandler)
+	swagger.RegisterRoutes(router)
+	router.GET("/metrics", openshift.MetricsHandler)
+	router.POST("/verify_token", keycloak.VerifyTokenHandler)
 
 	// Protected routes
 	auth := router.Group("/api/")
-	auth.Use(keycloak.Auth(keycloak.LoggedInCheck()))
+	auth.Use(authenticate(keycloak.Auth(keycloak.LoggedInCheck())))
+	auth.Use(authorizedUsersOnly())
+	auth.Use(readOnlyMode())
 	{
 		// Openshift routes
 		openshift.RegisterRoutes(auth)
@@ -69,16 +152,213 @@ func main() {
 
 	log.Println("Cloud SSP is running")
 
-	port := config.Config().GetString("port")
+	cfg := config.Config()
+	port := cfg.GetString("server_port")
+	if port == "" {
+		// deprecated, use server_port
+		port = cfg.GetString("port")
+	}
 	if port == "" {
 		port = "8000"
 	}
-	err := router.Run(":" + port)
+
+	tlsCert := cfg.GetString("server_tls_cert")
+	tlsKey := cfg.GetString("server_tls_key")
+	var err error
+	if tlsCert != "" || tlsKey != "" {
+		if err := validateTLSFiles(tlsCert, tlsKey); err != nil {
+			log.Fatal(err)
+		}
+		err = router.RunTLS(":"+port, tlsCert, tlsKey)
+	} else {
+		err = router.Run(":" + port)
+	}
 	if err != nil {
 		log.Println(err)
 	}
 }
 
+func validateTLSFiles(files ...string) error {
+	for _, f := range files {
+		if f == "" {
+			return errors.New("Both server_tls_cert and server_tls_key must be set")
+		}
+		if _, err := os.Stat(f); err != nil {
+			return fmt.Errorf("TLS file not readable: %v", err)
+		}
+	}
+	return nil
+}
+
+// registerFallbackHandlers returns JSON responses for unknown routes and methods,
+// so clients always get an ApiResponse
+func registerFallbackHandlers(router *gin.Engine) {
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, common.ApiResponse{
+			Message: fmt.Sprintf("The route %v does not exist", c.Request.URL.Path),
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+	})
+	router.NoMethod(func(c *gin.Context) {
+		c.JSON(http.StatusMethodNotAllowed, common.ApiResponse{
+			Message: fmt.Sprintf("The method %v is not allowed for %v", c.Request.Method, c.Request.URL.Path),
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+	})
+}
+
+var defaultAccessLogExcludePaths = []string{"/health", "/metrics"}
+
+const correlationIDHeader = "X-Correlation-ID"
+
+// accessLog logs every request except the excluded paths. The correlation id is
+// taken from the X-Correlation-ID header or generated and returned in the response.
+func accessLog(excludePaths []string) gin.HandlerFunc {
+	excluded := make(map[string]bool)
+	for _, path := range excludePaths {
+		excluded[path] = true
+	}
+	return func(c *gin.Context) {
+		correlationID := c.GetHeader(correlationIDHeader)
+		if correlationID == "" {
+			correlationID = common.RandomString(8)
+		}
+		c.Set("correlation_id", correlationID)
+		c.Header(correlationIDHeader, correlationID)
+
+		start := time.Now()
+		c.Next()
+
+		path := c.Request.URL.Path
+		if excluded[path] {
+			return
+		}
+		// set by keycloak.LoggedInCheck or authenticate on authenticated routes
+		username := c.GetString(keycloak.APITokenUserKey)
+		if token, ok := c.Get("token"); ok {
+			username = token.(keycloak.KeyCloakToken).UID
+		}
+		fields := log.Fields{
+			"method":        c.Request.Method,
+			"path":          path,
+			"status":        c.Writer.Status(),
+			"latency_ms":    time.Since(start).Milliseconds(),
+			"username":      username,
+			"correlationId": correlationID,
+		}
+		if name := c.GetString(apiTokenNameKey); name != "" {
+			fields["apiToken"] = name
+		}
+		log.WithFields(fields).Info("Request")
+	}
+}
+
+const defaultMaxRequestBodyBytes = 1 << 20 // 1MB
+
+// limitRequestBody reads the request body up to maxBytes before any handler
+// can bind it, so that too large requests are rejected with 413
+func limitRequestBody(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, common.ApiResponse{Message: "Request body too large"})
+			return
+		}
+		body, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, common.ApiResponse{Message: "Request body too large"})
+			return
+		}
+		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.Next()
+	}
+}
+
+// readOnlyRoutes are the POST routes which don't change anything, e.g. because the request is too large for a query
+var readOnlyRoutes = map[string]bool{
+	"/api/ose/projects/info":   true,
+	"/api/ose/project/preview": true,
+	"/api/ldap/users/exists":   true,
+}
+
+// readOnlyMode rejects all mutating requests if read_only is set in the config.
+// The config is only read at startup, so the backend must be restarted to change the flag.
+func readOnlyMode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !config.Config().GetBool("read_only") {
+			c.Next()
+			return
+		}
+		if c.Request.Method == http.MethodPost && readOnlyRoutes[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			log.Printf("Rejected %v %v because of maintenance mode", c.Request.Method, c.Request.URL.Path)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, common.ApiResponse{
+				Message: "The portal is in maintenance mode. Changes are currently not possible",
+				Code:    common.ErrorCodeMaintenance,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ldapGroupsOfUser is replaced in tests
+var ldapGroupsOfUser = (*ldap.LDAPClient).GetGroupsOfUser
+
+// authorizedUsersOnly restricts the portal to authorized_users and the members of authorized_group.
+// If neither is set, all authenticated users are allowed. API tokens are restricted by their own config.
+func authorizedUsersOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		cfg := config.Config()
+		users := cfg.GetStringSlice("authorized_users")
+		group := cfg.GetString("authorized_group")
+		if (len(users) == 0 && group == "") || c.GetString(keycloak.APITokenUserKey) != "" {
+			c.Next()
+			return
+		}
+		username := common.GetUserName(c)
+		if common.ContainsStringI(users, username) {
+			c.Next()
+			return
+		}
+		if group != "" {
+			l, err := ldap.New()
+			if err != nil {
+				log.Errorf("%v", err)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, common.ApiResponse{Message: common.ConfigNotSetError})
+				return
+			}
+			groups, err := ldapGroupsOfUser(l, username)
+			l.Close()
+			if err != nil && err != ldap.ErrUserNotFound {
+				log.Errorf("Error looking up the LDAP groups of %v: %v", username, err)
+				c.AbortWithStatusJSON(http.StatusBadGateway, common.ApiResponse{
+					Message: "An Error has occured while getting your LDAP groups. Please create an Issue.",
+					Code:    common.ErrorCodeBackendError,
+				})
+				return
+			}
+			if common.ContainsStringI(groups, group) {
+				c.Next()
+				return
+			}
+		}
+		log.Printf("%v is not authorized to use the portal", username)
+		c.AbortWithStatusJSON(http.StatusForbidden, common.ApiResponse{
+			Message: "Du bist nicht berechtigt, dieses Portal zu verwenden",
+			Code:    common.ErrorCodeForbidden,
+		})
+	}
+}
+
 // not in common package, because that generates an import loop
 type featureToggleResponse struct {
 	Openshift openshift.Features `json:"openshift"`
@@ -95,3 +375,38 @@ func featuresHandler(c *gin.Context) {
 		Kafka:     kafka.GetFeatures(),
 	})
 }
+
+type versionResponse struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+func versionHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, versionResponse{
+		Version:   version,
+		Commit:    commit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+	})
+}
+
+type healthResponse struct {
+	// ok or degraded, if the circuit breaker of a cluster isn't closed
+	Status string `json:"status"`
+	// State of the circuit breaker per cluster
+	Clusters map[string]string `json:"clusters"`
+}
+
+// healthHandler always returns 200, so an unavailable cluster doesn't restart the backend
+func healthHandler(c *gin.Context) {
+	clusters := openshift.ClusterBreakerStates()
+	status := "ok"
+	for _, state := range clusters {
+		if state != "closed" {
+			status = "degraded"
+		}
+	}
+	c.JSON(http.StatusOK, healthResponse{Status: status, Clusters: clusters})
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/ldap"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestFallbackHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	registerFallbackHandlers(router)
+	router.GET("/features", func(c *gin.Context) {})
+
+	var testsets = []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"GET", "/unknown", http.StatusNotFound},
+		{"DELETE", "/features", http.StatusMethodNotAllowed},
+	}
+
+	for _, set := range testsets {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(set.method, set.path, nil)
+		router.ServeHTTP(w, req)
+
+		if w.Code != set.status {
+			t.Errorf("ERROR: %v %v should return %v, but returned %v", set.method, set.path, set.status, w.Code)
+		}
+		var resp common.ApiResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Message == "" || resp.Code != common.ErrorCodeInvalidRequest {
+			t.Errorf("ERROR: %v %v should return an ApiResponse, but returned: %v", set.method, set.path, w.Body.String())
+		}
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	hook := test.NewGlobal()
+	router := gin.New()
+	router.Use(accessLog([]string{"/metrics"}))
+	router.GET("/features", func(c *gin.Context) {
+		c.Set("token", keycloak.KeyCloakToken{UID: "u123456"})
+		c.Status(http.StatusTeapot)
+	})
+	router.GET("/metrics", func(c *gin.Context) {})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/features", nil)
+	req.Header.Set("X-Correlation-ID", "abc")
+	router.ServeHTTP(w, req)
+
+	entry := hook.LastEntry()
+	if entry == nil {
+		t.Fatal("ERROR: request was not logged")
+	}
+	if entry.Data["status"] != http.StatusTeapot || entry.Data["username"] != "u123456" || entry.Data["correlationId"] != "abc" {
+		t.Errorf("ERROR: unexpected log fields: %v", entry.Data)
+	}
+	if w.Header().Get("X-Correlation-ID") != "abc" {
+		t.Errorf("ERROR: correlation id should be returned, but is: '%v'", w.Header().Get("X-Correlation-ID"))
+	}
+
+	hook.Reset()
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/metrics", nil)
+	router.ServeHTTP(w, req)
+	if len(hook.AllEntries()) != 0 {
+		t.Errorf("ERROR: /metrics should not be logged")
+	}
+	if w.Header().Get("X-Correlation-ID") == "" {
+		t.Errorf("ERROR: correlation id should be generated")
+	}
+}
+
+func TestAuthorizedUsersOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Init("bla")
+	router := gin.New()
+	router.Use(authorizedUsersOnly())
+	router.GET("/features", func(c *gin.Context) {})
+
+	request := func() int {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/features", nil)
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := request(); code != http.StatusOK {
+		t.Errorf("ERROR: without allowlist all users should be allowed, but got %v", code)
+	}
+
+	config.Config().Set("authorized_users", []string{"u123456"})
+	if code := request(); code != http.StatusForbidden {
+		t.Errorf("ERROR: users not in the allowlist should get 403, but got %v", code)
+	}
+
+	config.Config().Set("authorized_group", "portal-users")
+	config.Config().Set("ldap", map[string]interface{}{"host": "ldap", "base": "dc=test", "dn": "cn=test", "password": "secret"})
+	defer func() { ldapGroupsOfUser = (*ldap.LDAPClient).GetGroupsOfUser }()
+	ldapGroupsOfUser = func(*ldap.LDAPClient, string) ([]string, error) {
+		return nil, ldap.ErrUserNotFound
+	}
+	if code := request(); code != http.StatusForbidden {
+		t.Errorf("ERROR: users unknown in LDAP should get 403, but got %v", code)
+	}
+	ldapGroupsOfUser = func(*ldap.LDAPClient, string) ([]string, error) {
+		return nil, errors.New("connection refused")
+	}
+	if code := request(); code != http.StatusBadGateway {
+		t.Errorf("ERROR: LDAP errors should return 502, but got %v", code)
+	}
+	ldapGroupsOfUser = func(*ldap.LDAPClient, string) ([]string, error) {
+		return []string{"Portal-Users"}, nil
+	}
+	if code := request(); code != http.StatusOK {
+		t.Errorf("ERROR: members of the authorized group should be allowed, but got %v", code)
+	}
+}
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/health", healthHandler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/health", nil)
+	router.ServeHTTP(w, req)
+
+	var resp healthResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK || resp.Status != "ok" {
+		t.Errorf("ERROR: unexpected health response %v: %v", w.Code, w.Body.String())
+	}
+}
+
+func TestReadOnlyMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	config.Init("bla")
+	config.Config().Set("read_only", true)
+	router := gin.New()
+	auth := router.Group("/api/")
+	auth.Use(readOnlyMode())
+	handler := func(c *gin.Context) { c.Status(http.StatusOK) }
+	auth.GET("/ose/projects", handler)
+	auth.POST("/ose/project", handler)
+	auth.DELETE("/ose/project", handler)
+	auth.POST("/ose/projects/info", handler)
+	auth.POST("/ose/project/preview", handler)
+	auth.POST("/ldap/users/exists", handler)
+
+	var testsets = []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"GET", "/api/ose/projects", http.StatusOK},
+		{"POST", "/api/ose/project", http.StatusServiceUnavailable},
+		{"DELETE", "/api/ose/project", http.StatusServiceUnavailable},
+		// read-only POST routes
+		{"POST", "/api/ose/projects/info", http.StatusOK},
+		{"POST", "/api/ose/project/preview", http.StatusOK},
+		{"POST", "/api/ldap/users/exists", http.StatusOK},
+	}
+	for _, set := range testsets {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(set.method, set.path, nil)
+		router.ServeHTTP(w, req)
+		if w.Code != set.status {
+			t.Errorf("ERROR: %v %v should return %v in maintenance mode, but returned %v", set.method, set.path, set.status, w.Code)
+		}
+	}
+}
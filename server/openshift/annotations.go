@@ -0,0 +1,76 @@
+package openshift
+
+import (
+	"fmt"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+const (
+	defaultMaxAnnotationValueBytes = 16 * 1024
+	// Kubernetes rejects objects whose annotations (keys and values) are larger than 256KB
+	defaultMaxAnnotationsTotalBytes = 256 * 1024
+)
+
+// getAnnotationLimits returns the max size of a single annotation value and of all annotations
+func getAnnotationLimits() (int, int) {
+	cfg := config.Config()
+	maxValue := cfg.GetInt("max_annotation_value_bytes")
+	if maxValue <= 0 {
+		maxValue = defaultMaxAnnotationValueBytes
+	}
+	maxTotal := cfg.GetInt("max_annotations_total_bytes")
+	if maxTotal <= 0 {
+		maxTotal = defaultMaxAnnotationsTotalBytes
+	}
+	return maxValue, maxTotal
+}
+
+// annotationFieldName returns the API field which is stored in the annotation,
+// so that errors name the field the user has sent
+func annotationFieldName(key string) string {
+	switch key {
+	case getAnnotationKeys().Billing:
+		return "billing"
+	case getAnnotationKeys().Requester:
+		return "requester"
+	case "openshift.io/MEGAID":
+		return "megaid"
+	case "openshift.io/owner-group":
+		return "ownerGroup"
+	case dataClassificationAnnotation:
+		return "classification"
+	case displayNameAnnotation:
+		return "displayName"
+	}
+	return key
+}
+
+// validateAnnotationValues checks the length of the values which are written to the annotations
+func validateAnnotationValues(values map[string]string) error {
+	maxValue, _ := getAnnotationLimits()
+	for key, value := range values {
+		if len(value) > maxValue {
+			return common.NewValidationError(common.ErrorCodeInvalidRequest,
+				fmt.Sprintf("The field %v is too long (%v bytes, max %v bytes)", annotationFieldName(key), len(value), maxValue))
+		}
+	}
+	return nil
+}
+
+// validateAnnotationsSize checks the total size of all annotations of an object.
+// Only string values are counted, like Kubernetes does.
+func validateAnnotationsSize(annotations map[string]interface{}) error {
+	_, maxTotal := getAnnotationLimits()
+	total := 0
+	for key, value := range annotations {
+		s, _ := value.(string)
+		total += len(key) + len(s)
+	}
+	if total > maxTotal {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The annotations of the project are too large (%v bytes, max %v bytes)", total, maxTotal))
+	}
+	return nil
+}
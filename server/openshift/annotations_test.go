@@ -0,0 +1,51 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateAnnotationValues(t *testing.T) {
+	config.Init("bla")
+	config.Config().Set("max_annotation_value_bytes", 10)
+
+	if err := validateAnnotationValues(map[string]string{"openshift.io/MEGAID": strings.Repeat("a", 10)}); err != nil {
+		t.Errorf("ERROR: value with the max length should be valid, got: %v", err)
+	}
+	err := validateAnnotationValues(map[string]string{"openshift.io/MEGAID": strings.Repeat("a", 11)})
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeInvalidRequest || !strings.Contains(apiErr.Message, "megaid") {
+		t.Errorf("ERROR: too long value should return an error with the field name, got: %v", err)
+	}
+}
+
+func TestValidateAnnotationsSize(t *testing.T) {
+	config.Init("bla")
+	if err := validateAnnotationsSize(map[string]interface{}{"key": strings.Repeat("a", defaultMaxAnnotationsTotalBytes-3)}); err != nil {
+		t.Errorf("ERROR: annotations with the max size should be valid, got: %v", err)
+	}
+	if err := validateAnnotationsSize(map[string]interface{}{"key": strings.Repeat("a", defaultMaxAnnotationsTotalBytes-2)}); err == nil {
+		t.Error("ERROR: annotations larger than the max size should be invalid")
+	}
+}
+
+func TestCreateOrUpdateMetadataTooLarge(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "GET" {
+			t.Errorf("ERROR: too large annotations should not be written, got %v request", r.Method)
+		}
+		w.Write([]byte(`{"metadata": {"name": "project", "annotations": {"openshift.io/description": "` + strings.Repeat("a", 100) + `"}}}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("max_annotations_total_bytes", 200)
+
+	err := createOrUpdateMetadata("test", "project", strings.Repeat("1", 100), "", "", "", "user", false)
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeInvalidRequest {
+		t.Errorf("ERROR: expected an INVALID_REQUEST error, got: %v", err)
+	}
+}
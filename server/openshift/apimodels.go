@@ -6,3 +6,22 @@ type OpenshiftSubject struct {
 	Name      string `json:"name"`
 	Namespace string `json:"namespace"`
 }
+
+type RoleBinding struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`
+	Users  []string `json:"users"`
+	Groups []string `json:"groups"`
+}
+
+// PermissionSubject is a user or group with a role (admin or operator) in a project
+type PermissionSubject struct {
+	Role string `json:"role"`
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type CopyPermissionsResult struct {
+	Added   []PermissionSubject `json:"added"`
+	Removed []PermissionSubject `json:"removed"`
+}
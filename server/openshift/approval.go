@@ -0,0 +1,401 @@
+package openshift
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/gomail.v2"
+)
+
+type projectApprovalConfig struct {
+	// Members of this LDAP group can approve and reject project requests
+	ApproverGroup string `mapstructure:"approver_group"`
+	// Comma separated mail addresses which are notified about new requests
+	Mail string
+	// JSON file with the pending requests, so that they survive restarts
+	StoreFile string `mapstructure:"store_file"`
+	// JSON file with the pending quota increase requests
+	QuotaStoreFile string `mapstructure:"quota_store_file"`
+}
+
+// PendingProject is a project request on a cluster with approval_required
+type PendingProject struct {
+	ID        string                   `json:"id"`
+	Command   common.NewProjectCommand `json:"command"`
+	Username  string                   `json:"username"`
+	Requester string                   `json:"requester"`
+	// Mail address of the user, who is notified about the decision
+	Mail        string    `json:"mail,omitempty"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+var (
+	// Guards the store file and processingProjects
+	pendingProjectsMu sync.Mutex
+	// IDs of the requests which are being approved or rejected
+	processingProjects = map[string]bool{}
+)
+
+func getProjectApprovalConfig() (*projectApprovalConfig, error) {
+	cfg := projectApprovalConfig{}
+	if err := config.UnmarshalKey("project_approval", &cfg); err != nil {
+		log.Printf("WARNING: invalid project_approval config: %v", err)
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	if cfg.ApproverGroup == "" || cfg.StoreFile == "" {
+		log.Printf("WARNING: project_approval.approver_group and project_approval.store_file must be set for clusters with approval_required")
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	return &cfg, nil
+}
+
+// validateApprover checks that the user is in the approver_group
+func validateApprover(username string) (*projectApprovalConfig, error) {
+	cfg, err := getProjectApprovalConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkApprover(cfg.ApproverGroup, username); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func checkApprover(approverGroup, username string) error {
+	isApprover, err := isInLdapGroup(username, approverGroup)
+	if err != nil {
+		return err
+	}
+	if !isApprover {
+		return common.NewApiError(common.ErrorCodeForbidden, "Only approvers can use this function")
+	}
+	return nil
+}
+
+// loadPendingProjects reads the store file. A missing file means that there are no requests.
+func loadPendingProjects(file string) ([]PendingProject, error) {
+	pending := []PendingProject{}
+	if err := readStoreFile(file, &pending); err != nil {
+		return nil, err
+	}
+	return pending, nil
+}
+
+func savePendingProjects(file string, pending []PendingProject) error {
+	return writeStoreFile(file, pending)
+}
+
+// readStoreFile decodes the JSON store file into v. A missing file leaves v unchanged.
+func readStoreFile(file string, v interface{}) error {
+	content, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(content, v); err != nil {
+		return fmt.Errorf("Invalid store file %v: %v", file, err)
+	}
+	return nil
+}
+
+// writeStoreFile writes a temporary file and renames it,
+// so that a crash while writing doesn't corrupt the store
+func writeStoreFile(file string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(file), filepath.Base(file)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// addPendingProject stores a new request. Only one request per project and cluster is allowed.
+func addPendingProject(file string, p PendingProject) error {
+	pendingProjectsMu.Lock()
+	defer pendingProjectsMu.Unlock()
+
+	pending, err := loadPendingProjects(file)
+	if err != nil {
+		return err
+	}
+	for _, existing := range pending {
+		if existing.Command.ClusterId == p.Command.ClusterId && strings.EqualFold(existing.Command.Project, p.Command.Project) {
+			return common.NewApiError(common.ErrorCodeConflict,
+				fmt.Sprintf("The project %v has already been requested on cluster %v", p.Command.Project, p.Command.ClusterId))
+		}
+	}
+	return savePendingProjects(file, append(pending, p))
+}
+
+func getPendingProject(file, id string) (*PendingProject, error) {
+	pendingProjectsMu.Lock()
+	defer pendingProjectsMu.Unlock()
+
+	pending, err := loadPendingProjects(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		if p.ID == id {
+			return &p, nil
+		}
+	}
+	return nil, common.NewApiError(common.ErrorCodeProjectNotFound, fmt.Sprintf("The project request %v doesn't exist", id))
+}
+
+// claimPendingProject returns the request and marks it as being processed, so that concurrent
+// approvals don't create the project twice. The claim must be released with releasePendingProject.
+func claimPendingProject(file, id string) (*PendingProject, error) {
+	pendingProjectsMu.Lock()
+	defer pendingProjectsMu.Unlock()
+
+	pending, err := loadPendingProjects(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range pending {
+		if p.ID != id {
+			continue
+		}
+		if processingProjects[id] {
+			return nil, common.NewApiError(common.ErrorCodeConflict, fmt.Sprintf("The project request %v is already being processed", id))
+		}
+		processingProjects[id] = true
+		return &p, nil
+	}
+	return nil, common.NewApiError(common.ErrorCodeProjectNotFound, fmt.Sprintf("The project request %v doesn't exist", id))
+}
+
+func releasePendingProject(id string) {
+	pendingProjectsMu.Lock()
+	defer pendingProjectsMu.Unlock()
+	delete(processingProjects, id)
+}
+
+// claimErrorStatus returns 409 if the request is being processed and 404 if it doesn't exist
+func claimErrorStatus(err error) int {
+	if apiErr, ok := err.(*common.ApiError); ok && apiErr.Code == common.ErrorCodeConflict {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
+
+// revalidatePendingProject repeats the checks which can change while a request waits for approval,
+// e.g. the requester has reached the project limit or the accounting number was closed
+func revalidatePendingProject(p *PendingProject) (int, error) {
+	fields := projectFields{Billing: p.Command.Billing, MegaId: p.Command.MegaId, OwnerGroup: p.Command.OwnerGroup, Classification: p.Command.Classification}
+	if _, err := validateNewProject(p.Command.Project, fields, false); err != nil {
+		return http.StatusBadRequest, err
+	}
+	if err := validateClusterAccess(p.Username, p.Command.ClusterId); err != nil {
+		return http.StatusForbidden, err
+	}
+	if err := checkProjectLimit(p.Command.ClusterId, p.Requester); err != nil {
+		return http.StatusForbidden, err
+	}
+	return http.StatusOK, nil
+}
+
+func removePendingProject(file, id string) error {
+	pendingProjectsMu.Lock()
+	defer pendingProjectsMu.Unlock()
+
+	pending, err := loadPendingProjects(file)
+	if err != nil {
+		return err
+	}
+	remaining := []PendingProject{}
+	for _, p := range pending {
+		if p.ID != id {
+			remaining = append(remaining, p)
+		}
+	}
+	return savePendingProjects(file, remaining)
+}
+
+// requestProjectApproval stores the validated command and notifies the approvers
+func requestProjectApproval(data common.NewProjectCommand, username, requester, mail string) error {
+	cfg, err := getProjectApprovalConfig()
+	if err != nil {
+		return err
+	}
+	p := PendingProject{
+		ID:          common.RandomString(8),
+		Command:     data,
+		Username:    username,
+		Requester:   requester,
+		Mail:        mail,
+		RequestedAt: time.Now().UTC(),
+	}
+	if err := addPendingProject(cfg.StoreFile, p); err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"cluster":   data.ClusterId,
+		"project":   data.Project,
+		"username":  username,
+		"requester": requester,
+		"id":        p.ID,
+	}).Info("AUDIT: Project was requested and waits for approval")
+
+	body := fmt.Sprintf("The project %v on cluster %v has been requested by %v and waits for approval.\n\n"+
+		"Requester: %v\nAccounting number: %v\nMega ID: %v\nRequest ID: %v\n",
+		data.Project, data.ClusterId, username, requester, data.Billing, data.MegaId, p.ID)
+	if err := sendApprovalMail(parseMailAddresses(cfg.Mail), fmt.Sprintf("Project '%v' on OpenShift waits for approval", data.Project), body); err != nil {
+		log.Printf("Can't send e-mail about the project request %v: %v", p.ID, err)
+	}
+	return nil
+}
+
+func sendApprovalMail(to []string, subject, body string) error {
+	if len(to) == 0 {
+		return nil
+	}
+	mailer, err := getMailer()
+	if err != nil {
+		return err
+	}
+	from := parseMailAddresses(os.Getenv("MAIL_ADMIN_SENDER"))
+	if len(from) != 1 {
+		return errors.New("MAIL_ADMIN_SENDER must be a valid e-mail address.")
+	}
+	m := gomail.NewMessage()
+	m.SetHeader("From", from[0])
+	m.SetHeader("To", to...)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
+	return mailer.Send(m)
+}
+
+func getPendingProjectsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	cfg, err := validateApprover(username)
+	if err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	pendingProjectsMu.Lock()
+	pending, err := loadPendingProjects(cfg.StoreFile)
+	pendingProjectsMu.Unlock()
+	if err != nil {
+		log.Printf("Error reading the project requests: %v", err)
+		common.RespondError(c, http.StatusInternalServerError, common.NewApiError(common.ErrorCodeBackendError, genericAPIError))
+		return
+	}
+	c.JSON(http.StatusOK, pending)
+}
+
+// approveProjectHandler creates the requested project. The request is only removed if the project was created.
+// The validations of the request are repeated, because they might have changed since the request.
+func approveProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectRequestCommand
+	if c.BindJSON(&data) != nil || data.ID == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	cfg, err := validateApprover(username)
+	if err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+	p, err := claimPendingProject(cfg.StoreFile, data.ID)
+	if err != nil {
+		common.RespondError(c, claimErrorStatus(err), err)
+		return
+	}
+	defer releasePendingProject(p.ID)
+	if strings.EqualFold(p.Username, username) || strings.EqualFold(p.Requester, username) {
+		common.RespondError(c, http.StatusForbidden, common.NewApiError(common.ErrorCodeForbidden, "Approvers can't approve their own requests"))
+		return
+	}
+	if status, err := revalidatePendingProject(p); err != nil {
+		common.RespondError(c, status, err)
+		return
+	}
+
+	message, err := createProjectFromCommand(c, p.Command, p.Username, p.Requester)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  p.Command.ClusterId,
+		"project":  p.Command.Project,
+		"approver": username,
+		"id":       p.ID,
+	}).Info("AUDIT: Project request was approved")
+	if err := removePendingProject(cfg.StoreFile, p.ID); err != nil {
+		log.Printf("Error removing the approved project request %v: %v", p.ID, err)
+	}
+	if err := sendApprovalMail(parseMailAddresses(p.Mail), fmt.Sprintf("Project '%v' on OpenShift has been approved", p.Command.Project),
+		fmt.Sprintf("Your project %v on cluster %v has been approved by %v and created.\n", p.Command.Project, p.Command.ClusterId, username)); err != nil {
+		log.Printf("Can't send e-mail about the approval of request %v: %v", p.ID, err)
+	}
+	c.JSON(http.StatusOK, common.ApiResponse{Message: message})
+}
+
+func rejectProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectRequestCommand
+	if c.BindJSON(&data) != nil || data.ID == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	cfg, err := validateApprover(username)
+	if err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+	p, err := claimPendingProject(cfg.StoreFile, data.ID)
+	if err != nil {
+		common.RespondError(c, claimErrorStatus(err), err)
+		return
+	}
+	defer releasePendingProject(p.ID)
+	if err := removePendingProject(cfg.StoreFile, p.ID); err != nil {
+		log.Printf("Error removing the rejected project request %v: %v", p.ID, err)
+		common.RespondError(c, http.StatusInternalServerError, common.NewApiError(common.ErrorCodeBackendError, genericAPIError))
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  p.Command.ClusterId,
+		"project":  p.Command.Project,
+		"approver": username,
+		"id":       p.ID,
+		"reason":   data.Reason,
+	}).Info("AUDIT: Project request was rejected")
+	if err := sendApprovalMail(parseMailAddresses(p.Mail), fmt.Sprintf("Project '%v' on OpenShift has been rejected", p.Command.Project),
+		fmt.Sprintf("Your project %v on cluster %v has been rejected by %v.\n\nReason: %v\n", p.Command.Project, p.Command.ClusterId, username, data.Reason)); err != nil {
+		log.Printf("Can't send e-mail about the rejection of request %v: %v", p.ID, err)
+	}
+	c.JSON(http.StatusOK, common.ApiResponse{Message: common.T(c, common.MsgProjectRejected, p.Command.Project, p.Command.ClusterId)})
+}
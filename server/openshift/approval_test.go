@@ -0,0 +1,91 @@
+package openshift
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestPendingProjectStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "approval")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storeFile := filepath.Join(dir, "pending.json")
+
+	config.Init("bla")
+	config.Config().Set("mail_disabled", true)
+	config.Config().Set("project_approval", map[string]interface{}{
+		"approver_group": "DG_APPROVERS",
+		"mail":           "approvers@example.com",
+		"store_file":     storeFile,
+	})
+	os.Setenv("MAIL_ADMIN_SENDER", "ssp@example.com")
+	defer os.Unsetenv("MAIL_ADMIN_SENDER")
+	mailsBefore := len(recordedMails.Messages())
+
+	data := common.NewProjectCommand{Billing: "12345", Classification: "internal"}
+	data.ClusterId = "prod"
+	data.Project = "shop"
+	if err := requestProjectApproval(data, "u111111", "u222222", "u111111@example.com"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(recordedMails.Messages()) != mailsBefore+1 {
+		t.Error("ERROR: the approvers should be notified")
+	}
+
+	data.Project = "SHOP"
+	if apiErr, ok := requestProjectApproval(data, "u111111", "u111111", "").(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict {
+		t.Errorf("ERROR: a project can only be requested once, got: %v", apiErr)
+	}
+
+	// The requests are read from the file, so they survive restarts
+	pending, err := loadPendingProjects(storeFile)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("ERROR: expected 1 pending request, got %v (error: %v)", pending, err)
+	}
+	p, err := getPendingProject(storeFile, pending[0].ID)
+	if err != nil || p.Command.Project != "shop" || p.Requester != "u222222" || p.Command.Billing != "12345" {
+		t.Errorf("ERROR: unexpected request %+v (error: %v)", p, err)
+	}
+
+	// A request can only be processed by one approver at a time
+	claimed, err := claimPendingProject(storeFile, p.ID)
+	if err != nil || claimed.ID != p.ID {
+		t.Fatalf("ERROR: unexpected claim %+v (error: %v)", claimed, err)
+	}
+	if _, err := claimPendingProject(storeFile, p.ID); claimErrorStatus(err) != http.StatusConflict {
+		t.Errorf("ERROR: a claimed request should return a conflict, got: %v", err)
+	}
+	releasePendingProject(p.ID)
+	if _, err := claimPendingProject(storeFile, p.ID); err != nil {
+		t.Errorf("ERROR: a released request should be claimable, got: %v", err)
+	}
+	releasePendingProject(p.ID)
+
+	// The checks are repeated on approval
+	config.Config().Set("cluster_access", map[string]interface{}{"default_clusters": []string{"awsdev"}})
+	if status, err := revalidatePendingProject(p); status != http.StatusForbidden || err == nil {
+		t.Errorf("ERROR: a cluster which is no longer allowed should be refused, got %v: %v", status, err)
+	}
+
+	if err := removePendingProject(storeFile, p.ID); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if _, err := getPendingProject(storeFile, p.ID); err == nil {
+		t.Error("ERROR: removed requests should not be found")
+	}
+}
+
+func TestLoadPendingProjectsWithoutFile(t *testing.T) {
+	pending, err := loadPendingProjects(filepath.Join(os.TempDir(), "does-not-exist", "pending.json"))
+	if err != nil || pending == nil || len(pending) != 0 {
+		t.Errorf("ERROR: a missing file should return no requests, got %v (error: %v)", pending, err)
+	}
+}
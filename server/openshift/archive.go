@@ -0,0 +1,182 @@
+package openshift
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/publisher"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	archivedAtAnnotation       = "openshift.io/archived-at"
+	archiveGraceDaysAnnotation = "openshift.io/archive-daystodeletion"
+	defaultArchiveGraceDays    = 30
+	// add also works if replicas is not set
+	scaleToZeroPatch = `[{"op": "add", "path": "/spec/replicas", "value": 0}]`
+)
+
+// scalableResources are the workloads which are scaled to zero when a project is archived
+var scalableResources = []string{
+	"apis/apps/v1/namespaces/%v/deployments",
+	"apis/apps/v1/namespaces/%v/statefulsets",
+	"apis/apps.openshift.io/v1/namespaces/%v/deploymentconfigs",
+}
+
+func archiveProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.OpenshiftBase
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validateAdminAccess(data.ClusterId, username, data.Project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	graceDays, err := archiveProject(data.ClusterId, data.Project, username)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: fmt.Sprintf("Das Projekt %v wurde archiviert und wird in %v Tagen gelöscht", data.Project, graceDays),
+	})
+}
+
+// archiveProject scales the project to zero and marks it for the archive cleanup.
+// It returns the days until the project is deleted.
+func archiveProject(clusterId, project, username string) (int, error) {
+	if err := scaleProjectToZero(clusterId, project); err != nil {
+		return 0, err
+	}
+
+	graceDays := config.Config().GetInt("archive_grace_days")
+	if graceDays <= 0 {
+		graceDays = defaultArchiveGraceDays
+	}
+	err := updateNamespaceAnnotations(clusterId, project, func(annotations *gabs.Container) {
+		annotations.Set(time.Now().UTC().Format(time.RFC3339), archivedAtAnnotation)
+		annotations.Set(strconv.Itoa(graceDays), archiveGraceDaysAnnotation)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  clusterId,
+		"project":  project,
+		"username": username,
+	}).Info("AUDIT: Project was archived")
+	publisher.Publish(publisher.ProjectArchived, clusterId, project, username)
+	return graceDays, nil
+}
+
+func unarchiveProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.OpenshiftBase
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validateAdminAccess(data.ClusterId, username, data.Project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The workloads are not scaled up again, the replicas are unknown
+	err := updateNamespaceAnnotations(data.ClusterId, data.Project, func(annotations *gabs.Container) {
+		annotations.Delete(archivedAtAnnotation)
+		annotations.Delete(archiveGraceDaysAnnotation)
+	})
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  data.ClusterId,
+		"project":  data.Project,
+		"username": username,
+	}).Info("AUDIT: Project was unarchived")
+	publisher.Publish(publisher.ProjectUnarchived, data.ClusterId, data.Project, username)
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: fmt.Sprintf("Das Projekt %v ist nicht mehr archiviert. Die Deployments müssen manuell hochskaliert werden", data.Project),
+	})
+}
+
+// scaleProjectToZero sets the replicas of all workloads in the project to zero
+func scaleProjectToZero(clusterId, project string) error {
+	for _, resource := range scalableResources {
+		url := fmt.Sprintf(resource, project)
+		resp, err := getOseHTTPClient("GET", clusterId, url, nil)
+		if err != nil {
+			return err
+		}
+		json, err := parseJSONResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, item := range json.S("items").Children() {
+			name, _ := item.Path("metadata.name").Data().(string)
+			if replicas, ok := item.Path("spec.replicas").Data().(float64); ok && replicas == 0 {
+				continue
+			}
+			resp, err := getOseHTTPClient("PATCH", clusterId, url+"/"+name, bytes.NewReader([]byte(scaleToZeroPatch)))
+			if err != nil {
+				return err
+			}
+			if resp.StatusCode != http.StatusOK {
+				errMsg, _ := ioutil.ReadAll(resp.Body)
+				resp.Body.Close()
+				log.Printf("Error scaling %v/%v to zero: %v %v", url, name, resp.StatusCode, string(errMsg))
+				return newUpstreamError("PATCH", resp.StatusCode, errMsg)
+			}
+			resp.Body.Close()
+		}
+	}
+	return nil
+}
+
+// updateNamespaceAnnotations changes the annotations of the namespace with update
+func updateNamespaceAnnotations(clusterId, project string, update func(annotations *gabs.Container)) error {
+	return patchNamespaceAnnotations(clusterId, project, func(json *gabs.Container) {
+		update(json.Path("metadata.annotations"))
+	})
+}
+
+// isArchiveExpired checks if the grace period of an archived project is over
+func isArchiveExpired(project *gabs.Container, now time.Time) bool {
+	annotations := project.Path("metadata.annotations")
+	archivedAt, ok := annotations.S(archivedAtAnnotation).Data().(string)
+	if !ok {
+		return false
+	}
+	archived, err := time.Parse(time.RFC3339, archivedAt)
+	if err != nil {
+		log.Printf("Invalid %v annotation: %v", archivedAtAnnotation, archivedAt)
+		return false
+	}
+	graceDays := defaultArchiveGraceDays
+	if days, err := strconv.Atoi(fmt.Sprintf("%v", annotations.S(archiveGraceDaysAnnotation).Data())); err == nil {
+		graceDays = days
+	}
+	return now.After(archived.AddDate(0, 0, graceDays))
+}
@@ -0,0 +1,35 @@
+package openshift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestIsArchiveExpired(t *testing.T) {
+	now := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	var testsets = []struct {
+		name        string
+		annotations string
+		expired     bool
+	}{
+		{"not archived", `{}`, false},
+		{"in grace period", `{"openshift.io/archived-at": "2020-08-20T10:00:00Z", "openshift.io/archive-daystodeletion": "30"}`, false},
+		{"expired", `{"openshift.io/archived-at": "2020-08-01T09:00:00Z", "openshift.io/archive-daystodeletion": "30"}`, true},
+		{"custom grace period", `{"openshift.io/archived-at": "2020-08-20T10:00:00Z", "openshift.io/archive-daystodeletion": "7"}`, true},
+		{"invalid timestamp", `{"openshift.io/archived-at": "yesterday"}`, false},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.name, func(t *testing.T) {
+			project, err := gabs.ParseJSON([]byte(`{"metadata": {"annotations": ` + set.annotations + `}}`))
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			if expired := isArchiveExpired(project, now); expired != set.expired {
+				t.Errorf("ERROR: expired should be %v, but is %v", set.expired, expired)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package openshift
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// BillingProject is a project which is charged to the searched accounting number
+type BillingProject struct {
+	Cluster   string `json:"cluster"`
+	Project   string `json:"project"`
+	Billing   string `json:"billing"`
+	Requester string `json:"requester"`
+	MegaID    string `json:"megaId"`
+}
+
+// getProjectsByBillingHandler lists the projects of all clusters with the accounting number
+// given by the parameter billing. With prefix=true, all accounting numbers starting with billing match.
+func getProjectsByBillingHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	params := c.Request.URL.Query()
+	billing := params.Get("billing")
+	prefix, _ := strconv.ParseBool(params.Get("prefix"))
+
+	if billing == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "Kontierungsnummer muss angegeben werden", Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	log.Printf("%v searches projects with accounting number %v (prefix: %v)", username, billing, prefix)
+	billingProjects := []BillingProject{}
+	for _, cluster := range getOpenshiftClusters("") {
+		projects, err := getProjects(cluster.ID, username)
+		if err != nil {
+			common.RespondError(c, http.StatusBadGateway, err)
+			return
+		}
+		billingProjects = append(billingProjects, filterProjectsByBilling(cluster.ID, projects, billing, prefix)...)
+	}
+	c.JSON(http.StatusOK, billingProjects)
+}
+
+func filterProjectsByBilling(clusterId string, projects *gabs.Container, billing string, prefix bool) []BillingProject {
+	keys := getAnnotationKeys()
+	billingProjects := []BillingProject{}
+	for _, project := range projects.Children() {
+		objects := []*gabs.Container{project}
+		projectBilling := projectAnnotation(objects, keys.Billing, "openshift.io/kontierung-element")
+		if projectBilling == "" {
+			continue
+		}
+		if projectBilling != billing && !(prefix && strings.HasPrefix(projectBilling, billing)) {
+			continue
+		}
+		name, _ := project.Path("metadata.name").Data().(string)
+		requester := projectAnnotation(objects, keys.Requester, "openshift.io/requester")
+		megaID := projectAnnotation(objects, "openshift.io/MEGAID")
+		billingProjects = append(billingProjects, BillingProject{
+			Cluster:   clusterId,
+			Project:   name,
+			Billing:   projectBilling,
+			Requester: requester,
+			MegaID:    megaID,
+		})
+	}
+	return billingProjects
+}
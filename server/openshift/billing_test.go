@@ -0,0 +1,32 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestFilterProjectsByBilling(t *testing.T) {
+	projects, err := gabs.ParseJSON([]byte(`[
+		{"metadata": {"name": "a", "annotations": {"openshift.io/kontierung-element": "12345", "openshift.io/requester": "u1", "openshift.io/MEGAID": "m1"}}},
+		{"metadata": {"name": "b", "annotations": {"openshift.io/kontierung-element": "123456"}}},
+		{"metadata": {"name": "c", "annotations": {"openshift.io/kontierung-element": "99999"}}},
+		{"metadata": {"name": "d"}}
+	]`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+
+	exact := filterProjectsByBilling("test", projects, "12345", false)
+	if len(exact) != 1 {
+		t.Fatalf("ERROR: expected 1 project, got %v", len(exact))
+	}
+	expected := BillingProject{Cluster: "test", Project: "a", Billing: "12345", Requester: "u1", MegaID: "m1"}
+	if exact[0] != expected {
+		t.Errorf("ERROR: project should be %+v, but is: %+v", expected, exact[0])
+	}
+
+	if prefixed := filterProjectsByBilling("test", projects, "12345", true); len(prefixed) != 2 {
+		t.Errorf("ERROR: expected 2 projects with prefix matching, got %v", len(prefixed))
+	}
+}
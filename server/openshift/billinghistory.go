@@ -0,0 +1,94 @@
+package openshift
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	billingHistoryAnnotation = "openshift.io/kontierung-history"
+	// Older entries are removed, because the size of the annotations is limited
+	maxBillingHistoryEntries = 50
+)
+
+// BillingChange is an entry in the billing history of a project
+type BillingChange struct {
+	Timestamp string `json:"timestamp"`
+	// Empty if the billing was set for the first time
+	OldBilling string `json:"oldBilling"`
+	NewBilling string `json:"newBilling"`
+	Username   string `json:"username"`
+}
+
+func getBillingHistoryHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	params := c.Request.URL.Query()
+	clusterId := params.Get("clusterid")
+	project := params.Get("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	namespace, err := parseJSONResponse(resp)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getBillingHistory(namespace))
+}
+
+// getBillingHistory returns the billing history of the namespace, oldest entry first
+func getBillingHistory(namespace *gabs.Container) []BillingChange {
+	history := []BillingChange{}
+	value, ok := namespace.Path("metadata.annotations").S(billingHistoryAnnotation).Data().(string)
+	if !ok {
+		return history
+	}
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		log.Printf("Invalid %v annotation: %v", billingHistoryAnnotation, err)
+		return []BillingChange{}
+	}
+	return history
+}
+
+// appendBillingHistory adds an entry to the billing history if the billing changes
+func appendBillingHistory(namespace *gabs.Container, billing, username string, now time.Time) {
+	oldBilling, _ := namespace.Path("metadata.annotations").S(getAnnotationKeys().Billing).Data().(string)
+	if oldBilling == billing {
+		return
+	}
+
+	history := append(getBillingHistory(namespace), BillingChange{
+		Timestamp:  now.UTC().Format(time.RFC3339),
+		OldBilling: oldBilling,
+		NewBilling: billing,
+		Username:   username,
+	})
+	if len(history) > maxBillingHistoryEntries {
+		history = history[len(history)-maxBillingHistoryEntries:]
+	}
+
+	value, err := json.Marshal(history)
+	if err != nil {
+		log.Printf("Error encoding billing history: %v", err)
+		return
+	}
+	namespace.Path("metadata.annotations").Set(string(value), billingHistoryAnnotation)
+}
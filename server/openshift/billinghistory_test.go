@@ -0,0 +1,42 @@
+package openshift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestAppendBillingHistory(t *testing.T) {
+	namespace, err := gabs.ParseJSON([]byte(`{"metadata": {"annotations": {}}}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	now := time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	// first billing, no old value
+	setProjectMetadata(namespace, "1111", "", "", "", "u1", false)
+	// unchanged billing is not recorded
+	setProjectMetadata(namespace, "1111", "", "", "", "u1", false)
+	appendBillingHistory(namespace, "2222", "u2", now)
+
+	history := getBillingHistory(namespace)
+	if len(history) != 2 {
+		t.Fatalf("ERROR: expected 2 entries, got: %+v", history)
+	}
+	if history[0].OldBilling != "" || history[0].NewBilling != "1111" {
+		t.Errorf("ERROR: unexpected first entry: %+v", history[0])
+	}
+	expected := BillingChange{Timestamp: "2020-08-01T10:00:00Z", OldBilling: "1111", NewBilling: "2222", Username: "u2"}
+	if history[1] != expected {
+		t.Errorf("ERROR: expected %+v, got %+v", expected, history[1])
+	}
+
+	for i := 0; i < maxBillingHistoryEntries; i++ {
+		namespace.Path("metadata.annotations").Set("x", getAnnotationKeys().Billing)
+		appendBillingHistory(namespace, "y", "u3", now)
+	}
+	if history := getBillingHistory(namespace); len(history) != maxBillingHistoryEntries {
+		t.Errorf("ERROR: history should be capped at %v, but has %v entries", maxBillingHistoryEntries, len(history))
+	}
+}
@@ -0,0 +1,193 @@
+package openshift
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultSAPCacheTTL = 10 * time.Minute
+	maxSAPCacheEntries = 10000
+)
+
+// BillingValidator checks if an accounting number can be used for a project.
+// Warnings don't block the project, they are shown to the user.
+type BillingValidator interface {
+	Validate(billing string) ([]string, error)
+}
+
+type billingValidationConfig struct {
+	// none (default) or sap
+	Backend  string
+	URL      string
+	Username string
+	Password string
+	// Accept the accounting number with a warning if SAP is not reachable
+	SoftFail bool `mapstructure:"soft_fail"`
+	// Accept inactive accounting numbers with a warning, e.g. if they are activated after the project is requested
+	WarnInactive bool          `mapstructure:"warn_inactive"`
+	CacheTTL     time.Duration `mapstructure:"cache_ttl"`
+	Timeout      time.Duration
+}
+
+// patternBillingValidator only checks the format with billing_pattern.
+// It is used if no backend is configured.
+type patternBillingValidator struct {
+	pattern *regexp.Regexp
+}
+
+func (v patternBillingValidator) Validate(billing string) ([]string, error) {
+	if v.pattern != nil && !v.pattern.MatchString(billing) {
+		return nil, common.NewApiError(common.ErrorCodeInvalidBilling, fmt.Sprintf("Invalid accounting number %v", billing))
+	}
+	return nil, nil
+}
+
+// sapBillingValidator checks that the cost center exists and is active in SAP.
+// The format is checked first, so that SAP is not called for invalid input.
+type sapBillingValidator struct {
+	patternBillingValidator
+	url          string
+	username     string
+	password     string
+	softFail     bool
+	warnInactive bool
+	cacheTTL     time.Duration
+	client       *http.Client
+}
+
+type sapCostCenter struct {
+	Active bool `json:"active"`
+}
+
+// Valid accounting numbers
+var sapCache = common.NewCache(maxSAPCacheEntries)
+
+func (v sapBillingValidator) Validate(billing string) ([]string, error) {
+	if _, err := v.patternBillingValidator.Validate(billing); err != nil {
+		return nil, err
+	}
+
+	if _, ok := sapCache.Get(billing); ok {
+		return nil, nil
+	}
+
+	active, err := v.lookup(billing)
+	if err != nil {
+		if v.softFail {
+			log.Printf("WARNING: Accounting number %v could not be validated, SAP is not available: %v", billing, err)
+			return []string{fmt.Sprintf("Accounting number %v could not be validated in SAP", billing)}, nil
+		}
+		log.Printf("Error validating accounting number %v in SAP: %v", billing, err)
+		return nil, common.NewApiError(common.ErrorCodeBackendError, "The accounting number could not be validated. Please try again later")
+	}
+	if active == nil {
+		return nil, common.NewApiError(common.ErrorCodeInvalidBilling, fmt.Sprintf("Accounting number %v does not exist", billing))
+	}
+	if !*active {
+		if v.warnInactive {
+			// not cached, so the warning disappears as soon as the accounting number is active
+			return []string{fmt.Sprintf("Accounting number %v is not active yet", billing)}, nil
+		}
+		return nil, common.NewApiError(common.ErrorCodeInvalidBilling, fmt.Sprintf("Accounting number %v is not active", billing))
+	}
+
+	sapCache.Set(billing, true, v.cacheTTL)
+	return nil, nil
+}
+
+// lookup returns if the cost center is active, or nil if it doesn't exist.
+// An error is returned if SAP is not available.
+func (v sapBillingValidator) lookup(billing string) (*bool, error) {
+	req, err := http.NewRequest("GET", strings.TrimSuffix(v.url, "/")+"/costcenters/"+url.PathEscape(billing), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(v.username, v.password)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var costCenter sapCostCenter
+		if err := json.NewDecoder(resp.Body).Decode(&costCenter); err != nil {
+			return nil, fmt.Errorf("invalid response: %v", err)
+		}
+		return &costCenter.Active, nil
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("SAP returned %v", resp.StatusCode)
+}
+
+func getBillingValidator() (BillingValidator, error) {
+	patternValidator := patternBillingValidator{}
+	if pattern := config.Config().GetString("billing_pattern"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid billing_pattern: %v", err)
+		}
+		patternValidator.pattern = re
+	}
+
+	cfg := billingValidationConfig{}
+	if err := config.UnmarshalKey("billing_validation", &cfg); err != nil {
+		return nil, err
+	}
+	switch cfg.Backend {
+	case "", "none":
+		return patternValidator, nil
+	case "sap":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("billing_validation.url must be set for the sap backend")
+		}
+		client, err := common.GetOutboundClient(cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.CacheTTL <= 0 {
+			cfg.CacheTTL = defaultSAPCacheTTL
+		}
+		return sapBillingValidator{
+			patternBillingValidator: patternValidator,
+			url:                     cfg.URL,
+			username:                cfg.Username,
+			password:                cfg.Password,
+			softFail:                cfg.SoftFail,
+			warnInactive:            cfg.WarnInactive,
+			cacheTTL:                cfg.CacheTTL,
+			client:                  client,
+		}, nil
+	}
+	return nil, fmt.Errorf("Unknown billing_validation.backend: %v", cfg.Backend)
+}
+
+// ValidateBillingValidation checks billing_pattern and billing_validation at startup
+func ValidateBillingValidation() error {
+	_, err := getBillingValidator()
+	return err
+}
+
+// validateBilling checks the accounting number with the configured validator
+func validateBilling(billing string) ([]string, error) {
+	validator, err := getBillingValidator()
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		return nil, common.NewApiError(common.ErrorCodeBackendError, common.ConfigNotSetError)
+	}
+	return validator.Validate(billing)
+}
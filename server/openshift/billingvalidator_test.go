@@ -0,0 +1,100 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateBillingPattern(t *testing.T) {
+	config.Init("bla")
+	if _, err := validateBilling("anything"); err != nil {
+		t.Errorf("ERROR: without billing_pattern all accounting numbers should be valid, got: %v", err)
+	}
+
+	config.Config().Set("billing_pattern", `^\d{5,8}$`)
+	if _, err := validateBilling("12345"); err != nil {
+		t.Errorf("ERROR: unexpected error: %v", err)
+	}
+	_, err := validateBilling("12a45")
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeInvalidBilling {
+		t.Errorf("ERROR: expected invalid billing error, got: %v", apiErr)
+	}
+
+	config.Config().Set("billing_pattern", `(`)
+	if err := ValidateBillingValidation(); err == nil {
+		t.Error("ERROR: invalid billing_pattern should be reported")
+	}
+}
+
+func TestSAPBillingValidator(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if user, pass, ok := r.BasicAuth(); !ok || user != "ssp" || pass != "secret" {
+			t.Errorf("ERROR: SAP should be called with the credentials")
+		}
+		switch r.URL.Path {
+		case "/costcenters/11111":
+			w.Write([]byte(`{"active": true}`))
+		case "/costcenters/22222":
+			w.Write([]byte(`{"active": false}`))
+		case "/costcenters/33333":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config.Init("bla")
+	sapCache.Clear()
+	config.Config().Set("billing_validation", map[string]interface{}{"backend": "sap", "url": srv.URL, "username": "ssp", "password": "secret"})
+
+	var testsets = []struct {
+		billing string
+		code    string
+	}{
+		{"11111", ""},
+		{"22222", common.ErrorCodeInvalidBilling},
+		{"44444", common.ErrorCodeInvalidBilling},
+		{"33333", common.ErrorCodeBackendError},
+	}
+	for _, set := range testsets {
+		_, err := validateBilling(set.billing)
+		if set.code == "" {
+			if err != nil {
+				t.Errorf("ERROR: %v should be valid, got: %v", set.billing, err)
+			}
+			continue
+		}
+		if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != set.code {
+			t.Errorf("ERROR: %v should return %v, got: %v", set.billing, set.code, err)
+		}
+	}
+
+	requests = 0
+	if _, err := validateBilling("11111"); err != nil || requests != 0 {
+		t.Errorf("ERROR: valid accounting numbers should be cached, got %v requests and error %v", requests, err)
+	}
+
+	config.Config().Set("billing_validation", map[string]interface{}{"backend": "sap", "url": srv.URL, "username": "ssp", "password": "secret", "soft_fail": true})
+	if warnings, err := validateBilling("33333"); err != nil || len(warnings) != 1 {
+		t.Errorf("ERROR: with soft_fail, SAP downtime should only be a warning, got: %v, %v", warnings, err)
+	}
+	if _, err := validateBilling("22222"); err == nil {
+		t.Error("ERROR: with soft_fail, inactive accounting numbers should still be invalid")
+	}
+
+	config.Config().Set("billing_validation", map[string]interface{}{"backend": "sap", "url": srv.URL, "username": "ssp", "password": "secret", "warn_inactive": true})
+	if warnings, err := validateBilling("22222"); err != nil || len(warnings) != 1 || !strings.Contains(warnings[0], "not active yet") {
+		t.Errorf("ERROR: with warn_inactive, inactive accounting numbers should be a warning, got: %v, %v", warnings, err)
+	}
+	if _, err := validateBilling("44444"); err == nil {
+		t.Error("ERROR: with warn_inactive, unknown accounting numbers should still be invalid")
+	}
+}
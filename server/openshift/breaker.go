@@ -0,0 +1,167 @@
+package openshift
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerOpenTimeout      = 30 * time.Second
+)
+
+// States of the circuit breaker of a cluster
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+type breakerConfig struct {
+	// Consecutive failures until the breaker opens. A negative value disables the breaker
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// Time until a request is let through again to probe the cluster
+	OpenTimeout time.Duration `mapstructure:"open_timeout"`
+}
+
+// clusterBreaker stops the requests to a cluster after consecutive failures (open).
+// After open_timeout, a single request probes the cluster (half-open) and closes the breaker on success.
+type clusterBreaker struct {
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+var (
+	breakersMu  sync.Mutex
+	allBreakers = map[string]*clusterBreaker{}
+)
+
+func getBreakerConfig() breakerConfig {
+	cfg := breakerConfig{}
+	if err := config.UnmarshalKey("ose_circuit_breaker", &cfg); err != nil {
+		log.Printf("WARNING: invalid ose_circuit_breaker config: %v", err)
+	}
+	if cfg.FailureThreshold == 0 {
+		cfg.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if cfg.OpenTimeout <= 0 {
+		cfg.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	return cfg
+}
+
+func getClusterBreaker(clusterId string) *clusterBreaker {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	b, ok := allBreakers[clusterId]
+	if !ok {
+		b = &clusterBreaker{state: breakerClosed}
+		allBreakers[clusterId] = b
+	}
+	return b
+}
+
+// allow returns an error if the breaker is open or another request is probing the cluster
+func (b *clusterBreaker) allow(clusterId string, cfg breakerConfig, now time.Time) error {
+	if cfg.FailureThreshold < 0 {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == breakerOpen && now.Sub(b.openedAt) >= cfg.OpenTimeout {
+		log.Printf("Circuit breaker of cluster %v is half-open, probing the API", clusterId)
+		b.state = breakerHalfOpen
+	}
+	if b.state == breakerClosed {
+		return nil
+	}
+	if b.state == breakerHalfOpen && !b.probing {
+		b.probing = true
+		return nil
+	}
+	return common.NewApiError(common.ErrorCodeClusterUnavailable,
+		fmt.Sprintf("Die OpenShift API von Cluster %v ist nicht erreichbar. Bitte versuche es später nochmals", clusterId))
+}
+
+// record counts the result of a request which was allowed
+func (b *clusterBreaker) record(clusterId string, cfg breakerConfig, success bool, now time.Time) {
+	if cfg.FailureThreshold < 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if success {
+		if b.state != breakerClosed {
+			log.Printf("Circuit breaker of cluster %v is closed again", clusterId)
+		}
+		b.state = breakerClosed
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= cfg.FailureThreshold {
+		if b.state != breakerOpen {
+			log.Printf("WARNING: Circuit breaker of cluster %v is open after %v consecutive failures", clusterId, b.failures)
+		}
+		b.state = breakerOpen
+		b.openedAt = now
+	}
+}
+
+// cancel releases the probe of a request which was allowed but not sent
+func (b *clusterBreaker) cancel() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
+
+func (b *clusterBreaker) getState() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// ClusterBreakerStates returns the state of the circuit breaker of every cluster which has been called
+func ClusterBreakerStates() map[string]string {
+	breakersMu.Lock()
+	defer breakersMu.Unlock()
+	states := map[string]string{}
+	for clusterId, b := range allBreakers {
+		states[clusterId] = b.getState()
+	}
+	return states
+}
+
+// breakerMetrics returns the state of the circuit breakers in the Prometheus text format
+func breakerMetrics() string {
+	states := ClusterBreakerStates()
+	var clusterIds []string
+	for clusterId := range states {
+		clusterIds = append(clusterIds, clusterId)
+	}
+	sort.Strings(clusterIds)
+
+	metrics := "# HELP ssp_openshift_circuit_breaker_state State of the circuit breaker of the cluster (0 = closed, 1 = open, 2 = half-open).\n" +
+		"# TYPE ssp_openshift_circuit_breaker_state gauge\n"
+	for _, clusterId := range clusterIds {
+		value := 0
+		switch states[clusterId] {
+		case breakerOpen:
+			value = 1
+		case breakerHalfOpen:
+			value = 2
+		}
+		metrics += fmt.Sprintf("ssp_openshift_circuit_breaker_state{cluster=%q} %v\n", clusterId, value)
+	}
+	return metrics
+}
@@ -0,0 +1,121 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestClusterBreaker(t *testing.T) {
+	cfg := breakerConfig{FailureThreshold: 2, OpenTimeout: time.Minute}
+	b := &clusterBreaker{state: breakerClosed}
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if err := b.allow("test", cfg, now); err != nil {
+			t.Fatalf("ERROR: closed breaker should allow requests, got: %v", err)
+		}
+		b.record("test", cfg, false, now)
+	}
+	if b.getState() != breakerOpen {
+		t.Fatalf("ERROR: breaker should be open after 2 failures, got: %v", b.getState())
+	}
+	err := b.allow("test", cfg, now.Add(time.Second))
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeClusterUnavailable {
+		t.Errorf("ERROR: open breaker should return CLUSTER_UNAVAILABLE, got: %v", err)
+	}
+
+	// after the timeout, only one request probes the cluster
+	later := now.Add(time.Minute)
+	if err := b.allow("test", cfg, later); err != nil || b.getState() != breakerHalfOpen {
+		t.Fatalf("ERROR: breaker should be half-open and allow a probe, got: %v, %v", b.getState(), err)
+	}
+	if err := b.allow("test", cfg, later); err == nil {
+		t.Error("ERROR: only one probe should be allowed")
+	}
+	b.record("test", cfg, false, later)
+	if b.getState() != breakerOpen || b.allow("test", cfg, later.Add(time.Second)) == nil {
+		t.Errorf("ERROR: failed probe should open the breaker again, got: %v", b.getState())
+	}
+
+	later = later.Add(time.Minute)
+	if err := b.allow("test", cfg, later); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	b.record("test", cfg, true, later)
+	if b.getState() != breakerClosed || b.allow("test", cfg, later) != nil {
+		t.Errorf("ERROR: successful probe should close the breaker, got: %v", b.getState())
+	}
+
+	disabled := breakerConfig{FailureThreshold: -1}
+	b = &clusterBreaker{state: breakerClosed}
+	for i := 0; i < 10; i++ {
+		b.record("test", disabled, false, now)
+	}
+	if err := b.allow("test", disabled, now); err != nil {
+		t.Errorf("ERROR: disabled breaker should allow all requests, got: %v", err)
+	}
+}
+
+func TestGetOseHTTPClientCircuitBreaker(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("ose_circuit_breaker", map[string]interface{}{"failure_threshold": 3, "open_timeout": "1m"})
+
+	for i := 0; i < 3; i++ {
+		resp, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+		if err != nil {
+			t.Fatalf("ERROR: unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	_, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeClusterUnavailable || atomic.LoadInt32(&calls) != 3 {
+		t.Errorf("ERROR: open breaker should fail without calling the cluster, got: %v after %v calls", err, atomic.LoadInt32(&calls))
+	}
+	if states := ClusterBreakerStates(); states["test"] != breakerOpen {
+		t.Errorf("ERROR: unexpected states: %v", states)
+	}
+	if metrics := breakerMetrics(); !strings.Contains(metrics, `ssp_openshift_circuit_breaker_state{cluster="test"} 1`) {
+		t.Errorf("ERROR: unexpected metrics: %v", metrics)
+	}
+}
+
+func TestGetOseHTTPClientTimeoutOpensBreaker(t *testing.T) {
+	stalled := make(chan struct{})
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-stalled
+	}))
+	defer srv.Close()
+	defer close(stalled)
+	setTestCluster(srv.URL)
+	config.Config().Set("ose_request_timeout", "50ms")
+	config.Config().Set("ose_circuit_breaker", map[string]interface{}{"failure_threshold": 2, "open_timeout": "1m"})
+
+	for i := 0; i < 2; i++ {
+		start := time.Now()
+		if _, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil); err == nil {
+			t.Fatal("ERROR: a stalled cluster should return an error")
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("ERROR: the request should time out after ose_request_timeout, took %v", elapsed)
+		}
+	}
+	_, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeClusterUnavailable || atomic.LoadInt32(&calls) != 2 {
+		t.Errorf("ERROR: timeouts should open the breaker, got: %v after %v calls", err, atomic.LoadInt32(&calls))
+	}
+}
@@ -0,0 +1,210 @@
+package openshift
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/publisher"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCleanupConcurrency   = 4
+	defaultCleanupDeleteTimeout = time.Minute
+)
+
+// CleanupResult is the number of projects which were deleted by a cleanup run
+type CleanupResult struct {
+	Deleted int `json:"deleted"`
+	Failed  int `json:"failed"`
+	// Test projects whose requester has been warned about the deletion
+	Warned int `json:"warned"`
+}
+
+var (
+	// 1 while a cleanup run is active, so that runs don't overlap
+	cleanupRunning int32
+	// Totals since the start, see cleanupMetrics
+	cleanupDeletedTotal int64
+	cleanupFailedTotal  int64
+	cleanupSkippedTotal int64
+	cleanupWarnedTotal  int64
+)
+
+// StartArchiveCleanup deletes archived projects after their grace period and warns the requesters
+// of test projects before the deletion. It runs every archive_cleanup_interval (e.g. 1h) and is disabled if the interval is not set.
+func StartArchiveCleanup() {
+	interval := config.Config().GetDuration("archive_cleanup_interval")
+	if interval <= 0 {
+		return
+	}
+	log.Printf("Deleting archived projects every %v", interval)
+	go func() {
+		for range time.Tick(interval) {
+			if _, err := runArchiveCleanup(); err != nil {
+				log.Printf("Skipping the archive cleanup: %v", err)
+			}
+		}
+	}()
+}
+
+// runArchiveCleanupHandler starts a cleanup run on demand and returns its result
+func runArchiveCleanupHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	log.Printf("%v starts the archive cleanup", username)
+	result, err := runArchiveCleanup()
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// runArchiveCleanup deletes the expired archived projects of all clusters and sends the
+// deletion warnings of the test projects. It returns a conflict error if the previous run is still active.
+func runArchiveCleanup() (CleanupResult, error) {
+	if !atomic.CompareAndSwapInt32(&cleanupRunning, 0, 1) {
+		atomic.AddInt64(&cleanupSkippedTotal, 1)
+		return CleanupResult{}, common.NewApiError(common.ErrorCodeConflict, "The archive cleanup is already running")
+	}
+	defer atomic.StoreInt32(&cleanupRunning, 0)
+
+	concurrency, timeout := getCleanupLimits()
+	warningDays := getTestProjectWarningDays()
+	total := CleanupResult{}
+	now := time.Now()
+	for _, cluster := range getOpenshiftClusters("") {
+		clusterId := cluster.ID
+		projects, err := getProjects(clusterId, "")
+		if err != nil {
+			log.Printf("Error getting projects for the archive cleanup on cluster %v: %v", clusterId, err)
+			continue
+		}
+		if warningDays > 0 {
+			total.Warned += warnExpiringTestProjects(clusterId, projects, warningDays, now)
+		}
+		client, err := newOseClient(clusterId)
+		if err != nil {
+			log.Printf("Error getting the client for the archive cleanup on cluster %v: %v", clusterId, err)
+			continue
+		}
+		result := deleteProjectsConcurrently(getExpiredArchivedProjects(projects, now), concurrency, timeout, func(ctx context.Context, project string) error {
+			return deleteArchivedProject(ctx, client, project)
+		})
+		total.Deleted += result.Deleted
+		total.Failed += result.Failed
+	}
+	atomic.AddInt64(&cleanupDeletedTotal, int64(total.Deleted))
+	atomic.AddInt64(&cleanupFailedTotal, int64(total.Failed))
+	atomic.AddInt64(&cleanupWarnedTotal, int64(total.Warned))
+	log.Printf("Archive cleanup finished: %v projects deleted, %v failed, %v test projects warned", total.Deleted, total.Failed, total.Warned)
+	return total, nil
+}
+
+// getCleanupLimits returns archive_cleanup_concurrency and archive_cleanup_delete_timeout
+func getCleanupLimits() (int, time.Duration) {
+	cfg := config.Config()
+	concurrency := cfg.GetInt("archive_cleanup_concurrency")
+	if concurrency <= 0 {
+		concurrency = defaultCleanupConcurrency
+	}
+	timeout := cfg.GetDuration("archive_cleanup_delete_timeout")
+	if timeout <= 0 {
+		timeout = defaultCleanupDeleteTimeout
+	}
+	return concurrency, timeout
+}
+
+// deleteProjectsConcurrently deletes the projects with at most concurrency deletes at the same time.
+// A delete which takes longer than timeout is canceled and counted as failed, so it doesn't stall the other deletes.
+func deleteProjectsConcurrently(projects []string, concurrency int, timeout time.Duration, deleteProject func(ctx context.Context, project string) error) CleanupResult {
+	var (
+		mu     sync.Mutex
+		result CleanupResult
+	)
+	common.Parallel(len(projects), concurrency, nil, func(i int) {
+		err := deleteWithTimeout(projects[i], timeout, deleteProject)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			log.Printf("Error deleting project %v: %v", projects[i], err)
+			result.Failed++
+		} else {
+			result.Deleted++
+		}
+	})
+	return result
+}
+
+// deleteWithTimeout cancels the delete through its context after timeout. It returns when the
+// delete returned, so the number of deletes in flight is bounded by the concurrency.
+func deleteWithTimeout(project string, timeout time.Duration, deleteProject func(ctx context.Context, project string) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := deleteProject(ctx, project)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timeout after %v", timeout)
+	}
+	return err
+}
+
+func getExpiredArchivedProjects(projects *gabs.Container, now time.Time) []string {
+	var expired []string
+	for _, project := range projects.Children() {
+		if isArchiveExpired(project, now) {
+			name, _ := project.Path("metadata.name").Data().(string)
+			expired = append(expired, name)
+		}
+	}
+	return expired
+}
+
+func deleteArchivedProject(ctx context.Context, client *oseClient, project string) error {
+	clusterId := client.clusterId
+	resp, err := client.request(ctx, "DELETE", "apis/project.openshift.io/v1/projects/"+project, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%v %v", resp.StatusCode, string(errMsg))
+	}
+	log.WithFields(log.Fields{
+		"cluster": clusterId,
+		"project": project,
+	}).Info("AUDIT: Archived project was deleted after the grace period")
+	publisher.Publish(publisher.ProjectDeleted, clusterId, project, "")
+	return nil
+}
+
+// cleanupMetrics returns the counters of the archive cleanup in the Prometheus text format
+func cleanupMetrics() string {
+	return "# HELP ssp_archive_cleanup_deleted_total Number of archived projects deleted by the cleanup.\n" +
+		"# TYPE ssp_archive_cleanup_deleted_total counter\n" +
+		fmt.Sprintf("ssp_archive_cleanup_deleted_total %v\n", atomic.LoadInt64(&cleanupDeletedTotal)) +
+		"# HELP ssp_archive_cleanup_failed_total Number of archived projects the cleanup failed to delete.\n" +
+		"# TYPE ssp_archive_cleanup_failed_total counter\n" +
+		fmt.Sprintf("ssp_archive_cleanup_failed_total %v\n", atomic.LoadInt64(&cleanupFailedTotal)) +
+		"# HELP ssp_archive_cleanup_skipped_total Number of cleanup runs skipped because the previous run was still active.\n" +
+		"# TYPE ssp_archive_cleanup_skipped_total counter\n" +
+		fmt.Sprintf("ssp_archive_cleanup_skipped_total %v\n", atomic.LoadInt64(&cleanupSkippedTotal)) +
+		"# HELP ssp_testproject_warnings_total Number of deletion warnings sent for test projects.\n" +
+		"# TYPE ssp_testproject_warnings_total counter\n" +
+		fmt.Sprintf("ssp_testproject_warnings_total %v\n", atomic.LoadInt64(&cleanupWarnedTotal))
+}
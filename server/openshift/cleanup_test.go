@@ -0,0 +1,102 @@
+package openshift
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+)
+
+func TestDeleteProjectsConcurrently(t *testing.T) {
+	var (
+		mu         sync.Mutex
+		running    int
+		maxRunning int
+	)
+	deleteProject := func(ctx context.Context, project string) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			running--
+			mu.Unlock()
+		}()
+
+		switch project {
+		case "slow":
+			select {
+			case <-time.After(time.Second):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case "broken":
+			return errors.New("forbidden")
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+		return nil
+	}
+
+	projects := []string{"a", "slow", "b", "broken", "c", "d", "e"}
+	start := time.Now()
+	result := deleteProjectsConcurrently(projects, 2, 100*time.Millisecond, deleteProject)
+	if result.Deleted != 5 || result.Failed != 2 {
+		t.Errorf("ERROR: expected 5 deleted and 2 failed, got: %+v", result)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("ERROR: a slow delete should not stall the cleanup, took %v", time.Since(start))
+	}
+	if maxRunning > 2 {
+		// The timed out delete is canceled, not left running in the background
+		t.Errorf("ERROR: at most 2 deletes should run at the same time, got: %v", maxRunning)
+	}
+}
+
+func TestDeleteArchivedProjectTimeout(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+	setTestCluster(srv.URL)
+
+	client, err := newOseClient("test")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	start := time.Now()
+	err = deleteWithTimeout("project", 100*time.Millisecond, func(ctx context.Context, project string) error {
+		return deleteArchivedProject(ctx, client, project)
+	})
+	if err == nil || time.Since(start) > time.Second {
+		t.Errorf("ERROR: the DELETE should be canceled after the timeout, got %v after %v", err, time.Since(start))
+	}
+}
+
+func TestRunArchiveCleanupSkipsOverlappingRuns(t *testing.T) {
+	atomic.StoreInt32(&cleanupRunning, 1)
+	defer atomic.StoreInt32(&cleanupRunning, 0)
+
+	skipped := atomic.LoadInt64(&cleanupSkippedTotal)
+	_, err := runArchiveCleanup()
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict {
+		t.Errorf("ERROR: expected conflict error, got: %v", err)
+	}
+	if atomic.LoadInt64(&cleanupSkippedTotal) != skipped+1 {
+		t.Error("ERROR: skipped runs should be counted")
+	}
+}
@@ -1,10 +1,20 @@
 package openshift
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
 	"github.com/gin-gonic/gin"
 )
@@ -15,10 +25,35 @@ type OpenshiftCluster struct {
 	Optgroup string   `json:"optgroup"`
 	Features []string `json:"features"`
 	// exclude token from json marshal
-	Token      string      `json:"-"`
+	Token string `json:"-"`
+	// File with the token, e.g. a mounted secret. It is reloaded when it changes and takes precedence over Token
+	TokenFile  string      `json:"-" mapstructure:"token_file"`
 	URL        string      `json:"url"`
 	GlusterApi *GlusterApi `json:"-"`
 	NfsApi     *NfsApi     `json:"-"`
+	// CA bundle to verify the certificate of the cluster API
+	CAFile             string `json:"-" mapstructure:"ca_file"`
+	InsecureSkipVerify bool   `json:"-" mapstructure:"insecure_skip_verify"`
+	// Overrides the global quota config (e.g. max_quota_cpu)
+	Quota *QuotaConfig `json:"-"`
+	// ProjectRequest as JSON which is used as base for new projects, e.g. for node selectors
+	ProjectRequestTemplate string `json:"-" mapstructure:"project_request_template"`
+	// Proxy for the calls to the cluster API, overrides outbound_proxy
+	Proxy string `json:"-"`
+	// Additional headers for every call to the cluster API, e.g. for an authenticating proxy
+	Headers map[string]string `json:"-"`
+	// New projects must be approved by a member of project_approval.approver_group
+	ApprovalRequired bool `json:"approvalRequired" mapstructure:"approval_required"`
+	// Node selector annotation of new projects unless the project_request_template sets one
+	DefaultNodeSelector string `json:"-" mapstructure:"default_node_selector"`
+}
+
+type QuotaConfig struct {
+	// CPU in cores, memory in GiB
+	DefaultCPU    int `mapstructure:"default_cpu"`
+	DefaultMemory int `mapstructure:"default_memory"`
+	MaxCPU        int `mapstructure:"max_cpu"`
+	MaxMemory     int `mapstructure:"max_memory"`
 }
 
 type GlusterApi struct {
@@ -44,7 +79,7 @@ func clustersHandler(c *gin.Context) {
 func getOpenshiftClusters(feature string) []OpenshiftCluster {
 	log.Printf("Looking up clusters with the following features %v", feature)
 	clusters := []OpenshiftCluster{}
-	config.Config().UnmarshalKey("openshift", &clusters)
+	config.UnmarshalKey("openshift", &clusters)
 	if feature != "" {
 		tmp := []OpenshiftCluster{}
 		for _, p := range clusters {
@@ -106,3 +141,146 @@ func getStorageClass(clusterId, technology string) (string, error) {
 	}
 	return storageclass, nil
 }
+
+// ValidateClusters checks the cluster configuration at startup,
+// so that misconfigured CA files are noticed before the first request
+func ValidateClusters() error {
+	for _, cluster := range getOpenshiftClusters("") {
+		if _, err := getClusterTLSConfig(cluster); err != nil {
+			return err
+		}
+		if _, err := newProjectRequest(cluster, "validation"); err != nil {
+			return err
+		}
+		if cluster.DefaultNodeSelector != "" {
+			if err := validateNodeSelector(cluster.DefaultNodeSelector); err != nil {
+				return fmt.Errorf("Invalid default_node_selector of cluster %v: %v", cluster.ID, err)
+			}
+		}
+		if _, err := common.GetProxyFunc(cluster.Proxy); err != nil {
+			return fmt.Errorf("Invalid proxy of cluster %v: %v", cluster.ID, err)
+		}
+		if _, err := getClusterToken(cluster); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type cachedToken struct {
+	token   string
+	modTime time.Time
+	size    int64
+}
+
+var (
+	tokenCache      = map[string]cachedToken{}
+	tokenCacheMutex sync.Mutex
+)
+
+// getClusterToken returns the token of the cluster. If token_file is set, the file is
+// read again as soon as its modification time or size changes, so rotated secrets are used
+// without a restart. While the file is rotated (missing or empty), the last token is used.
+// Without a token file, the token of the config is used.
+func getClusterToken(cluster OpenshiftCluster) (string, error) {
+	if cluster.TokenFile == "" {
+		return cluster.Token, nil
+	}
+
+	tokenCacheMutex.Lock()
+	defer tokenCacheMutex.Unlock()
+
+	cached, ok := tokenCache[cluster.TokenFile]
+	info, err := os.Stat(cluster.TokenFile)
+	if err == nil && ok && info.ModTime().Equal(cached.modTime) && info.Size() == cached.size {
+		return cached.token, nil
+	}
+	var content []byte
+	if err == nil {
+		content, err = ioutil.ReadFile(cluster.TokenFile)
+	}
+	token := strings.TrimSpace(string(content))
+	if err == nil && token == "" {
+		err = errors.New("file is empty")
+	}
+	if err != nil {
+		if ok {
+			log.Printf("WARNING: Could not reload token file of cluster %v, using the last token: %v", cluster.ID, err)
+			return cached.token, nil
+		}
+		if cluster.Token != "" {
+			log.Printf("WARNING: Could not read token file of cluster %v, using the token of the config: %v", cluster.ID, err)
+			return cluster.Token, nil
+		}
+		return "", fmt.Errorf("Could not read token file of cluster %v: %v", cluster.ID, err)
+	}
+
+	if ok {
+		log.Printf("Token file of cluster %v has changed, using the new token", cluster.ID)
+	}
+	tokenCache[cluster.TokenFile] = cachedToken{token: token, modTime: info.ModTime(), size: info.Size()}
+	return token, nil
+}
+
+type cachedCAPool struct {
+	pool    *x509.CertPool
+	modTime time.Time
+	size    int64
+}
+
+var (
+	caPoolCache      = map[string]cachedCAPool{}
+	caPoolCacheMutex sync.Mutex
+)
+
+// getClusterTLSConfig returns the TLS config of the cluster. The CA file is only read and parsed
+// again when its modification time or size changes, not on every request.
+func getClusterTLSConfig(cluster OpenshiftCluster) (*tls.Config, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: cluster.InsecureSkipVerify}
+	if cluster.CAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caPoolCacheMutex.Lock()
+	defer caPoolCacheMutex.Unlock()
+
+	info, err := os.Stat(cluster.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read CA file of cluster %v: %v", cluster.ID, err)
+	}
+	if cached, ok := caPoolCache[cluster.CAFile]; ok && info.ModTime().Equal(cached.modTime) && info.Size() == cached.size {
+		tlsConfig.RootCAs = cached.pool
+		return tlsConfig, nil
+	}
+
+	caCert, err := ioutil.ReadFile(cluster.CAFile)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read CA file of cluster %v: %v", cluster.ID, err)
+	}
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("Could not parse CA file of cluster %v: %v", cluster.ID, cluster.CAFile)
+	}
+	caPoolCache[cluster.CAFile] = cachedCAPool{pool: caCertPool, modTime: info.ModTime(), size: info.Size()}
+	tlsConfig.RootCAs = caCertPool
+	return tlsConfig, nil
+}
+
+// newProjectRequest returns the ProjectRequest for a new project.
+// The project_request_template of the cluster is used as base if it is set.
+func newProjectRequest(cluster OpenshiftCluster, project string) (*gabs.Container, error) {
+	if cluster.ProjectRequestTemplate == "" {
+		return newObjectRequest("ProjectRequest", project, "project.openshift.io/v1"), nil
+	}
+	p, err := gabs.ParseJSON([]byte(cluster.ProjectRequestTemplate))
+	if err != nil {
+		return nil, fmt.Errorf("Invalid project_request_template of cluster %v: %v", cluster.ID, err)
+	}
+	if _, ok := p.Data().(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("Invalid project_request_template of cluster %v: must be a JSON object", cluster.ID)
+	}
+	p.Set("ProjectRequest", "kind")
+	p.Set("project.openshift.io/v1", "apiVersion")
+	p.SetP(project, "metadata.name")
+	return p, nil
+}
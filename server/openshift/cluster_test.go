@@ -0,0 +1,120 @@
+package openshift
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewProjectRequest(t *testing.T) {
+	p, err := newProjectRequest(OpenshiftCluster{ID: "test"}, "myproject")
+	if err != nil || p.Path("metadata.name").Data() != "myproject" || p.S("kind").Data() != "ProjectRequest" {
+		t.Errorf("ERROR: unexpected default ProjectRequest: %v, error: %v", p, err)
+	}
+
+	cluster := OpenshiftCluster{
+		ID:                     "test",
+		ProjectRequestTemplate: `{"metadata": {"name": "template", "annotations": {"openshift.io/node-selector": "zone=a"}}}`,
+	}
+	p, err = newProjectRequest(cluster, "myproject")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if p.Path("metadata.name").Data() != "myproject" || p.S("apiVersion").Data() != "project.openshift.io/v1" {
+		t.Errorf("ERROR: name and apiVersion should be set: %v", p)
+	}
+	if p.Path("metadata.annotations").S("openshift.io/node-selector").Data() != "zone=a" {
+		t.Errorf("ERROR: annotations of the template should be kept: %v", p)
+	}
+
+	for _, template := range []string{`{"metadata": `, `[]`} {
+		if _, err := newProjectRequest(OpenshiftCluster{ID: "test", ProjectRequestTemplate: template}, "myproject"); err == nil {
+			t.Errorf("ERROR: template %v should be invalid", template)
+		}
+	}
+}
+
+func TestGetClusterTokenFromFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "token")
+	cluster := OpenshiftCluster{ID: "test", Token: "config-token", TokenFile: file}
+
+	if token, err := getClusterToken(cluster); err != nil || token != "config-token" {
+		t.Errorf("ERROR: without the file, the token of the config should be used, got: '%v', %v", token, err)
+	}
+	if _, err := getClusterToken(OpenshiftCluster{ID: "test", TokenFile: file}); err == nil {
+		t.Error("ERROR: missing token file without a token in the config should return an error")
+	}
+
+	ioutil.WriteFile(file, []byte("first\n"), 0600)
+	if token, _ := getClusterToken(cluster); token != "first" {
+		t.Errorf("ERROR: token of the file should be used, got: '%v'", token)
+	}
+
+	// rotation: the file is empty for a moment, then contains the new token
+	ioutil.WriteFile(file, []byte(""), 0600)
+	if token, _ := getClusterToken(cluster); token != "first" {
+		t.Errorf("ERROR: while the file is rotated, the last token should be used, got: '%v'", token)
+	}
+	ioutil.WriteFile(file, []byte("second"), 0600)
+	os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if token, _ := getClusterToken(cluster); token != "second" {
+		t.Errorf("ERROR: changed token file should be reloaded, got: '%v'", token)
+	}
+}
+
+func TestGetClusterTLSConfig(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{}"))
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "ca")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "ca.crt")
+	ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0600)
+
+	tlsConfig, err := getClusterTLSConfig(OpenshiftCluster{ID: "test"})
+	if err != nil || tlsConfig.InsecureSkipVerify || tlsConfig.RootCAs != nil {
+		t.Errorf("ERROR: without ca_file the system CAs should be verified, got: %+v, %v", tlsConfig, err)
+	}
+	if tlsConfig, _ := getClusterTLSConfig(OpenshiftCluster{ID: "test", InsecureSkipVerify: true}); !tlsConfig.InsecureSkipVerify {
+		t.Error("ERROR: insecure_skip_verify should disable the verification")
+	}
+
+	cluster := OpenshiftCluster{ID: "test", CAFile: file}
+	tlsConfig, err = getClusterTLSConfig(cluster)
+	if err != nil || tlsConfig.RootCAs == nil {
+		t.Fatalf("ERROR: the CA file should be used, got: %v", err)
+	}
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("ERROR: the certificate should be verified with the CA file, got: %v", err)
+	}
+	resp.Body.Close()
+	if cached, _ := getClusterTLSConfig(cluster); cached.RootCAs != tlsConfig.RootCAs {
+		t.Error("ERROR: the CA file should only be parsed again if it changes")
+	}
+
+	ioutil.WriteFile(file, []byte("no certificate"), 0600)
+	os.Chtimes(file, time.Now().Add(time.Minute), time.Now().Add(time.Minute))
+	if _, err := getClusterTLSConfig(cluster); err == nil {
+		t.Error("ERROR: an invalid CA file should return an error")
+	}
+	if _, err := getClusterTLSConfig(OpenshiftCluster{ID: "test", CAFile: filepath.Join(dir, "missing")}); err == nil {
+		t.Error("ERROR: a missing CA file should return an error")
+	}
+}
@@ -0,0 +1,79 @@
+package openshift
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+type clusterAccessConfig struct {
+	// Clusters of users which are in none of the groups. Empty means all clusters
+	DefaultClusters []string             `mapstructure:"default_clusters"`
+	Groups          []clusterAccessGroup `mapstructure:"groups"`
+}
+
+type clusterAccessGroup struct {
+	Group    string   `mapstructure:"group"`
+	Clusters []string `mapstructure:"clusters"`
+}
+
+// allowedClusters returns the clusters of all mapped groups of the user, or the default clusters
+// if the user is in none of them. nil means that all clusters are allowed.
+func (cfg clusterAccessConfig) allowedClusters(userGroups []string) []string {
+	var allowed []string
+	mapped := false
+	for _, g := range cfg.Groups {
+		if common.ContainsStringI(userGroups, g.Group) {
+			mapped = true
+			allowed = append(allowed, g.Clusters...)
+		}
+	}
+	if !mapped {
+		if len(cfg.DefaultClusters) == 0 {
+			return nil
+		}
+		allowed = cfg.DefaultClusters
+	}
+	allowed = common.RemoveDuplicates(allowed)
+	sort.Strings(allowed)
+	return allowed
+}
+
+// validateClusterAccess checks with cluster_access if the LDAP groups of the user allow the cluster.
+// Without cluster_access, all clusters are allowed.
+func validateClusterAccess(username, clusterId string) error {
+	if !config.Config().IsSet("cluster_access") {
+		return nil
+	}
+	cfg := clusterAccessConfig{}
+	if err := config.UnmarshalKey("cluster_access", &cfg); err != nil {
+		log.Printf("WARNING: invalid cluster_access config: %v", err)
+		return common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+
+	var userGroups []string
+	if len(cfg.Groups) > 0 {
+		groups, err := getLdapGroupsOfUser(username)
+		if err != nil {
+			return err
+		}
+		userGroups = groups
+	}
+	return checkClusterAccess(cfg.allowedClusters(userGroups), clusterId)
+}
+
+func checkClusterAccess(allowed []string, clusterId string) error {
+	if allowed == nil || common.ContainsStringI(allowed, clusterId) {
+		return nil
+	}
+	names := "none"
+	if len(allowed) > 0 {
+		names = strings.Join(allowed, ", ")
+	}
+	return common.NewApiError(common.ErrorCodeForbidden,
+		fmt.Sprintf("You are not allowed to use the cluster %v. Allowed clusters: %v", clusterId, names))
+}
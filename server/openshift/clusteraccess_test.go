@@ -0,0 +1,59 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestAllowedClusters(t *testing.T) {
+	cfg := clusterAccessConfig{
+		DefaultClusters: []string{"awsdev"},
+		Groups: []clusterAccessGroup{
+			{Group: "DG_TEAM_A", Clusters: []string{"awsdev", "awsprod"}},
+			{Group: "DG_TEAM_B", Clusters: []string{"azureprod"}},
+		},
+	}
+
+	var testsets = []struct {
+		groups    []string
+		clusterId string
+		allowed   bool
+	}{
+		{[]string{"dg_team_a"}, "awsprod", true},
+		{[]string{"DG_TEAM_B"}, "awsdev", false},
+		{[]string{"DG_TEAM_A", "DG_TEAM_B"}, "azureprod", true},
+		{[]string{"DG_OTHER"}, "awsdev", true},
+		{[]string{"DG_OTHER"}, "awsprod", false},
+	}
+	for _, set := range testsets {
+		err := checkClusterAccess(cfg.allowedClusters(set.groups), set.clusterId)
+		if set.allowed != (err == nil) {
+			t.Errorf("ERROR: groups %v on %v should be allowed: %v, got: %v", set.groups, set.clusterId, set.allowed, err)
+		}
+	}
+
+	err := checkClusterAccess(cfg.allowedClusters([]string{"DG_TEAM_B"}), "awsdev")
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeForbidden ||
+		apiErr.Message != "You are not allowed to use the cluster awsdev. Allowed clusters: azureprod" {
+		t.Errorf("ERROR: the error should list the allowed clusters, got: %v", err)
+	}
+
+	if allowed := (clusterAccessConfig{}).allowedClusters(nil); allowed != nil {
+		t.Errorf("ERROR: without default clusters all clusters should be allowed, got: %v", allowed)
+	}
+}
+
+func TestValidateClusterAccessWithoutConfig(t *testing.T) {
+	config.Init("bla")
+	if err := validateClusterAccess("u123456", "awsprod"); err != nil {
+		t.Errorf("ERROR: without cluster_access all clusters should be allowed, got: %v", err)
+	}
+
+	// Without groups, LDAP is not needed
+	config.Config().Set("cluster_access", map[string]interface{}{"default_clusters": []string{"awsdev"}})
+	if err := validateClusterAccess("u123456", "awsprod"); err == nil {
+		t.Error("ERROR: only the default clusters should be allowed")
+	}
+}
@@ -0,0 +1,125 @@
+package openshift
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCMDBCacheTTL = 5 * time.Minute
+	// Failures are cached shortly, so that an unavailable CMDB doesn't slow down every request
+	cmdbFailureCacheTTL = 30 * time.Second
+	maxCMDBCacheEntries = 10000
+)
+
+// ProjectOwnership is the team which owns a project according to the CMDB
+type ProjectOwnership struct {
+	Team  string `json:"team"`
+	Owner string `json:"owner,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type cmdbConfig struct {
+	// The ownership is read from <url>/<key>. Disabled if empty
+	URL      string
+	Username string
+	Password string
+	// project (default) or megaid
+	Key      string
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+	Timeout  time.Duration
+}
+
+// The ownership per key, nil if the CMDB doesn't know the key or is not reachable
+var cmdbCache = common.NewCache(maxCMDBCacheEntries)
+
+// getProjectOwnership returns the ownership of the project from the CMDB, or nil if the CMDB is not
+// configured, doesn't know the project or is not reachable. The project information is returned anyway.
+func getProjectOwnership(project, megaId string) *ProjectOwnership {
+	cfg := cmdbConfig{}
+	if err := config.UnmarshalKey("cmdb", &cfg); err != nil {
+		log.Printf("WARNING: invalid cmdb config: %v", err)
+		return nil
+	}
+	if cfg.URL == "" {
+		return nil
+	}
+
+	var key string
+	switch cfg.Key {
+	case "", "project":
+		key = project
+	case "megaid":
+		key = megaId
+	default:
+		log.Printf("WARNING: unknown cmdb.key: %v", cfg.Key)
+		return nil
+	}
+	if key == "" {
+		return nil
+	}
+
+	if cached, ok := cmdbCache.Get(key); ok {
+		return cached.(*ProjectOwnership)
+	}
+
+	ownership, err := lookupProjectOwnership(cfg, key)
+	if err != nil {
+		log.Printf("WARNING: Can't read the ownership of project %v from the CMDB: %v", project, err)
+		cmdbCache.Set(key, (*ProjectOwnership)(nil), cmdbFailureCacheTTL)
+		return nil
+	}
+	if ownership == nil {
+		log.Printf("WARNING: Project %v is unknown in the CMDB (key %v)", project, key)
+	}
+
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = defaultCMDBCacheTTL
+	}
+	cmdbCache.Set(key, ownership, cfg.CacheTTL)
+	return ownership
+}
+
+// lookupProjectOwnership returns nil if the key doesn't exist in the CMDB.
+// An error is returned if the CMDB is not available.
+func lookupProjectOwnership(cfg cmdbConfig, key string) (*ProjectOwnership, error) {
+	client, err := common.GetOutboundClient(cfg.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(cfg.URL, "/")+"/"+url.PathEscape(key), nil)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var ownership ProjectOwnership
+		if err := json.NewDecoder(resp.Body).Decode(&ownership); err != nil {
+			return nil, fmt.Errorf("invalid response: %v", err)
+		}
+		return &ownership, nil
+	case http.StatusNotFound:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("CMDB returned %v", resp.StatusCode)
+}
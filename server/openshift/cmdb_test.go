@@ -0,0 +1,61 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestGetProjectOwnership(t *testing.T) {
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/projects/shop", "/projects/1234":
+			w.Write([]byte(`{"team": "Webshop", "owner": "u123456", "email": "webshop@example.com"}`))
+		case "/projects/broken":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	config.Init("bla")
+	cmdbCache.Clear()
+	if ownership := getProjectOwnership("shop", "1234"); ownership != nil || requests != 0 {
+		t.Errorf("ERROR: without cmdb.url the CMDB should not be called, got: %v", ownership)
+	}
+
+	config.Config().Set("cmdb", map[string]interface{}{"url": srv.URL + "/projects"})
+	ownership := getProjectOwnership("shop", "")
+	if ownership == nil || ownership.Team != "Webshop" || ownership.Owner != "u123456" {
+		t.Errorf("ERROR: unexpected ownership: %+v", ownership)
+	}
+	requests = 0
+	if getProjectOwnership("shop", ""); requests != 0 {
+		t.Error("ERROR: the ownership should be cached")
+	}
+	if ownership := getProjectOwnership("unknown", ""); ownership != nil {
+		t.Errorf("ERROR: unknown projects should have no ownership, got: %+v", ownership)
+	}
+	if ownership := getProjectOwnership("broken", ""); ownership != nil {
+		t.Errorf("ERROR: CMDB errors should be ignored, got: %+v", ownership)
+	}
+	requests = 0
+	if getProjectOwnership("broken", ""); requests != 0 {
+		t.Error("ERROR: errors should be cached shortly")
+	}
+
+	cmdbCache.Clear()
+	config.Config().Set("cmdb", map[string]interface{}{"url": srv.URL + "/projects", "key": "megaid", "cache_ttl": time.Minute})
+	if ownership := getProjectOwnership("other", "1234"); ownership == nil || ownership.Team != "Webshop" {
+		t.Errorf("ERROR: the ownership should be read by MegaID, got: %+v", ownership)
+	}
+	if ownership := getProjectOwnership("shop", ""); ownership != nil {
+		t.Errorf("ERROR: projects without MegaID should have no ownership, got: %+v", ownership)
+	}
+}
@@ -0,0 +1,117 @@
+package openshift
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Max time a request waits for a free slot, if ose_max_concurrent is reached
+const oseSlotTimeout = 10 * time.Second
+
+// clusterSlots limits the concurrent requests to the API of a cluster
+type clusterSlots struct {
+	slots    chan struct{}
+	inFlight int64
+}
+
+var (
+	clusterSlotsMu  sync.Mutex
+	allClusterSlots = map[string]*clusterSlots{}
+)
+
+func getClusterSlots(clusterId string) *clusterSlots {
+	clusterSlotsMu.Lock()
+	defer clusterSlotsMu.Unlock()
+	s, ok := allClusterSlots[clusterId]
+	if !ok {
+		s = &clusterSlots{}
+		// 0 means unlimited
+		if limit := config.Config().GetInt("ose_max_concurrent"); limit > 0 {
+			s.slots = make(chan struct{}, limit)
+		}
+		allClusterSlots[clusterId] = s
+	}
+	return s
+}
+
+// acquireClusterSlot blocks until a slot for the cluster is free.
+// The returned function must be called to release the slot.
+func acquireClusterSlot(clusterId string, timeout time.Duration) (func(), error) {
+	s := getClusterSlots(clusterId)
+	if s.slots != nil {
+		select {
+		case s.slots <- struct{}{}:
+		case <-time.After(timeout):
+			log.Printf("WARNING: No free slot for a request to cluster %v after %v", clusterId, timeout)
+			return nil, common.NewApiError(common.ErrorCodeBackendBusy, "Die OpenShift API ist ausgelastet. Bitte versuche es später nochmals")
+		}
+	}
+
+	clusterSlotsMu.Lock()
+	s.inFlight++
+	clusterSlotsMu.Unlock()
+
+	return func() {
+		clusterSlotsMu.Lock()
+		s.inFlight--
+		clusterSlotsMu.Unlock()
+		if s.slots != nil {
+			<-s.slots
+		}
+	}, nil
+}
+
+// releasingBody releases the slot of the request when the response body is read completely or closed.
+// Releasing at EOF frees the slot even if the caller makes further requests before the deferred Close.
+type releasingBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+func (b *releasingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.once.Do(b.release)
+	}
+	return n, err
+}
+
+func (b *releasingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// MetricsHandler returns the requests in flight and the circuit breaker state per cluster and the counters
+// of the archive cleanup and the mails in the Prometheus text format
+func MetricsHandler(c *gin.Context) {
+	clusterSlotsMu.Lock()
+	var clusterIds []string
+	inFlight := map[string]int64{}
+	for clusterId, s := range allClusterSlots {
+		clusterIds = append(clusterIds, clusterId)
+		inFlight[clusterId] = s.inFlight
+	}
+	clusterSlotsMu.Unlock()
+	sort.Strings(clusterIds)
+
+	metrics := "# HELP ssp_openshift_requests_in_flight Current number of requests to the OpenShift API.\n" +
+		"# TYPE ssp_openshift_requests_in_flight gauge\n"
+	for _, clusterId := range clusterIds {
+		metrics += fmt.Sprintf("ssp_openshift_requests_in_flight{cluster=%q} %v\n", clusterId, inFlight[clusterId])
+	}
+	metrics += cleanupMetrics()
+	metrics += mailMetrics()
+	metrics += breakerMetrics()
+	c.Data(http.StatusOK, "text/plain; version=0.0.4", []byte(metrics))
+}
@@ -0,0 +1,72 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestAcquireClusterSlot(t *testing.T) {
+	config.Init("bla")
+	config.Config().Set("ose_max_concurrent", 1)
+
+	release, err := acquireClusterSlot("slots", time.Millisecond)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	_, err = acquireClusterSlot("slots", 10*time.Millisecond)
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeBackendBusy {
+		t.Errorf("ERROR: expected busy error, got: %v", err)
+	}
+	release()
+
+	release, err = acquireClusterSlot("slots", time.Millisecond)
+	if err != nil {
+		t.Errorf("ERROR: slot should be free after release, got: %v", err)
+	} else {
+		release()
+	}
+}
+
+func TestClusterSlotHeldUntilBodyClosed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("ose_max_concurrent", 1)
+	defer setTestCluster(srv.URL)
+
+	resp, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if inFlight := getClusterSlots("test").inFlight; inFlight != 1 {
+		t.Errorf("ERROR: the request should be in flight until the body is closed, got: %v", inFlight)
+	}
+	if _, err := acquireClusterSlot("test", time.Millisecond); err == nil {
+		t.Error("ERROR: the slot should be held until the body is closed")
+	}
+	resp.Body.Close()
+	resp.Body.Close()
+	if inFlight := getClusterSlots("test").inFlight; inFlight != 0 {
+		t.Errorf("ERROR: closing the body should release the slot once, got %v in flight", inFlight)
+	}
+
+	// parseJSONResponse reads the body completely, which releases the slot before the deferred Close
+	resp, err = getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if _, err := parseJSONResponse(resp); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if inFlight := getClusterSlots("test").inFlight; inFlight != 0 {
+		t.Errorf("ERROR: reading the body completely should release the slot, got %v in flight", inFlight)
+	}
+}
@@ -0,0 +1,161 @@
+package openshift
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Average number of hours per month
+const hoursPerMonth = 730
+
+// CostModel are the prices of cost_model in the config
+type CostModel struct {
+	Currency        string  `mapstructure:"currency"`
+	CPUCoreHour     float64 `mapstructure:"cpu_core_hour"`
+	MemoryGiBHour   float64 `mapstructure:"memory_gib_hour"`
+	StorageGiBMonth float64 `mapstructure:"storage_gib_month"`
+}
+
+// CostEstimate is the estimated monthly cost of the quota of a project
+type CostEstimate struct {
+	Cluster    string  `json:"cluster"`
+	Project    string  `json:"project"`
+	Currency   string  `json:"currency"`
+	CPUCores   float64 `json:"cpuCores"`
+	MemoryGiB  float64 `json:"memoryGiB"`
+	StorageGiB float64 `json:"storageGiB"`
+	// Monthly costs
+	CPUCost     float64 `json:"cpuCost"`
+	MemoryCost  float64 `json:"memoryCost"`
+	StorageCost float64 `json:"storageCost"`
+	Total       float64 `json:"total"`
+	// Set if the cost can't be estimated, e.g. if the project has no quota
+	Note string `json:"note,omitempty"`
+}
+
+func getProjectCostHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	clusterId := c.Query("clusterid")
+	project := c.Query("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	costModel, err := getCostModel()
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	quota, err := getQuotas(clusterId, project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	estimate, err := estimateCost(quota, *costModel)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	estimate.Cluster = clusterId
+	estimate.Project = project
+	c.JSON(http.StatusOK, estimate)
+}
+
+func getCostModel() (*CostModel, error) {
+	if !config.Config().IsSet("cost_model") {
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	costModel := CostModel{}
+	if err := config.UnmarshalKey("cost_model", &costModel); err != nil {
+		log.Printf("WARNING: invalid cost_model config: %v", err)
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	return &costModel, nil
+}
+
+// estimateCost calculates the monthly cost of the hard limits of the ResourceQuota
+func estimateCost(quota *gabs.Container, costModel CostModel) (CostEstimate, error) {
+	estimate := CostEstimate{Currency: costModel.Currency}
+	if quota == nil || !quota.ExistsP("spec.hard") {
+		estimate.Note = "The project has no quota, the cost can't be estimated"
+		return estimate, nil
+	}
+	hard := quota.Path("spec.hard")
+
+	cpu, err := quotaValue(hard, "cpu", "limits.cpu", "requests.cpu")
+	if err != nil {
+		return estimate, err
+	}
+	memory, err := quotaValue(hard, "memory", "limits.memory", "requests.memory")
+	if err != nil {
+		return estimate, err
+	}
+	storage, err := quotaValue(hard, "requests.storage")
+	if err != nil {
+		return estimate, err
+	}
+
+	gib := math.Pow(1024, 3)
+	estimate.CPUCores = cpu
+	estimate.MemoryGiB = roundCost(memory / gib)
+	estimate.StorageGiB = roundCost(storage / gib)
+	estimate.CPUCost = roundCost(cpu * costModel.CPUCoreHour * hoursPerMonth)
+	estimate.MemoryCost = roundCost(memory / gib * costModel.MemoryGiBHour * hoursPerMonth)
+	estimate.StorageCost = roundCost(storage / gib * costModel.StorageGiBMonth)
+	estimate.Total = roundCost(estimate.CPUCost + estimate.MemoryCost + estimate.StorageCost)
+	return estimate, nil
+}
+
+// quotaValue returns the first of the keys which is set in the hard limits, or 0
+func quotaValue(hard *gabs.Container, keys ...string) (float64, error) {
+	for _, key := range keys {
+		if value := hard.S(key).Data(); value != nil {
+			return parseQuantity(fmt.Sprintf("%v", value))
+		}
+	}
+	return 0, nil
+}
+
+var quantitySuffixes = []struct {
+	suffix     string
+	multiplier float64
+}{
+	// Binary suffixes first, so that Mi is not parsed as M
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40}, {"Pi", 1 << 50},
+	{"m", 1e-3}, {"k", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12}, {"P", 1e15},
+}
+
+// parseQuantity parses a Kubernetes quantity like 500m, 4Gi or 100G
+func parseQuantity(quantity string) (float64, error) {
+	quantity = strings.TrimSpace(quantity)
+	multiplier := 1.0
+	for _, s := range quantitySuffixes {
+		if strings.HasSuffix(quantity, s.suffix) {
+			quantity = strings.TrimSuffix(quantity, s.suffix)
+			multiplier = s.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(quantity, 64)
+	if err != nil {
+		return 0, common.NewApiError(common.ErrorCodeBackendError, fmt.Sprintf("Invalid quantity in the quota: %v", quantity))
+	}
+	return value * multiplier, nil
+}
+
+func roundCost(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,57 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestParseQuantity(t *testing.T) {
+	var testsets = []struct {
+		quantity string
+		value    float64
+	}{
+		{"2", 2},
+		{"500m", 0.5},
+		{"4Gi", 4 * 1024 * 1024 * 1024},
+		{"512Mi", 512 * 1024 * 1024},
+		{"1G", 1e9},
+		{"1.5", 1.5},
+	}
+	for _, set := range testsets {
+		if value, err := parseQuantity(set.quantity); err != nil || value != set.value {
+			t.Errorf("ERROR: %v should be %v, got %v (error: %v)", set.quantity, set.value, value, err)
+		}
+	}
+	if _, err := parseQuantity("lots"); err == nil {
+		t.Error("ERROR: invalid quantities should return an error")
+	}
+}
+
+func TestEstimateCost(t *testing.T) {
+	costModel := CostModel{Currency: "CHF", CPUCoreHour: 0.01, MemoryGiBHour: 0.005, StorageGiBMonth: 0.1}
+	quota, _ := gabs.ParseJSON([]byte(`{"spec": {"hard": {"cpu": "2", "memory": "4Gi", "requests.storage": "100Gi"}}}`))
+
+	estimate, err := estimateCost(quota, costModel)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	expected := CostEstimate{
+		Currency:    "CHF",
+		CPUCores:    2,
+		MemoryGiB:   4,
+		StorageGiB:  100,
+		CPUCost:     14.6,
+		MemoryCost:  14.6,
+		StorageCost: 10,
+		Total:       39.2,
+	}
+	if estimate != expected {
+		t.Errorf("ERROR: estimate should be %+v, but is %+v", expected, estimate)
+	}
+
+	estimate, err = estimateCost(nil, costModel)
+	if err != nil || estimate.Note == "" || estimate.Total != 0 {
+		t.Errorf("ERROR: projects without quota should return a note, got %+v (error: %v)", estimate, err)
+	}
+}
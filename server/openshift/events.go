@@ -0,0 +1,86 @@
+package openshift
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultProjectEventsLimit = 50
+
+type ProjectEvent struct {
+	Type           string         `json:"type"`
+	Reason         string         `json:"reason"`
+	Message        string         `json:"message"`
+	InvolvedObject InvolvedObject `json:"involvedObject"`
+	LastTimestamp  string         `json:"lastTimestamp"`
+}
+
+type InvolvedObject struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+func getProjectEventsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	params := c.Request.URL.Query()
+	clusterId := params.Get("clusterid")
+	project := params.Get("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project+"/events", nil)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	json, err := parseJSONResponse(resp)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	limit := config.Config().GetInt("project_events_limit")
+	if limit <= 0 {
+		limit = defaultProjectEventsLimit
+	}
+	c.JSON(http.StatusOK, normalizeEvents(json, limit))
+}
+
+// normalizeEvents returns the newest events first, at most limit events
+func normalizeEvents(json *gabs.Container, limit int) []ProjectEvent {
+	events := []ProjectEvent{}
+	for _, e := range json.S("items").Children() {
+		event := ProjectEvent{}
+		event.Type, _ = e.S("type").Data().(string)
+		event.Reason, _ = e.S("reason").Data().(string)
+		event.Message, _ = e.S("message").Data().(string)
+		event.InvolvedObject.Kind, _ = e.Path("involvedObject.kind").Data().(string)
+		event.InvolvedObject.Name, _ = e.Path("involvedObject.name").Data().(string)
+		event.LastTimestamp, _ = e.S("lastTimestamp").Data().(string)
+		// newer events only have eventTime
+		if event.LastTimestamp == "" {
+			event.LastTimestamp, _ = e.S("eventTime").Data().(string)
+		}
+		events = append(events, event)
+	}
+
+	// RFC3339 timestamps in UTC can be sorted as strings
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].LastTimestamp > events[j].LastTimestamp
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events
+}
@@ -0,0 +1,33 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestNormalizeEvents(t *testing.T) {
+	json, err := gabs.ParseJSON([]byte(`{
+		"items": [
+			{"type": "Normal", "reason": "Pulled", "message": "pulled", "involvedObject": {"kind": "Pod", "name": "a"}, "lastTimestamp": "2020-08-01T10:00:00Z"},
+			{"type": "Warning", "reason": "FailedScheduling", "message": "no nodes", "involvedObject": {"kind": "Pod", "name": "b"}, "lastTimestamp": "2020-08-01T12:00:00Z"},
+			{"type": "Normal", "reason": "Scheduled", "involvedObject": {"kind": "Pod", "name": "c"}, "eventTime": "2020-08-01T11:00:00.000000Z"}
+		]
+	}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+
+	events := normalizeEvents(json, 2)
+	if len(events) != 2 {
+		t.Fatalf("ERROR: expected 2 events, got %v", len(events))
+	}
+	if events[0].Reason != "FailedScheduling" || events[0].InvolvedObject.Name != "b" || events[1].Reason != "Scheduled" {
+		t.Errorf("ERROR: events should be sorted newest first: %+v", events)
+	}
+
+	empty, _ := gabs.ParseJSON([]byte(`{"items": []}`))
+	if events := normalizeEvents(empty, 2); events == nil || len(events) != 0 {
+		t.Errorf("ERROR: expected an empty list, got %v", events)
+	}
+}
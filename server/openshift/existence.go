@@ -0,0 +1,121 @@
+package openshift
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultProjectExistsTimeout = 10 * time.Second
+
+// ProjectExistence is the existence of a project name on all clusters.
+// Clusters which couldn't be checked (error or timeout) are only in Errors, never in Exists.
+type ProjectExistence struct {
+	Project string            `json:"project"`
+	Exists  map[string]bool   `json:"exists"`
+	Errors  map[string]string `json:"errors"`
+}
+
+// getProjectExistenceHandler checks on which clusters a project with the name exists, e.g. before a migration
+func getProjectExistenceHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	project := c.Query("project")
+
+	if project == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if len(project) > 63 || !projectNameRegex.MatchString(project) {
+		common.RespondError(c, http.StatusBadRequest, common.NewValidationError(common.ErrorCodeInvalidRequest, "Invalid project name"))
+		return
+	}
+
+	log.Printf("%v checks the existence of project %v on all clusters", username, project)
+	c.JSON(http.StatusOK, checkProjectExistence(getOpenshiftClusters(""), project, getProjectExistsTimeout()))
+}
+
+// getProjectExistsTimeout returns project_exists_timeout (default 10s)
+func getProjectExistsTimeout() time.Duration {
+	timeout := config.Config().GetDuration("project_exists_timeout")
+	if timeout <= 0 {
+		return defaultProjectExistsTimeout
+	}
+	return timeout
+}
+
+// checkProjectExistence checks all clusters at the same time.
+// A cluster which doesn't answer within timeout is reported as error, so it doesn't delay the others.
+// The clusters are resolved before the requests start, so the goroutines don't read the config.
+func checkProjectExistence(clusters []OpenshiftCluster, project string, timeout time.Duration) ProjectExistence {
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	result := ProjectExistence{
+		Project: project,
+		Exists:  map[string]bool{},
+		Errors:  map[string]string{},
+	}
+	for _, cluster := range clusters {
+		client, err := newOseClient(cluster.ID)
+		if err != nil {
+			log.Printf("Error checking the existence of project %v on cluster %v: %v", project, cluster.ID, err)
+			result.Errors[cluster.ID] = err.Error()
+			continue
+		}
+		wg.Add(1)
+		go func(client *oseClient) {
+			defer wg.Done()
+			exists, err := projectExistsWithTimeout(client, project, timeout)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				log.Printf("Error checking the existence of project %v on cluster %v: %v", project, client.clusterId, err)
+				result.Errors[client.clusterId] = err.Error()
+				return
+			}
+			result.Exists[client.clusterId] = exists
+		}(client)
+	}
+	wg.Wait()
+	return result
+}
+
+// projectExistsWithTimeout cancels the request after timeout
+func projectExistsWithTimeout(client *oseClient, project string, timeout time.Duration) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	exists, err := projectExists(ctx, client, project)
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return false, fmt.Errorf("timeout after %v", timeout)
+	}
+	return exists, err
+}
+
+// projectExists returns false only if OpenShift reports the namespace as not found.
+// Unlike getProjectObject, a forbidden or failed request is an error, because the project might exist.
+func projectExists(ctx context.Context, client *oseClient, project string) (bool, error) {
+	resp, err := client.request(ctx, "GET", "api/v1/namespaces/"+project, nil)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return true, nil
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return false, newUpstreamError("GET", resp.StatusCode, body)
+	}
+}
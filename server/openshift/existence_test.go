@@ -0,0 +1,63 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestCheckProjectExistence(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/namespaces/existing"):
+			w.Write([]byte(`{"metadata": {"name": "existing"}}`))
+		case strings.HasSuffix(r.URL.Path, "/namespaces/forbidden"):
+			w.WriteHeader(http.StatusForbidden)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}
+	fast := httptest.NewServer(http.HandlerFunc(handler))
+	defer fast.Close()
+	block := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		handler(w, r)
+	}))
+	defer slow.Close()
+	defer close(block)
+
+	setTestCluster(fast.URL)
+	config.Config().Set("openshift", []map[string]interface{}{
+		{"id": "fast", "url": fast.URL, "token": "token"},
+		{"id": "slow", "url": slow.URL, "token": "token"},
+	})
+	clusters := getOpenshiftClusters("")
+
+	start := time.Now()
+	result := checkProjectExistence(clusters, "existing", 200*time.Millisecond)
+	if exists, ok := result.Exists["fast"]; !ok || !exists {
+		t.Errorf("ERROR: the project should exist on the fast cluster, got: %+v", result)
+	}
+	// the request to the slow cluster is canceled instead of left running
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("ERROR: the slow cluster should be canceled after the timeout, took %v", elapsed)
+	}
+	if _, ok := result.Exists["slow"]; ok || !strings.Contains(result.Errors["slow"], "timeout") {
+		t.Errorf("ERROR: the slow cluster should time out, got: %+v", result)
+	}
+
+	result = checkProjectExistence(clusters[:1], "missing", 200*time.Millisecond)
+	if exists, ok := result.Exists["fast"]; !ok || exists || len(result.Errors) != 0 {
+		t.Errorf("ERROR: the project shouldn't exist, got: %+v", result)
+	}
+
+	result = checkProjectExistence(clusters[:1], "forbidden", 200*time.Millisecond)
+	if _, ok := result.Exists["fast"]; ok || result.Errors["fast"] == "" {
+		t.Errorf("ERROR: a forbidden request should be an error, not a missing project, got: %+v", result)
+	}
+}
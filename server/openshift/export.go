@@ -0,0 +1,124 @@
+package openshift
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProjectExport is a snapshot of the configuration of a project
+type ProjectExport struct {
+	Cluster      string                `yaml:"cluster"`
+	Project      string                `yaml:"project"`
+	ExportedAt   string                `yaml:"exportedAt"`
+	ExportedBy   string                `yaml:"exportedBy"`
+	Annotations  map[string]string     `yaml:"annotations,omitempty"`
+	Labels       map[string]string     `yaml:"labels,omitempty"`
+	Quota        map[string]string     `yaml:"quota,omitempty"`
+	RoleBindings []ExportedRoleBinding `yaml:"rolebindings"`
+}
+
+type ExportedRoleBinding struct {
+	Name     string            `yaml:"name"`
+	Role     string            `yaml:"role"`
+	Subjects []ExportedSubject `yaml:"subjects"`
+}
+
+type ExportedSubject struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+}
+
+// exportProjectHandler returns the annotations, labels, quota and rolebindings of a project as YAML
+func exportProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	clusterId := c.Query("clusterid")
+	project := c.Query("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer resp.Body.Close()
+	namespace, err := parseJSONResponse(resp)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The quota is optional, e.g. for imported namespaces
+	quota, err := getQuotas(clusterId, project)
+	if err != nil {
+		log.Printf("WARNING: Quota of project %v on cluster %v is not exported: %v", project, clusterId, err)
+		quota = nil
+	}
+
+	roleBindings, err := getRoleBindings(clusterId, project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.Printf("%v exports the configuration of project %v on cluster %v", username, project, clusterId)
+	export := newProjectExport(clusterId, project, namespace, quota, roleBindings)
+	export.ExportedAt = time.Now().UTC().Format(time.RFC3339)
+	export.ExportedBy = username
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%v-%v.yaml"`, clusterId, project))
+	c.YAML(http.StatusOK, export)
+}
+
+func newProjectExport(clusterId, project string, namespace, quota, roleBindings *gabs.Container) ProjectExport {
+	export := ProjectExport{
+		Cluster:      clusterId,
+		Project:      project,
+		Annotations:  stringMap(namespace.Path("metadata.annotations")),
+		Labels:       stringMap(namespace.Path("metadata.labels")),
+		RoleBindings: []ExportedRoleBinding{},
+	}
+	if quota != nil {
+		export.Quota = stringMap(quota.Path("spec.hard"))
+	}
+
+	for _, roleBinding := range roleBindings.S("items").Children() {
+		name, _ := roleBinding.Path("metadata.name").Data().(string)
+		role, _ := roleBinding.Path("roleRef.name").Data().(string)
+		exported := ExportedRoleBinding{Name: name, Role: role, Subjects: []ExportedSubject{}}
+		for _, subject := range roleBinding.S("subjects").Children() {
+			kind, _ := subject.S("kind").Data().(string)
+			subjectName, _ := subject.S("name").Data().(string)
+			subjectNamespace, _ := subject.S("namespace").Data().(string)
+			exported.Subjects = append(exported.Subjects, ExportedSubject{Kind: kind, Name: subjectName, Namespace: subjectNamespace})
+		}
+		export.RoleBindings = append(export.RoleBindings, exported)
+	}
+	sort.Slice(export.RoleBindings, func(i, j int) bool {
+		return export.RoleBindings[i].Name < export.RoleBindings[j].Name
+	})
+	return export
+}
+
+// stringMap returns the values of a JSON object as strings. Missing objects return nil.
+func stringMap(json *gabs.Container) map[string]string {
+	children := json.ChildrenMap()
+	if len(children) == 0 {
+		return nil
+	}
+	m := map[string]string{}
+	for key, value := range children {
+		m[key] = fmt.Sprintf("%v", value.Data())
+	}
+	return m
+}
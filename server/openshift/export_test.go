@@ -0,0 +1,45 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestNewProjectExport(t *testing.T) {
+	namespace, _ := gabs.ParseJSON([]byte(`{"metadata": {"name": "project", "annotations": {"openshift.io/kontierung-element": "12345", "openshift.io/requester": "u123456"}}}`))
+	quota, _ := gabs.ParseJSON([]byte(`{"spec": {"hard": {"cpu": "2", "memory": "4Gi"}}}`))
+	roleBindings, _ := gabs.ParseJSON([]byte(`{"items": [
+		{"metadata": {"name": "view"}, "roleRef": {"name": "view"}},
+		{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [
+			{"kind": "User", "name": "u123456"},
+			{"kind": "ServiceAccount", "name": "jenkins", "namespace": "ci"}
+		]}
+	]}`))
+
+	export := newProjectExport("test", "project", namespace, quota, roleBindings)
+	if export.Labels != nil {
+		t.Errorf("ERROR: missing labels should be omitted, got: %v", export.Labels)
+	}
+	if export.Quota["memory"] != "4Gi" || export.Annotations["openshift.io/kontierung-element"] != "12345" {
+		t.Errorf("ERROR: unexpected export: %+v", export)
+	}
+	if len(export.RoleBindings) != 2 || export.RoleBindings[0].Name != "admin" || len(export.RoleBindings[0].Subjects) != 2 ||
+		export.RoleBindings[0].Subjects[1] != (ExportedSubject{Kind: "ServiceAccount", Name: "jenkins", Namespace: "ci"}) {
+		t.Errorf("ERROR: unexpected rolebindings: %+v", export.RoleBindings)
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.YAML(http.StatusOK, newProjectExport("test", "project", namespace, nil, gabs.New()))
+	body := w.Body.String()
+	if strings.Contains(body, "quota") || strings.Contains(body, "labels") || !strings.Contains(body, "rolebindings: []") ||
+		!strings.Contains(body, "openshift.io/requester: u123456") {
+		t.Errorf("ERROR: missing components should be omitted, got:\n%v", body)
+	}
+}
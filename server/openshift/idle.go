@@ -0,0 +1,283 @@
+package openshift
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// RFC3339 timestamp of the last use, maintained outside of the SSP
+	lastUsedAnnotation             = "openshift.io/last-used"
+	defaultIdleProjectsConcurrency = 4
+)
+
+// Sources of the last activity of a project
+const (
+	activityEvent      = "event"
+	activityAnnotation = "annotation"
+	activityCreated    = "created"
+)
+
+// IdleProject is a project with the time since its last activity
+type IdleProject struct {
+	Project      string `json:"project"`
+	LastActivity string `json:"lastActivity"`
+	// event, annotation (openshift.io/last-used) or created
+	Source          string `json:"source"`
+	InactiveSeconds int64  `json:"inactiveSeconds"`
+	InactiveDays    int    `json:"inactiveDays"`
+	Archived        bool   `json:"archived"`
+}
+
+// ArchiveIdleProjectsResult are the projects which were archived and the reasons for the others
+type ArchiveIdleProjectsResult struct {
+	Archived []string          `json:"archived"`
+	Skipped  map[string]string `json:"skipped"`
+}
+
+// getIdleProjectsHandler returns the projects of a cluster, the longest inactive first.
+// With mindays, only the projects which are inactive for at least mindays are returned.
+func getIdleProjectsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	clusterId := c.Query("clusterid")
+	minDays := 0
+	if value := c.Query("mindays"); value != "" {
+		days, err := strconv.Atoi(value)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "mindays must be a number of days", Code: common.ErrorCodeInvalidRequest})
+			return
+		}
+		minDays = days
+	}
+	if clusterId == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	log.Printf("%v lists the idle projects on cluster %v", username, clusterId)
+	// Projects whose events can't be read are left out, their last activity is unknown
+	idleProjects, _, err := getIdleProjects(clusterId, time.Now())
+	if err != nil {
+		common.RespondError(c, http.StatusBadGateway, err)
+		return
+	}
+	filtered := []IdleProject{}
+	for _, p := range idleProjects {
+		if p.InactiveDays >= minDays {
+			filtered = append(filtered, p)
+		}
+	}
+	c.JSON(http.StatusOK, filtered)
+}
+
+// archiveIdleProjectsHandler archives the given projects if they are still inactive for at least minDays.
+// The archive cleanup deletes them after the grace period.
+func archiveIdleProjectsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ArchiveIdleProjectsCommand
+	if c.BindJSON(&data) != nil || data.ClusterId == "" || len(data.Projects) == 0 {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if data.MinDays <= 0 {
+		c.JSON(http.StatusUnprocessableEntity, common.ApiResponse{Message: "minDays must be at least 1", Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	idleProjects, unknown, err := getIdleProjects(data.ClusterId, time.Now())
+	if err != nil {
+		common.RespondError(c, http.StatusBadGateway, err)
+		return
+	}
+	c.JSON(http.StatusOK, archiveIdleProjects(data.ClusterId, username, data.Projects, data.MinDays, idleProjects, unknown))
+}
+
+// archiveIdleProjects archives the projects which are inactive for at least minDays.
+// Projects in unknown are never archived, because they could have been active recently.
+func archiveIdleProjects(clusterId, username string, projects []string, minDays int, idleProjects []IdleProject, unknown map[string]string) ArchiveIdleProjectsResult {
+	byName := map[string]IdleProject{}
+	for _, p := range idleProjects {
+		byName[p.Project] = p
+	}
+	result := ArchiveIdleProjectsResult{Archived: []string{}, Skipped: map[string]string{}}
+	for _, project := range common.RemoveDuplicates(projects) {
+		idle, ok := byName[project]
+		reason, isUnknown := unknown[project]
+		switch {
+		case isUnknown:
+			result.Skipped[project] = "The last activity of the project is unknown: " + reason
+		case !ok:
+			result.Skipped[project] = "The project doesn't exist"
+		case idle.Archived:
+			result.Skipped[project] = "The project is already archived"
+		case idle.InactiveDays < minDays:
+			result.Skipped[project] = "The project was active " + strconv.Itoa(idle.InactiveDays) + " days ago"
+		default:
+			if _, err := archiveProject(clusterId, project, username); err != nil {
+				log.Printf("Error archiving idle project %v on cluster %v: %v", project, clusterId, err)
+				result.Skipped[project] = err.Error()
+				continue
+			}
+			result.Archived = append(result.Archived, project)
+		}
+	}
+	sort.Strings(result.Archived)
+	return result
+}
+
+// getIdleProjects returns the projects of the cluster with their last activity, the longest inactive first.
+// The last activity is the newest of the events, the openshift.io/last-used annotation and the creation.
+// The events are read with at most idle_projects_concurrency requests at the same time.
+// Projects whose events can't be read are not returned, but with the error in the map.
+func getIdleProjects(clusterId string, now time.Time) ([]IdleProject, map[string]string, error) {
+	projects, err := getProjects(clusterId, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var names []string
+	for _, project := range projects.Children() {
+		if project.Path("status.phase").Data() == "Terminating" {
+			continue
+		}
+		if name, ok := project.Path("metadata.name").Data().(string); ok {
+			names = append(names, name)
+		}
+	}
+	concurrency := config.Config().GetInt("idle_projects_concurrency")
+	if concurrency <= 0 {
+		concurrency = defaultIdleProjectsConcurrency
+	}
+	// The cluster is resolved once, so the workers don't read the config concurrently
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		return nil, nil, err
+	}
+	lastEvents, unknown := getLastEventTimes(client, names, concurrency)
+
+	idleProjects := []IdleProject{}
+	for _, project := range projects.Children() {
+		name, _ := project.Path("metadata.name").Data().(string)
+		lastEvent, ok := lastEvents[name]
+		if !ok {
+			continue
+		}
+		idleProjects = append(idleProjects, newIdleProject(project, lastEvent, now))
+	}
+	sort.SliceStable(idleProjects, func(i, j int) bool {
+		if idleProjects[i].InactiveSeconds != idleProjects[j].InactiveSeconds {
+			return idleProjects[i].InactiveSeconds > idleProjects[j].InactiveSeconds
+		}
+		return idleProjects[i].Project < idleProjects[j].Project
+	})
+	return idleProjects, unknown, nil
+}
+
+func newIdleProject(project *gabs.Container, lastEvent time.Time, now time.Time) IdleProject {
+	name, _ := project.Path("metadata.name").Data().(string)
+	annotations := project.Path("metadata.annotations")
+
+	lastActivity, source := time.Time{}, ""
+	candidates := []struct {
+		source string
+		value  interface{}
+	}{
+		{activityCreated, project.Path("metadata.creationTimestamp").Data()},
+		{activityAnnotation, annotations.S(lastUsedAnnotation).Data()},
+	}
+	for _, candidate := range candidates {
+		s, _ := candidate.value.(string)
+		if t, err := time.Parse(time.RFC3339, s); err == nil && t.After(lastActivity) {
+			lastActivity, source = t, candidate.source
+		}
+	}
+	if lastEvent.After(lastActivity) {
+		lastActivity, source = lastEvent, activityEvent
+	}
+
+	idleProject := IdleProject{
+		Project:  name,
+		Source:   source,
+		Archived: annotations.Exists(archivedAtAnnotation),
+	}
+	if !lastActivity.IsZero() {
+		inactive := now.Sub(lastActivity)
+		idleProject.LastActivity = lastActivity.UTC().Format(time.RFC3339)
+		idleProject.InactiveSeconds = int64(inactive.Seconds())
+		idleProject.InactiveDays = int(inactive.Hours() / 24)
+	}
+	return idleProject
+}
+
+// getLastEventTimes returns the time of the newest event of each project. It is zero if the project
+// has no events, OpenShift only keeps the events for a few hours. The projects whose events
+// can't be read are returned with the error in the second map.
+func getLastEventTimes(client *oseClient, projects []string, concurrency int) (map[string]time.Time, map[string]string) {
+	var mu sync.Mutex
+	lastEvents := make(map[string]time.Time, len(projects))
+	failed := map[string]string{}
+	common.Parallel(len(projects), concurrency, nil, func(i int) {
+		project := projects[i]
+		lastEvent, err := getLastEventTime(client, project)
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			log.Printf("Error getting the events of project %v on cluster %v: %v", project, client.clusterId, err)
+			failed[project] = err.Error()
+			return
+		}
+		lastEvents[project] = lastEvent
+	})
+	return lastEvents, failed
+}
+
+func getLastEventTime(client *oseClient, project string) (time.Time, error) {
+	resp, err := client.request(context.Background(), "GET", "api/v1/namespaces/"+project+"/events", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return time.Time{}, newUpstreamError("GET", resp.StatusCode, body)
+	}
+	json, err := parseJSONResponse(resp)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var lastEvent time.Time
+	for _, event := range json.S("items").Children() {
+		timestamp, _ := event.S("lastTimestamp").Data().(string)
+		// newer events only have eventTime
+		if timestamp == "" {
+			timestamp, _ = event.S("eventTime").Data().(string)
+		}
+		if t, err := time.Parse(time.RFC3339, timestamp); err == nil && t.After(lastEvent) {
+			lastEvent = t
+		}
+	}
+	return lastEvent, nil
+}
@@ -0,0 +1,104 @@
+package openshift
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestGetIdleProjects(t *testing.T) {
+	now := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	var (
+		mu       sync.Mutex
+		inFlight int
+		maxSeen  int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/apis/project.openshift.io/v1/projects" {
+			w.Write([]byte(`{"items": [
+				{"metadata": {"name": "active", "creationTimestamp": "2020-01-01T00:00:00Z"}},
+				{"metadata": {"name": "used", "creationTimestamp": "2020-01-01T00:00:00Z", "annotations": {"openshift.io/last-used": "2020-08-20T10:00:00Z"}}},
+				{"metadata": {"name": "old", "creationTimestamp": "2020-06-03T10:00:00Z"}},
+				{"metadata": {"name": "new", "creationTimestamp": "2020-06-03T10:00:00Z", "annotations": {"openshift.io/archived-at": "2020-08-31T10:00:00Z"}}},
+				{"metadata": {"name": "deleted", "creationTimestamp": "2020-01-01T00:00:00Z"}, "status": {"phase": "Terminating"}}
+			]}`))
+			return
+		}
+		mu.Lock()
+		inFlight++
+		if inFlight > maxSeen {
+			maxSeen = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+
+		switch r.URL.Path {
+		case "/api/v1/namespaces/active/events":
+			w.Write([]byte(`{"items": [{"lastTimestamp": "2020-08-30T10:00:00Z"}, {"eventTime": "2020-08-31T10:00:00.000000Z"}]}`))
+		case "/api/v1/namespaces/used/events":
+			w.Write([]byte(`{"items": [{"lastTimestamp": "2020-08-01T10:00:00Z"}]}`))
+		case "/api/v1/namespaces/new/events":
+			w.Write([]byte(`{"items": []}`))
+		case "/api/v1/namespaces/old/events":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"kind": "Status"}`))
+		default:
+			t.Errorf("ERROR: unexpected request %v", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("idle_projects_concurrency", 2)
+
+	idleProjects, unknown, err := getIdleProjects("test", now)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	// the events of old can't be read, so its last activity is unknown
+	if _, ok := unknown["old"]; !ok || len(unknown) != 1 {
+		t.Errorf("ERROR: only old should be unknown, got: %v", unknown)
+	}
+	expected := []IdleProject{
+		{Project: "new", LastActivity: "2020-06-03T10:00:00Z", Source: activityCreated, InactiveSeconds: 90 * 24 * 3600, InactiveDays: 90, Archived: true},
+		{Project: "used", LastActivity: "2020-08-20T10:00:00Z", Source: activityAnnotation, InactiveSeconds: 12 * 24 * 3600, InactiveDays: 12},
+		{Project: "active", LastActivity: "2020-08-31T10:00:00Z", Source: activityEvent, InactiveSeconds: 24 * 3600, InactiveDays: 1},
+	}
+	if fmt.Sprint(idleProjects) != fmt.Sprint(expected) {
+		t.Errorf("ERROR: expected %+v, got %+v", expected, idleProjects)
+	}
+	if maxSeen > 2 {
+		t.Errorf("ERROR: at most 2 events requests should run at the same time, got %v", maxSeen)
+	}
+}
+
+func TestArchiveIdleProjectsSkipsActiveProjects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("ERROR: no project should be archived, got %v %v", r.Method, r.URL.Path)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	idleProjects := []IdleProject{
+		{Project: "active", InactiveDays: 1},
+		{Project: "archived", InactiveDays: 90, Archived: true},
+	}
+	unknown := map[string]string{"forbidden": "403"}
+	result := archiveIdleProjects("test", "admin", []string{"active", "archived", "missing", "forbidden"}, 30, idleProjects, unknown)
+	if len(result.Archived) != 0 || len(result.Skipped) != 4 || !strings.Contains(result.Skipped["active"], "1 days") {
+		t.Errorf("ERROR: unexpected result: %+v", result)
+	}
+	if !strings.Contains(result.Skipped["forbidden"], "unknown") {
+		t.Errorf("ERROR: projects without events should not be archived, got: %v", result.Skipped["forbidden"])
+	}
+}
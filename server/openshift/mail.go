@@ -0,0 +1,210 @@
+package openshift
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/gomail.v2"
+)
+
+// Number of mails kept by the memoryMailer
+const maxRecordedMails = 100
+
+type mailer interface {
+	Send(m *gomail.Message) error
+}
+
+const (
+	defaultMailPort = 25
+	// Port of SMTP with implicit TLS (SMTPS)
+	implicitTLSMailPort = 465
+)
+
+// smtpMailer sends the mails to the MAIL_SERVER
+type smtpMailer struct {
+	host string
+	port int
+	// implicit TLS instead of STARTTLS
+	implicitTLS bool
+}
+
+// newSMTPMailer reads MAIL_PORT (default 25) and MAIL_TLS. MAIL_TLS is starttls or implicit,
+// without it implicit TLS is used on port 465 and STARTTLS on all other ports.
+func newSMTPMailer(host string) (*smtpMailer, error) {
+	s := &smtpMailer{host: host, port: defaultMailPort}
+	if port := os.Getenv("MAIL_PORT"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil || p <= 0 || p > 65535 {
+			return nil, fmt.Errorf("Invalid MAIL_PORT: %v", port)
+		}
+		s.port = p
+	}
+	switch strings.ToLower(os.Getenv("MAIL_TLS")) {
+	case "":
+		s.implicitTLS = s.port == implicitTLSMailPort
+	case "starttls":
+		s.implicitTLS = false
+	case "implicit":
+		s.implicitTLS = true
+	default:
+		return nil, fmt.Errorf("Invalid MAIL_TLS: %v. Allowed values: starttls, implicit", os.Getenv("MAIL_TLS"))
+	}
+	return s, nil
+}
+
+// dialer returns the dialer of gomail. Without implicit TLS, gomail uses STARTTLS if the server offers it.
+func (s *smtpMailer) dialer() *gomail.Dialer {
+	return &gomail.Dialer{
+		Host:      s.host,
+		Port:      s.port,
+		SSL:       s.implicitTLS,
+		TLSConfig: &tls.Config{InsecureSkipVerify: true, ServerName: s.host},
+	}
+}
+
+func (s *smtpMailer) Send(m *gomail.Message) error {
+	return s.dialer().DialAndSend(m)
+}
+
+// memoryMailer records the mails instead of sending them.
+// It is used if mail_disabled is set, e.g. in test environments
+type memoryMailer struct {
+	mu       sync.Mutex
+	messages []*gomail.Message
+}
+
+func (s *memoryMailer) Send(m *gomail.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, m)
+	if len(s.messages) > maxRecordedMails {
+		s.messages = s.messages[len(s.messages)-maxRecordedMails:]
+	}
+	return nil
+}
+
+// Messages returns the recorded mails
+func (s *memoryMailer) Messages() []*gomail.Message {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*gomail.Message{}, s.messages...)
+}
+
+var recordedMails = &memoryMailer{}
+
+var (
+	mailSentTotal int64
+	// Failed mails per error class
+	mailFailedMu    sync.Mutex
+	mailFailedTotal = map[string]int64{}
+)
+
+// meteredMailer counts and logs the outcome of every mail
+type meteredMailer struct {
+	next mailer
+}
+
+func (s meteredMailer) Send(m *gomail.Message) error {
+	fields := log.Fields{
+		"to":      strings.Join(append(m.GetHeader("To"), m.GetHeader("Cc")...), ","),
+		"subject": strings.Join(m.GetHeader("Subject"), " "),
+	}
+	err := s.next.Send(m)
+	if err != nil {
+		class := mailErrorClass(err)
+		mailFailedMu.Lock()
+		mailFailedTotal[class]++
+		mailFailedMu.Unlock()
+		fields["class"] = class
+		log.WithFields(fields).Errorf("Error sending e-mail: %v", err)
+		return err
+	}
+	atomic.AddInt64(&mailSentTotal, 1)
+	log.WithFields(fields).Info("E-mail sent")
+	return nil
+}
+
+// mailErrorClass returns a short class of the error for the metrics and logs,
+// e.g. smtp_4xx for temporary and smtp_5xx for permanent errors of the relay
+func mailErrorClass(err error) string {
+	switch e := err.(type) {
+	case *textproto.Error:
+		return fmt.Sprintf("smtp_%vxx", e.Code/100)
+	case net.Error:
+		if e.Timeout() {
+			return "timeout"
+		}
+		return "connection"
+	}
+	return "other"
+}
+
+// mailMetrics returns the counters of the sent and failed mails in the Prometheus text format
+func mailMetrics() string {
+	mailFailedMu.Lock()
+	var classes []string
+	failed := map[string]int64{}
+	for class, count := range mailFailedTotal {
+		classes = append(classes, class)
+		failed[class] = count
+	}
+	mailFailedMu.Unlock()
+	sort.Strings(classes)
+
+	metrics := "# HELP ssp_mail_sent_total Number of e-mails sent.\n" +
+		"# TYPE ssp_mail_sent_total counter\n" +
+		fmt.Sprintf("ssp_mail_sent_total %v\n", atomic.LoadInt64(&mailSentTotal)) +
+		"# HELP ssp_mail_failed_total Number of e-mails which could not be sent, by error class.\n" +
+		"# TYPE ssp_mail_failed_total counter\n"
+	for _, class := range classes {
+		metrics += fmt.Sprintf("ssp_mail_failed_total{class=%q} %v\n", class, failed[class])
+	}
+	return metrics
+}
+
+func getMailer() (mailer, error) {
+	if config.Config().GetBool("mail_disabled") {
+		return meteredMailer{next: recordedMails}, nil
+	}
+
+	mailServer, ok := os.LookupEnv("MAIL_SERVER")
+	if !ok {
+		return nil, errors.New("Error looking up MAIL_SERVER from environment.")
+	}
+	s, err := newSMTPMailer(mailServer)
+	if err != nil {
+		return nil, err
+	}
+	return meteredMailer{next: s}, nil
+}
+
+// parseMailAddresses parses a comma separated list of addresses.
+// Invalid addresses are skipped with a warning.
+func parseMailAddresses(list string) []string {
+	var addresses []string
+	for _, a := range strings.Split(list, ",") {
+		a = strings.TrimSpace(a)
+		if a == "" {
+			continue
+		}
+		address, err := mail.ParseAddress(a)
+		if err != nil {
+			log.Warnf("Skipping invalid e-mail address '%v': %v", a, err)
+			continue
+		}
+		addresses = append(addresses, address.Address)
+	}
+	return addresses
+}
@@ -0,0 +1,148 @@
+package openshift
+
+import (
+	"errors"
+	"net"
+	"net/textproto"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"gopkg.in/gomail.v2"
+)
+
+func TestSendNewProjectMailDisabled(t *testing.T) {
+	config.Init("bla")
+	config.Config().Set("mail_disabled", true)
+	os.Setenv("MAIL_ADMIN_SENDER", "ssp@example.com")
+	os.Setenv("MAIL_NEW_PROJECT_RECIPIENT", "cloud@example.com")
+	defer os.Unsetenv("MAIL_ADMIN_SENDER")
+	defer os.Unsetenv("MAIL_NEW_PROJECT_RECIPIENT")
+
+	before := len(recordedMails.Messages())
+	if err := sendNewProjectMail("cluster", "project", "u123456", "1234"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	messages := recordedMails.Messages()
+	if len(messages) != before+1 {
+		t.Fatalf("ERROR: expected one recorded mail, got %v", len(messages)-before)
+	}
+	if subject := messages[len(messages)-1].GetHeader("Subject"); len(subject) != 1 || subject[0] != "New Project 'project' on OpenShift" {
+		t.Errorf("ERROR: unexpected subject: %v", subject)
+	}
+}
+
+func TestParseMailAddresses(t *testing.T) {
+	addresses := parseMailAddresses("cloud@example.com, invalid, Ticket System <tickets@example.com>,,@example.com")
+	expected := []string{"cloud@example.com", "tickets@example.com"}
+	if !reflect.DeepEqual(addresses, expected) {
+		t.Errorf("ERROR: expected %v, got %v", expected, addresses)
+	}
+	if addresses := parseMailAddresses(""); len(addresses) != 0 {
+		t.Errorf("ERROR: expected no addresses, got %v", addresses)
+	}
+}
+
+type failingMailer struct {
+	err error
+}
+
+func (s failingMailer) Send(m *gomail.Message) error {
+	return s.err
+}
+
+func TestMeteredMailer(t *testing.T) {
+	m := gomail.NewMessage()
+	m.SetHeader("To", "cloud@example.com")
+	m.SetHeader("Subject", "test")
+
+	sent := mailSentTotal
+	if err := (meteredMailer{next: &memoryMailer{}}).Send(m); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if mailSentTotal != sent+1 {
+		t.Errorf("ERROR: sent mails should be counted, got %v", mailSentTotal-sent)
+	}
+
+	failed := mailFailedTotal["smtp_4xx"]
+	smtpErr := &textproto.Error{Code: 451, Msg: "try again later"}
+	if err := (meteredMailer{next: failingMailer{err: smtpErr}}).Send(m); err != smtpErr {
+		t.Errorf("ERROR: the error of the mailer should be returned, got: %v", err)
+	}
+	if mailFailedTotal["smtp_4xx"] != failed+1 || mailSentTotal != sent+1 {
+		t.Errorf("ERROR: failed mails should be counted by class, got: %v", mailFailedTotal)
+	}
+	if metrics := mailMetrics(); !strings.Contains(metrics, `ssp_mail_failed_total{class="smtp_4xx"}`) {
+		t.Errorf("ERROR: unexpected metrics: %v", metrics)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestMailErrorClass(t *testing.T) {
+	_, dialErr := net.DialTimeout("tcp", "127.0.0.1:1", time.Second)
+
+	var testsets = []struct {
+		err   error
+		class string
+	}{
+		{&textproto.Error{Code: 550, Msg: "mailbox unavailable"}, "smtp_5xx"},
+		{&textproto.Error{Code: 421, Msg: "service not available"}, "smtp_4xx"},
+		{timeoutError{}, "timeout"},
+		{dialErr, "connection"},
+		{errors.New("gomail: invalid address"), "other"},
+	}
+	for _, set := range testsets {
+		if class := mailErrorClass(set.err); class != set.class {
+			t.Errorf("ERROR: class of %v should be %v, got %v", set.err, set.class, class)
+		}
+	}
+}
+
+func TestNewSMTPMailer(t *testing.T) {
+	defer os.Unsetenv("MAIL_PORT")
+	defer os.Unsetenv("MAIL_TLS")
+
+	var testsets = []struct {
+		port        string
+		tls         string
+		valid       bool
+		expectedSSL bool
+		expected    int
+	}{
+		{"", "", true, false, 25},
+		{"587", "", true, false, 587},
+		{"465", "", true, true, 465},
+		{"465", "starttls", true, false, 465},
+		{"2465", "implicit", true, true, 2465},
+		{"smtp", "", false, false, 0},
+		{"70000", "", false, false, 0},
+		{"587", "ssl", false, false, 0},
+	}
+	for _, set := range testsets {
+		os.Setenv("MAIL_PORT", set.port)
+		os.Setenv("MAIL_TLS", set.tls)
+		s, err := newSMTPMailer("relay.example.com")
+		if !set.valid {
+			if err == nil {
+				t.Errorf("ERROR: MAIL_PORT '%v' and MAIL_TLS '%v' should be invalid", set.port, set.tls)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ERROR: unexpected error for MAIL_PORT '%v' and MAIL_TLS '%v': %v", set.port, set.tls, err)
+			continue
+		}
+		d := s.dialer()
+		if d.Host != "relay.example.com" || d.Port != set.expected || d.SSL != set.expectedSSL || d.TLSConfig.ServerName != "relay.example.com" {
+			t.Errorf("ERROR: unexpected dialer for MAIL_PORT '%v' and MAIL_TLS '%v': %+v", set.port, set.tls, d)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package openshift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/publisher"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// Labels and annotations of these domains (and their subdomains) are set by the platform
+var defaultReservedManifestDomains = []string{"openshift.io", "kubernetes.io", "k8s.io"}
+
+var allowedManifestFields = []string{"apiVersion", "kind", "metadata", "displayName", "description"}
+
+var allowedManifestMetadataFields = []string{"name", "labels", "annotations"}
+
+// newProjectFromManifestHandler creates a project from a ProjectRequest manifest of a power user.
+// The same validations as for /ose/project are done and the permissions and metadata are set afterwards.
+func newProjectFromManifestHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.NewProjectManifestCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validatePowerUser(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	project, err := validateProjectManifest(data.Manifest)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The manifest only replaces the name of the command, so the same validations as for /ose/project are done
+	command := common.NewProjectCommand{
+		OpenshiftBase:  common.OpenshiftBase{Project: project, ClusterId: data.ClusterId},
+		Billing:        data.Billing,
+		MegaId:         data.MegaId,
+		Operators:      data.Operators,
+		OwnerGroup:     data.OwnerGroup,
+		Classification: data.Classification,
+	}
+	requester, warnings, ok := validateNewProjectCommand(c, &command, username)
+	if !ok {
+		return
+	}
+	if err := validateNewProjectWebhook(command, username, requester); err != nil {
+		status := http.StatusForbidden
+		if apiErr, ok := err.(*common.ApiError); ok && apiErr.Code == common.ErrorCodeBackendError {
+			status = http.StatusBadGateway
+		}
+		common.RespondError(c, status, err)
+		return
+	}
+
+	cluster, err := getOpenshiftCluster(data.ClusterId)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	// The approval stores the normal project requests, so manifests can't be approved
+	if cluster.ApprovalRequired {
+		common.RespondError(c, http.StatusForbidden, common.NewApiError(common.ErrorCodeForbidden,
+			fmt.Sprintf("Projects on cluster %v must be approved. Please use /ose/project", data.ClusterId)))
+		return
+	}
+
+	p, err := newProjectRequestFromManifest(cluster, project, data.Manifest)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		common.RespondError(c, http.StatusBadRequest, errors.New(common.ConfigNotSetError))
+		return
+	}
+
+	log.Printf("%v creates project %v on cluster %v from a manifest", username, project, data.ClusterId)
+	if err := submitProjectRequest(data.ClusterId, project, p, requester, data.Billing, data.MegaId, data.OwnerGroup, data.Classification, command.Operators, projectNodeSelector(cluster, p, ""), false); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	publisher.Publish(publisher.ProjectCreated, data.ClusterId, project, username)
+
+	if err := sendNewProjectMail(data.ClusterId, project, requester, data.MegaId); err != nil {
+		log.Printf("Can't send e-mail about new project (%v) on cluster %v.", err, data.ClusterId)
+	}
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message:  common.T(c, common.MsgProjectCreated, project, data.ClusterId),
+		Warnings: warnings,
+	})
+}
+
+// validatePowerUser checks if the user is in the power_user_group.
+// Without power_user_group, nobody is a power user.
+func validatePowerUser(username string) error {
+	powerUserGroup := config.Config().GetString("power_user_group")
+	if powerUserGroup == "" {
+		return common.NewApiError(common.ErrorCodeForbidden, "Creating projects from manifests is not enabled")
+	}
+	isPowerUser, err := isInLdapGroup(username, powerUserGroup)
+	if err != nil {
+		return err
+	}
+	if !isPowerUser {
+		return common.NewApiError(common.ErrorCodeForbidden, "Only power users can create projects from manifests")
+	}
+	return nil
+}
+
+// validateProjectManifest checks that the manifest is a ProjectRequest without fields which are
+// reserved for the platform. It returns the name of the project.
+func validateProjectManifest(manifest map[string]interface{}) (string, error) {
+	if manifest == nil {
+		return "", common.NewApiError(common.ErrorCodeInvalidRequest, "The manifest must be provided")
+	}
+	if err := validateManifestFields("", manifest, allowedManifestFields); err != nil {
+		return "", err
+	}
+	if manifest["kind"] != "ProjectRequest" {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest, "The manifest must be of kind ProjectRequest")
+	}
+	if manifest["apiVersion"] != "project.openshift.io/v1" {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest, "The apiVersion of the manifest must be project.openshift.io/v1")
+	}
+	for _, field := range []string{"displayName", "description"} {
+		if _, ok := manifest[field]; !ok {
+			continue
+		}
+		if _, ok := manifest[field].(string); !ok {
+			return "", common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The field %v of the manifest must be a string", field))
+		}
+	}
+
+	metadata, ok := manifest["metadata"].(map[string]interface{})
+	if !ok {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest, "The manifest must have metadata")
+	}
+	if err := validateManifestFields("metadata.", metadata, allowedManifestMetadataFields); err != nil {
+		return "", err
+	}
+	project, _ := metadata["name"].(string)
+	if len(project) == 0 || len(project) > 63 || !projectNameRegex.MatchString(project) {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The project name '%v' is invalid. Allowed are at most 63 lowercase letters, digits and '-'", project))
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		if err := validateManifestMap("metadata."+field, metadata[field]); err != nil {
+			return "", err
+		}
+	}
+	return project, nil
+}
+
+// validateManifestFields rejects fields which are not allowed. The names are case sensitive like in the
+// OpenShift API, so e.g. Labels would be ignored by OpenShift and is rejected.
+func validateManifestFields(prefix string, object map[string]interface{}, allowed []string) error {
+	var disallowed []string
+	for field := range object {
+		if !common.ContainsString(allowed, field) {
+			disallowed = append(disallowed, prefix+field)
+		}
+	}
+	if len(disallowed) > 0 {
+		sort.Strings(disallowed)
+		return common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The manifest contains fields which are not allowed: %v", strings.Join(disallowed, ", ")))
+	}
+	return nil
+}
+
+// validateManifestMap checks the labels or annotations of the manifest for string values and reserved keys
+func validateManifestMap(field string, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	values, ok := value.(map[string]interface{})
+	if !ok {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The field %v of the manifest must be an object", field))
+	}
+	var reserved []string
+	for key, v := range values {
+		if _, ok := v.(string); !ok {
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The value of %v in %v must be a string", key, field))
+		}
+		if isReservedManifestKey(key) {
+			reserved = append(reserved, key)
+		}
+	}
+	if len(reserved) > 0 {
+		sort.Strings(reserved)
+		return common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The keys %v in %v are reserved for the platform", strings.Join(reserved, ", "), field))
+	}
+	return nil
+}
+
+// isReservedManifestKey checks if the key is one of our annotations or its prefix is in manifest_reserved_domains
+func isReservedManifestKey(key string) bool {
+	keys := getAnnotationKeys()
+	if key == keys.Billing || key == keys.Requester {
+		return true
+	}
+	i := strings.Index(key, "/")
+	if i < 0 {
+		return false
+	}
+	prefix := strings.ToLower(key[:i])
+	domains := defaultReservedManifestDomains
+	if config.Config().IsSet("manifest_reserved_domains") {
+		domains = config.Config().GetStringSlice("manifest_reserved_domains")
+	}
+	for _, domain := range domains {
+		domain = strings.ToLower(domain)
+		if prefix == domain || strings.HasSuffix(prefix, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// newProjectRequestFromManifest adds the labels, annotations, displayName and description of the manifest
+// to the ProjectRequest of the cluster. The values of the project_request_template have precedence.
+func newProjectRequestFromManifest(cluster OpenshiftCluster, project string, manifest map[string]interface{}) (*gabs.Container, error) {
+	p, err := newProjectRequest(cluster, project)
+	if err != nil {
+		return nil, err
+	}
+	m := gabs.Wrap(manifest)
+	for _, field := range []string{"displayName", "description"} {
+		if m.Exists(field) && !p.Exists(field) {
+			p.Set(m.S(field).Data(), field)
+		}
+	}
+	for _, field := range []string{"labels", "annotations"} {
+		for key, value := range m.S("metadata", field).ChildrenMap() {
+			if !p.Exists("metadata", field, key) {
+				p.Set(value.Data(), "metadata", field, key)
+			}
+		}
+	}
+	return p, nil
+}
@@ -0,0 +1,78 @@
+package openshift
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func parseManifest(t *testing.T, s string) map[string]interface{} {
+	var manifest map[string]interface{}
+	if err := json.Unmarshal([]byte(s), &manifest); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	return manifest
+}
+
+func TestValidateProjectManifest(t *testing.T) {
+	config.Init("bla")
+
+	var testsets = []struct {
+		manifest string
+		valid    bool
+	}{
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project"}}`, true},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "labels": {"team": "ssp"}, "annotations": {"example.com/owner": "ssp"}}, "displayName": "My Project", "description": "test"}`, true},
+		{`{"kind": "Namespace", "apiVersion": "v1", "metadata": {"name": "my-project"}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "v1", "metadata": {"name": "my-project"}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "My_Project"}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "namespace": "default"}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project"}, "spec": {}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "annotations": {"openshift.io/node-selector": ""}}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "labels": {"pod-security.kubernetes.io/enforce": "privileged"}}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "labels": {"team": 1}}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project"}, "displayName": {}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "Labels": {"team": "ssp"}}}`, false},
+		{`{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "Metadata": {"name": "my-project"}}`, false},
+	}
+
+	for _, set := range testsets {
+		project, err := validateProjectManifest(parseManifest(t, set.manifest))
+		if set.valid && (err != nil || project != "my-project") {
+			t.Errorf("ERROR: manifest %v should be valid, got: %v, %v", set.manifest, project, err)
+		}
+		if !set.valid && err == nil {
+			t.Errorf("ERROR: manifest %v should be invalid", set.manifest)
+		}
+	}
+
+	config.Config().Set("manifest_reserved_domains", []string{"sbb.ch"})
+	manifest := `{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "labels": {"team.sbb.ch/name": "ssp"}}}`
+	if _, err := validateProjectManifest(parseManifest(t, manifest)); err == nil {
+		t.Error("ERROR: labels of manifest_reserved_domains should be rejected")
+	}
+}
+
+func TestNewProjectRequestFromManifest(t *testing.T) {
+	cluster := OpenshiftCluster{
+		ID:                     "test",
+		ProjectRequestTemplate: `{"metadata": {"annotations": {"openshift.io/node-selector": "zone=a"}}, "description": "template"}`,
+	}
+	manifest := parseManifest(t, `{"kind": "ProjectRequest", "apiVersion": "project.openshift.io/v1", "metadata": {"name": "my-project", "labels": {"team": "ssp"}, "annotations": {"example.com/owner": "ssp"}}, "displayName": "My Project", "description": "manifest"}`)
+
+	p, err := newProjectRequestFromManifest(cluster, "my-project", manifest)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if p.Path("metadata.name").Data() != "my-project" || p.S("displayName").Data() != "My Project" {
+		t.Errorf("ERROR: name and displayName should be set: %v", p)
+	}
+	if p.S("metadata", "labels", "team").Data() != "ssp" || p.S("metadata", "annotations", "example.com/owner").Data() != "ssp" {
+		t.Errorf("ERROR: labels and annotations of the manifest should be added: %v", p)
+	}
+	if p.S("metadata", "annotations", "openshift.io/node-selector").Data() != "zone=a" || p.S("description").Data() != "template" {
+		t.Errorf("ERROR: the values of the template should have precedence: %v", p)
+	}
+}
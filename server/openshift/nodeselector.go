@@ -0,0 +1,85 @@
+package openshift
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	log "github.com/sirupsen/logrus"
+)
+
+const nodeSelectorAnnotation = "openshift.io/node-selector"
+
+var (
+	labelNameRegex   = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	labelPrefixRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,251}[a-z0-9])?$`)
+)
+
+// validateNodeSelector checks the syntax of a project node selector, e.g. "node-role.kubernetes.io/app=,zone=a"
+func validateNodeSelector(selector string) error {
+	for _, term := range strings.Split(selector, ",") {
+		parts := strings.SplitN(strings.TrimSpace(term), "=", 2)
+		if len(parts) != 2 || !isValidLabelKey(parts[0]) || (parts[1] != "" && !labelNameRegex.MatchString(parts[1])) {
+			return common.NewValidationError(common.ErrorCodeInvalidRequest,
+				fmt.Sprintf("The node selector '%v' is invalid. Allowed are labels separated by commas, e.g. zone=a,node-role.kubernetes.io/app=", selector))
+		}
+	}
+	return nil
+}
+
+func isValidLabelKey(key string) bool {
+	name := key
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		if !labelPrefixRegex.MatchString(key[:i]) {
+			return false
+		}
+		name = key[i+1:]
+	}
+	return labelNameRegex.MatchString(name)
+}
+
+// validateNodeSelectorOverride checks if the user may replace the default node selector of the cluster.
+// Only members of the power_user_group may choose the node selector.
+func validateNodeSelectorOverride(username, selector string) error {
+	if selector == "" {
+		return nil
+	}
+	if err := validateNodeSelector(selector); err != nil {
+		return err
+	}
+	err := validatePowerUser(username)
+	if apiErr, ok := err.(*common.ApiError); ok && apiErr.Code == common.ErrorCodeForbidden {
+		return common.NewApiError(common.ErrorCodeForbidden, "Only power users can choose the node selector")
+	}
+	return err
+}
+
+// projectNodeSelector returns the node selector of a new project: the override of a power user,
+// otherwise the default_node_selector of the cluster unless the ProjectRequest template sets one
+func projectNodeSelector(cluster OpenshiftCluster, p *gabs.Container, override string) string {
+	if override != "" {
+		return override
+	}
+	if p.Exists("metadata", "annotations", nodeSelectorAnnotation) {
+		return ""
+	}
+	return cluster.DefaultNodeSelector
+}
+
+// setProjectNodeSelector sets the node selector annotation of a new project. The metadata updates
+// of the users don't touch the annotation, so it is kept afterwards.
+func setProjectNodeSelector(clusterId, project, selector string) error {
+	if selector == "" {
+		return nil
+	}
+	err := patchNamespaceAnnotations(clusterId, project, func(json *gabs.Container) {
+		json.Set(selector, "metadata", "annotations", nodeSelectorAnnotation)
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("Node selector of project %v on cluster %v is '%v'", project, clusterId, selector)
+	return nil
+}
@@ -0,0 +1,112 @@
+package openshift
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateNodeSelector(t *testing.T) {
+	tests := []struct {
+		selector string
+		valid    bool
+	}{
+		{"zone=a", true},
+		{"node-role.kubernetes.io/app=", true},
+		{"zone=a, node-role.kubernetes.io/app=", true},
+		{"zone", false},
+		{"zone!=a", false},
+		{"zone=a,", false},
+		{"zone=a b", false},
+		{"Example.com/zone=a", false},
+		{"zone=" + strings.Repeat("a", 64), false},
+	}
+	for _, test := range tests {
+		if err := validateNodeSelector(test.selector); (err == nil) != test.valid {
+			t.Errorf("ERROR: node selector '%v' should be valid: %v, got: %v", test.selector, test.valid, err)
+		}
+	}
+}
+
+func TestValidateNodeSelectorOverride(t *testing.T) {
+	config.Init("bla")
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+	ldapGroupsOfUser = func(username string) ([]string, error) {
+		if username == "u111111" {
+			return []string{"DG_POWER_USERS"}, nil
+		}
+		return nil, nil
+	}
+
+	if err := validateNodeSelectorOverride("u111111", "zone=a"); err == nil {
+		t.Error("ERROR: without power_user_group nobody may choose the node selector")
+	}
+	config.Config().Set("power_user_group", "DG_POWER_USERS")
+	if err := validateNodeSelectorOverride("u111111", "zone=a"); err != nil {
+		t.Errorf("ERROR: power users may choose the node selector, got: %v", err)
+	}
+	err := validateNodeSelectorOverride("u222222", "zone=a")
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Message != "Only power users can choose the node selector" {
+		t.Errorf("ERROR: other users should get the node selector error, got: %v", err)
+	}
+	if err := validateNodeSelectorOverride("u222222", ""); err != nil {
+		t.Errorf("ERROR: the default node selector needs no permission, got: %v", err)
+	}
+}
+
+func TestProjectNodeSelector(t *testing.T) {
+	cluster := OpenshiftCluster{ID: "test", DefaultNodeSelector: "zone=prod"}
+	p := newObjectRequest("ProjectRequest", "project", "project.openshift.io/v1")
+	if s := projectNodeSelector(cluster, p, ""); s != "zone=prod" {
+		t.Errorf("ERROR: the default node selector should be used, got: %v", s)
+	}
+	if s := projectNodeSelector(cluster, p, "zone=a"); s != "zone=a" {
+		t.Errorf("ERROR: the override should be used, got: %v", s)
+	}
+	p.Set("zone=template", "metadata", "annotations", nodeSelectorAnnotation)
+	if s := projectNodeSelector(cluster, p, ""); s != "" {
+		t.Errorf("ERROR: the node selector of the template should be kept, got: %v", s)
+	}
+}
+
+func TestNodeSelectorIsKeptOnMetadataUpdates(t *testing.T) {
+	var patches []*gabs.Container
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"metadata": {"name": "project", "annotations": {"openshift.io/node-selector": "zone=prod"}}}`))
+		case "PATCH":
+			body, _ := ioutil.ReadAll(r.Body)
+			patch, err := gabs.ParseJSON(body)
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			patches = append(patches, patch)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := createOrUpdateMetadata("test", "project", "9999", "1234", "", "", "user", false); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if err := setProjectNodeSelector("test", "project", "zone=a"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(patches) != 2 {
+		t.Fatalf("ERROR: expected 2 patches, got: %v", patches)
+	}
+	if patches[0].Exists("metadata", "annotations", nodeSelectorAnnotation) {
+		t.Errorf("ERROR: metadata updates should keep the node selector, got: %v", patches[0])
+	}
+	if patches[1].S("metadata", "annotations", nodeSelectorAnnotation).Data() != "zone=a" {
+		t.Errorf("ERROR: the node selector should be set, got: %v", patches[1])
+	}
+}
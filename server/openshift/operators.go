@@ -0,0 +1,80 @@
+package openshift
+
+import (
+	"net/http"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProjectOperators are the OLM subscriptions of a project (OpenShift 4)
+type ProjectOperators struct {
+	Subscriptions []Subscription `json:"subscriptions"`
+	// Set if the cluster has no OLM, e.g. on OpenShift 3
+	Note string `json:"note,omitempty"`
+}
+
+// Subscription is an operator installed with OLM
+type Subscription struct {
+	Name         string `json:"name"`
+	Package      string `json:"package"`
+	Channel      string `json:"channel"`
+	Source       string `json:"source"`
+	InstalledCSV string `json:"installedCSV"`
+	// e.g. AtLatestKnown or UpgradePending
+	State string `json:"state"`
+}
+
+func getProjectOperatorsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	clusterId := c.Query("clusterid")
+	project := c.Query("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	operators, err := getProjectOperators(clusterId, project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, operators)
+}
+
+func getProjectOperators(clusterId, project string) (*ProjectOperators, error) {
+	resp, err := getOseHTTPClient("GET", clusterId, "apis/operators.coreos.com/v1alpha1/namespaces/"+project+"/subscriptions", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// The API group doesn't exist without OLM
+	if resp.StatusCode == http.StatusNotFound {
+		log.Printf("OLM is not available on cluster %v", clusterId)
+		return &ProjectOperators{Subscriptions: []Subscription{}, Note: "Operators are not available on this cluster"}, nil
+	}
+	json, err := parseJSONResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return &ProjectOperators{Subscriptions: parseSubscriptions(json)}, nil
+}
+
+func parseSubscriptions(json *gabs.Container) []Subscription {
+	subscriptions := []Subscription{}
+	for _, item := range json.S("items").Children() {
+		s := Subscription{}
+		s.Name, _ = item.Path("metadata.name").Data().(string)
+		s.Package, _ = item.Path("spec.name").Data().(string)
+		s.Channel, _ = item.Path("spec.channel").Data().(string)
+		s.Source, _ = item.Path("spec.source").Data().(string)
+		s.InstalledCSV, _ = item.Path("status.installedCSV").Data().(string)
+		s.State, _ = item.Path("status.state").Data().(string)
+		subscriptions = append(subscriptions, s)
+	}
+	return subscriptions
+}
@@ -0,0 +1,50 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGetProjectOperators(t *testing.T) {
+	olm := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/apis/operators.coreos.com/v1alpha1/namespaces/project/subscriptions" {
+			t.Errorf("ERROR: unexpected request %v", r.URL.Path)
+		}
+		if !olm {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("404 page not found"))
+			return
+		}
+		w.Write([]byte(`{"kind": "SubscriptionList", "items": [{
+			"metadata": {"name": "amq-streams"},
+			"spec": {"name": "amq-streams", "channel": "stable", "source": "redhat-operators"},
+			"status": {"installedCSV": "amqstreams.v2.1.0", "state": "AtLatestKnown"}
+		}, {
+			"metadata": {"name": "pending"},
+			"spec": {"name": "etcd", "channel": "alpha", "source": "community-operators"}
+		}]}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	operators, err := getProjectOperators("test", "project")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	expected := Subscription{Name: "amq-streams", Package: "amq-streams", Channel: "stable", Source: "redhat-operators",
+		InstalledCSV: "amqstreams.v2.1.0", State: "AtLatestKnown"}
+	if len(operators.Subscriptions) != 2 || operators.Subscriptions[0] != expected || operators.Note != "" {
+		t.Errorf("ERROR: unexpected subscriptions: %+v", operators)
+	}
+	if s := operators.Subscriptions[1]; s.Package != "etcd" || s.InstalledCSV != "" {
+		t.Errorf("ERROR: subscriptions without status should be returned: %+v", s)
+	}
+
+	olm = false
+	operators, err = getProjectOperators("test", "project")
+	if err != nil || operators.Subscriptions == nil || len(operators.Subscriptions) != 0 || operators.Note == "" {
+		t.Errorf("ERROR: without OLM an empty list with a note should be returned, got: %+v, %v", operators, err)
+	}
+}
@@ -0,0 +1,696 @@
+package openshift
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/ldap"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// projectRoles maps the roles used in the SSP to the rolebindings in OpenShift
+var projectRoles = map[string]string{
+	"admin":    "admin",
+	"operator": "edit",
+}
+
+func addProjectGroupHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectGroupCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validateProjectGroup(data.ClusterId, username, data.Project, data.Group, data.Role); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := validateLdapGroup(data.Group); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := changeProjectGroupPermission(data.ClusterId, data.Project, data.Group, data.Role, false); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  data.ClusterId,
+		"project":  data.Project,
+		"group":    data.Group,
+		"role":     data.Role,
+		"username": username,
+	}).Info("Group was added to project")
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: fmt.Sprintf("The group %v has been added as %v to the project %v", data.Group, data.Role, data.Project),
+	})
+}
+
+func removeProjectGroupHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	params := c.Request.URL.Query()
+	clusterId := params.Get("clusterid")
+	project := params.Get("project")
+	group := params.Get("group")
+	role := params.Get("role")
+
+	// The platform operators keep their access, like when the permissions are replaced
+	if strings.EqualFold(group, operatorGroup) {
+		common.RespondError(c, http.StatusForbidden, common.NewApiError(common.ErrorCodeForbidden, "The operator group can't be removed from a project"))
+		return
+	}
+
+	if err := validateProjectGroup(clusterId, username, project, group, role); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := changeProjectGroupPermission(clusterId, project, group, role, true); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  clusterId,
+		"project":  project,
+		"group":    group,
+		"role":     role,
+		"username": username,
+	}).Info("Group was removed from project")
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: fmt.Sprintf("The group %v has been removed as %v from the project %v", group, role, project),
+	})
+}
+
+// copyProjectPermissionsHandler copies the admins and operators of the source project to the target project
+func copyProjectPermissionsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.CopyPermissionsCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if data.Mode == "" {
+		data.Mode = copyModeMerge
+	}
+	if data.Mode != copyModeMerge && data.Mode != copyModeReplace {
+		common.RespondError(c, http.StatusBadRequest, common.NewValidationError(common.ErrorCodeInvalidRequest, "Mode must be merge or replace"))
+		return
+	}
+	if data.Source == data.Target {
+		common.RespondError(c, http.StatusBadRequest, common.NewValidationError(common.ErrorCodeInvalidRequest, "Source and target must be different projects"))
+		return
+	}
+	for _, project := range []common.OpenshiftBase{data.Source, data.Target} {
+		if err := validateAdminAccess(project.ClusterId, username, project.Project); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	result, err := copyProjectPermissions(data.Source, data.Target, data.Mode, username)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"source":   data.Source.ClusterId + "/" + data.Source.Project,
+		"cluster":  data.Target.ClusterId,
+		"project":  data.Target.Project,
+		"mode":     data.Mode,
+		"added":    len(result.Added),
+		"removed":  len(result.Removed),
+		"username": username,
+	}).Info("Permissions were copied to project")
+
+	c.JSON(http.StatusOK, result)
+}
+
+const (
+	copyModeMerge   = "merge"
+	copyModeReplace = "replace"
+)
+
+// operatorGroup is the OpenShift group of the platform operators. It is added to the admin rolebinding
+// by the platform and never removed by the portal
+const operatorGroup = "operator"
+
+// copyProjectPermissions copies the user and group subjects of the admin and operator rolebindings.
+// With mode replace, the users and groups of the target which are not in the source are removed,
+// except the user who copies the permissions and the operator group. Other subjects (e.g. service accounts) are kept.
+func copyProjectPermissions(source, target common.OpenshiftBase, mode, username string) (CopyPermissionsResult, error) {
+	result := CopyPermissionsResult{Added: []PermissionSubject{}, Removed: []PermissionSubject{}}
+	for _, role := range []string{"admin", "operator"} {
+		name := projectRoles[role]
+		sourceRoleBinding, err := getRoleBinding(source.ClusterId, source.Project, name)
+		if err != nil {
+			return result, err
+		}
+		if sourceRoleBinding == nil {
+			sourceRoleBinding = newRoleBindingRequest(name)
+		}
+		targetRoleBinding, err := getRoleBinding(target.ClusterId, target.Project, name)
+		if err != nil {
+			return result, err
+		}
+		create := targetRoleBinding == nil
+		if create {
+			targetRoleBinding = newRoleBindingRequest(name)
+		}
+
+		keepUser := ""
+		if role == "admin" {
+			keepUser = username
+		}
+		added, removed := copySubjects(sourceRoleBinding, targetRoleBinding, mode == copyModeReplace, keepUser)
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		if err := saveRoleBinding(target.ClusterId, target.Project, targetRoleBinding, create); err != nil {
+			return result, err
+		}
+		for _, subject := range added {
+			result.Added = append(result.Added, PermissionSubject{Role: role, Kind: subject.Kind, Name: subject.Name})
+		}
+		for _, subject := range removed {
+			result.Removed = append(result.Removed, PermissionSubject{Role: role, Kind: subject.Kind, Name: subject.Name})
+		}
+	}
+	return result, nil
+}
+
+// copySubjects adds the user and group subjects of source to target and returns the added subjects.
+// If replace is set, the users and groups of target which are not in source are removed, except keepUser
+// and the operator group.
+func copySubjects(source, target *gabs.Container, replace bool, keepUser string) ([]OpenshiftSubject, []OpenshiftSubject) {
+	isCopied := func(kind string) bool {
+		return kind == "User" || kind == "Group"
+	}
+	isKept := func(kind, name string) bool {
+		return (kind == "User" && strings.EqualFold(name, keepUser)) || (kind == "Group" && strings.EqualFold(name, operatorGroup))
+	}
+	key := func(kind, name string) string {
+		return kind + "/" + name
+	}
+
+	sourceSubjects := map[string]bool{}
+	for _, subject := range source.S("subjects").Children() {
+		kind, _ := subject.S("kind").Data().(string)
+		name, _ := subject.S("name").Data().(string)
+		sourceSubjects[key(kind, name)] = true
+	}
+
+	var subjects []interface{}
+	var removed []OpenshiftSubject
+	existing := map[string]bool{}
+	for _, subject := range target.S("subjects").Children() {
+		kind, _ := subject.S("kind").Data().(string)
+		name, _ := subject.S("name").Data().(string)
+		if replace && isCopied(kind) && !sourceSubjects[key(kind, name)] && !isKept(kind, name) {
+			removed = append(removed, OpenshiftSubject{Kind: kind, Name: name})
+			continue
+		}
+		existing[key(kind, name)] = true
+		subjects = append(subjects, subject.Data())
+	}
+
+	var added []OpenshiftSubject
+	for _, subject := range source.S("subjects").Children() {
+		kind, _ := subject.S("kind").Data().(string)
+		name, _ := subject.S("name").Data().(string)
+		if !isCopied(kind) || existing[key(kind, name)] {
+			continue
+		}
+		existing[key(kind, name)] = true
+		newSubject := OpenshiftSubject{ApiGroup: "rbac.authorization.k8s.io", Kind: kind, Name: name}
+		added = append(added, newSubject)
+		subjects = append(subjects, newSubject)
+	}
+
+	target.Array("subjects")
+	for _, subject := range subjects {
+		target.ArrayAppend(subject, "subjects")
+	}
+	return added, removed
+}
+
+// ProjectRole is the effective role of the user in a project: admin, edit, view or none
+type ProjectRole struct {
+	Role string `json:"role"`
+}
+
+// getProjectRoleHandler returns the role of the current user in the project,
+// so that the UI can hide actions which are not allowed
+func getProjectRoleHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	clusterId := c.Query("clusterid")
+	project := c.Query("project")
+
+	if clusterId == "" || project == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	role, err := getProjectRole(clusterId, project, username)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	c.JSON(http.StatusOK, ProjectRole{Role: role})
+}
+
+// getProjectRole returns the highest role of the user in the project.
+// Admins and operators (see getProjectAdminsAndOperators) are admin, otherwise the
+// user and group subjects of the rolebindings are checked.
+// checkAdminPermissions allows the admin actions for exactly the users with the role admin.
+func getProjectRole(clusterId, project, username string) (string, error) {
+	admins, operators, err := getProjectAdminsAndOperators(clusterId, project)
+	if err != nil {
+		return "", err
+	}
+	if common.ContainsStringI(admins, username) || common.ContainsStringI(operators, username) {
+		return "admin", nil
+	}
+
+	roleBindings, err := getRoleBindings(clusterId, project)
+	if err != nil {
+		return "", err
+	}
+	return effectiveRole(roleBindings, username, func(groups []string) (bool, error) {
+		return isInAnyLdapGroup(username, groups)
+	})
+}
+
+// effectiveRole returns the highest role (admin, edit, view) whose rolebindings contain the user
+// or one of the groups of the user. The LDAP groups are only looked up if a rolebinding contains groups.
+// The operator group is skipped, its members are already admins (see getProjectAdminsAndOperators).
+func effectiveRole(roleBindings *gabs.Container, username string, isInAnyGroup func(groups []string) (bool, error)) (string, error) {
+	users := map[string][]string{}
+	groups := map[string][]string{}
+	for _, roleBinding := range roleBindings.S("items").Children() {
+		role, _ := roleBinding.Path("roleRef.name").Data().(string)
+		for _, subject := range roleBinding.S("subjects").Children() {
+			name, _ := subject.S("name").Data().(string)
+			switch subject.S("kind").Data() {
+			case "User":
+				users[role] = append(users[role], name)
+			case "Group":
+				if !strings.EqualFold(name, operatorGroup) {
+					groups[role] = append(groups[role], name)
+				}
+			}
+		}
+	}
+
+	for _, role := range []string{"admin", "edit", "view"} {
+		if common.ContainsStringI(users[role], username) {
+			return role, nil
+		}
+		if len(groups[role]) == 0 {
+			continue
+		}
+		isMember, err := isInAnyGroup(groups[role])
+		if err != nil {
+			return "", err
+		}
+		if isMember {
+			return role, nil
+		}
+	}
+	return "none", nil
+}
+
+func validateProjectGroup(clusterId, username, project, group, role string) error {
+	if group == "" {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "Group must be provided")
+	}
+
+	if _, ok := projectRoles[role]; !ok {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "Role must be admin or operator")
+	}
+
+	return validateAdminAccess(clusterId, username, project)
+}
+
+func validateLdapGroup(group string) error {
+	l, err := ldap.New()
+	if err != nil {
+		log.Errorf("%v", err)
+		return common.NewApiError(common.ErrorCodeBackendError, common.ConfigNotSetError)
+	}
+	defer l.Close()
+
+	exists, err := l.GroupExists(group)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"group": group,
+			"err":   err.Error(),
+		}).Error("Error looking up LDAP group")
+		return common.NewApiError(common.ErrorCodeBackendError, genericAPIError)
+	}
+	if !exists {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The group %v does not exist", group))
+	}
+	return nil
+}
+
+// changeProjectGroupPermission adds or removes a group subject in the rolebinding of the role.
+// The rolebinding is created if it doesn't exist yet.
+func changeProjectGroupPermission(clusterId, project, group, role string, remove bool) error {
+	roleBindingName := projectRoles[role]
+	roleBinding, err := getRoleBinding(clusterId, project, roleBindingName)
+	if err != nil {
+		return err
+	}
+
+	create := roleBinding == nil
+	if create {
+		if remove {
+			return nil
+		}
+		roleBinding = newRoleBindingRequest(roleBindingName)
+	}
+
+	roleBinding = setGroupSubject(roleBinding, group, remove)
+
+	return saveRoleBinding(clusterId, project, roleBinding, create)
+}
+
+// validateOwnerGroup checks the optional owner group of a project
+func validateOwnerGroup(group string) error {
+	if group == "" {
+		return nil
+	}
+	return validateLdapGroup(group)
+}
+
+// Usernames like u123456. Can be changed with username_pattern
+const defaultUsernamePattern = `^[a-zA-Z0-9][a-zA-Z0-9._-]{0,63}$`
+
+// sanitizeUsername trims the username and checks it against username_pattern,
+// before it is used in LDAP queries, rolebindings or annotations
+func sanitizeUsername(username string) (string, error) {
+	username = strings.TrimSpace(username)
+	pattern := config.Config().GetString("username_pattern")
+	if pattern == "" {
+		pattern = defaultUsernamePattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Printf("WARNING: invalid username_pattern: %v", err)
+		return "", common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	if !re.MatchString(username) {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("Invalid username '%v'", username))
+	}
+	return username, nil
+}
+
+const defaultMaxProjectOperators = 50
+
+// validateOperatorCount refuses requests with more than max_project_operators (default 50) operators.
+// It is checked before the usernames are looked up, so huge requests don't reach LDAP and the rolebindings.
+func validateOperatorCount(operators []string) error {
+	max := config.Config().GetInt("max_project_operators")
+	if max <= 0 {
+		max = defaultMaxProjectOperators
+	}
+	if len(operators) > max {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, fmt.Sprintf("Too many operators, the maximum is %v", max))
+	}
+	return nil
+}
+
+func sanitizeUsernames(usernames []string) ([]string, error) {
+	sanitized := []string{}
+	for _, u := range usernames {
+		username, err := sanitizeUsername(u)
+		if err != nil {
+			return nil, err
+		}
+		sanitized = append(sanitized, username)
+	}
+	return sanitized, nil
+}
+
+func validateLdapUsers(usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	usernames, err := sanitizeUsernames(usernames)
+	if err != nil {
+		return err
+	}
+	users, err := ldap.LookupUsers(usernames)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"usernames": usernames,
+			"err":       err.Error(),
+		}).Error("Error looking up LDAP users")
+		return errors.New(genericAPIError)
+	}
+	for _, user := range users {
+		if !user.Exists {
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The user %v does not exist", user.Username))
+		}
+	}
+	return nil
+}
+
+// addProjectOperators adds the users to the edit rolebinding of the project
+func addProjectOperators(clusterId, project string, operators []string) error {
+	if len(operators) == 0 {
+		return nil
+	}
+	roleBinding, err := getRoleBinding(clusterId, project, projectRoles["operator"])
+	if err != nil {
+		return err
+	}
+	create := roleBinding == nil
+	if create {
+		roleBinding = newRoleBindingRequest(projectRoles["operator"])
+	}
+	appendUserSubjects(roleBinding, operators)
+	if err := saveRoleBinding(clusterId, project, roleBinding, create); err != nil {
+		return err
+	}
+	log.Printf("%v are now operators of %v", strings.Join(operators, ", "), project)
+	return nil
+}
+
+func appendUserSubjects(roleBinding *gabs.Container, usernames []string) {
+	for _, username := range usernames {
+		for _, subject := range userSubjects(username) {
+			roleBinding.ArrayAppend(subject, "subjects")
+		}
+	}
+}
+
+// userSubjects returns the subjects for a user. Users are added in lower- and uppercase,
+// because the username in the token can have both variants.
+func userSubjects(username string) []OpenshiftSubject {
+	return []OpenshiftSubject{
+		{
+			ApiGroup: "rbac.authorization.k8s.io",
+			Kind:     "User",
+			Name:     strings.ToLower(username),
+		},
+		{
+			ApiGroup: "rbac.authorization.k8s.io",
+			Kind:     "User",
+			Name:     strings.ToUpper(username),
+		},
+	}
+}
+
+// setGroupSubject returns the rolebinding with the group subject added or removed.
+// Other subjects are not changed.
+func setGroupSubject(roleBinding *gabs.Container, group string, remove bool) *gabs.Container {
+	var subjects []interface{}
+	for _, subject := range roleBinding.S("subjects").Children() {
+		if subject.S("kind").Data() == "Group" && subject.S("name").Data() == group {
+			continue
+		}
+		subjects = append(subjects, subject.Data())
+	}
+	roleBinding.Array("subjects")
+	for _, subject := range subjects {
+		roleBinding.ArrayAppend(subject, "subjects")
+	}
+	if !remove {
+		roleBinding.ArrayAppend(OpenshiftSubject{
+			ApiGroup: "rbac.authorization.k8s.io",
+			Kind:     "Group",
+			Name:     group,
+		}, "subjects")
+	}
+	return roleBinding
+}
+
+func newRoleBindingRequest(name string) *gabs.Container {
+	roleBinding := newObjectRequest("RoleBinding", name, "rbac.authorization.k8s.io/v1")
+	roleBinding.Set("rbac.authorization.k8s.io", "roleRef", "apiGroup")
+	roleBinding.Set("ClusterRole", "roleRef", "kind")
+	roleBinding.Set(name, "roleRef", "name")
+	roleBinding.Array("subjects")
+	return roleBinding
+}
+
+// saveRoleBinding creates (POST) or updates (PUT) the rolebinding
+func saveRoleBinding(clusterId, project string, roleBinding *gabs.Container, create bool) error {
+	method := "PUT"
+	url := "apis/rbac.authorization.k8s.io/v1/namespaces/" + project + "/rolebindings"
+	expectedStatus := http.StatusOK
+	if create {
+		method = "POST"
+		expectedStatus = http.StatusCreated
+	} else {
+		url += "/" + roleBinding.Path("metadata.name").Data().(string)
+	}
+
+	resp, err := getOseHTTPClient(method, clusterId, url, bytes.NewReader(roleBinding.Bytes()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		log.Println("Error saving rolebinding:", resp.StatusCode, string(errMsg))
+		return newUpstreamError(method, resp.StatusCode, errMsg)
+	}
+	return nil
+}
+
+// getProjectRequester returns the user which is set as requester and admin of a new project.
+// Members of the service desk group can create projects on behalf of other users.
+func getProjectRequester(username, onBehalfOf string) (string, error) {
+	if onBehalfOf == "" || strings.EqualFold(onBehalfOf, username) {
+		return username, nil
+	}
+
+	serviceDeskGroup := config.Config().GetString("servicedesk_group")
+	if serviceDeskGroup == "" {
+		return "", common.NewApiError(common.ErrorCodeForbidden, "Creating projects on behalf of other users is not enabled")
+	}
+
+	isServiceDesk, err := isInLdapGroup(username, serviceDeskGroup)
+	if err != nil {
+		return "", err
+	}
+	if !isServiceDesk {
+		return "", common.NewApiError(common.ErrorCodeForbidden, "Only the service desk can create projects on behalf of other users")
+	}
+
+	l, err := ldap.New()
+	if err != nil {
+		log.Errorf("%v", err)
+		return "", common.NewApiError(common.ErrorCodeBackendError, common.ConfigNotSetError)
+	}
+	defer l.Close()
+
+	exists, err := l.UserExists(onBehalfOf)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"username": onBehalfOf,
+			"err":      err.Error(),
+		}).Error("Error looking up LDAP user")
+		return "", common.NewApiError(common.ErrorCodeBackendError, genericAPIError)
+	}
+	if !exists {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The user %v does not exist", onBehalfOf))
+	}
+	return onBehalfOf, nil
+}
+
+// validatePortalAdmin checks if the user is a member of the admin_group.
+// Portal admins can use the endpoints which show data of all projects.
+func validatePortalAdmin(username string) error {
+	adminGroup := config.Config().GetString("admin_group")
+	if adminGroup == "" {
+		return common.NewApiError(common.ErrorCodeForbidden, "This function is not enabled (admin_group is not configured)")
+	}
+	isAdmin, err := isInLdapGroup(username, adminGroup)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return common.NewApiError(common.ErrorCodeForbidden, "Only portal admins can use this function")
+	}
+	return nil
+}
+
+func isInLdapGroup(username, group string) (bool, error) {
+	return isInAnyLdapGroup(username, []string{group})
+}
+
+// getLdapGroupsOfUser returns no groups for users which can't be in LDAP, e.g. the pseudo-users of API tokens,
+// and if LDAP is not configured. So the group checks refuse them with FORBIDDEN.
+func getLdapGroupsOfUser(username string) ([]string, error) {
+	username, err := sanitizeUsername(username)
+	if err != nil {
+		return nil, nil
+	}
+	l, err := ldap.New()
+	if err != nil {
+		log.Errorf("Can't look up the LDAP groups of %v: %v", username, err)
+		return nil, nil
+	}
+	defer l.Close()
+
+	groups, err := l.GetGroupsOfUser(username)
+	if err == ldap.ErrUserNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"username": username,
+			"err":      err.Error(),
+		}).Error("Error looking up LDAP groups")
+		return nil, errors.New(genericAPIError)
+	}
+	return groups, nil
+}
+
+// ldapGroupsOfUser looks up the groups of the user, replaced in tests
+var ldapGroupsOfUser = getLdapGroupsOfUser
+
+func isInAnyLdapGroup(username string, allowedGroups []string) (bool, error) {
+	groups, err := ldapGroupsOfUser(username)
+	if err != nil {
+		return false, err
+	}
+	for _, group := range allowedGroups {
+		if common.ContainsStringI(groups, group) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func auditProjectOnBehalfOf(clusterId, project, username, requester string) {
+	log.WithFields(log.Fields{
+		"cluster":   clusterId,
+		"project":   project,
+		"username":  username,
+		"requester": requester,
+	}).Info("AUDIT: Project was created on behalf of another user")
+}
@@ -0,0 +1,326 @@
+package openshift
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAppendUserSubjects(t *testing.T) {
+	roleBinding := newRoleBindingRequest("edit")
+	appendUserSubjects(roleBinding, []string{"u123456", "U654321"})
+	// the subjects are structs until they are serialized
+	json, err := gabs.ParseJSON(roleBinding.Bytes())
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+
+	expected := []string{"u123456", "U123456", "u654321", "U654321"}
+	subjects := json.S("subjects").Children()
+	if len(subjects) != len(expected) {
+		t.Fatalf("ERROR: number of subjects should be %v, but is: %v", len(expected), len(subjects))
+	}
+	for i, subject := range subjects {
+		if subject.S("name").Data() != expected[i] {
+			t.Errorf("ERROR: subject %v should be %v, but is: %v", i, expected[i], subject.S("name").Data())
+		}
+		if subject.S("kind").Data() != "User" {
+			t.Errorf("ERROR: subject %v should be of kind User, but is: %v", i, subject.S("kind").Data())
+		}
+	}
+}
+
+func TestCopySubjects(t *testing.T) {
+	source, _ := gabs.ParseJSON([]byte(`{"subjects": [
+		{"kind": "User", "name": "u111111"},
+		{"kind": "Group", "name": "team-a"},
+		{"kind": "ServiceAccount", "name": "deployer", "namespace": "source"}]}`))
+	newTarget := func() *gabs.Container {
+		target, _ := gabs.ParseJSON([]byte(`{"subjects": [
+			{"kind": "User", "name": "u111111"},
+			{"kind": "User", "name": "u222222"},
+			{"kind": "User", "name": "u333333"},
+			{"kind": "Group", "name": "operator"},
+			{"kind": "ServiceAccount", "name": "jenkins", "namespace": "target"}]}`))
+		return target
+	}
+
+	target := newTarget()
+	added, removed := copySubjects(source, target, false, "u222222")
+	if len(added) != 1 || added[0].Kind != "Group" || added[0].Name != "team-a" || len(removed) != 0 {
+		t.Errorf("ERROR: merge should only add the group, added: %v, removed: %v", added, removed)
+	}
+	if n := len(target.S("subjects").Children()); n != 6 {
+		t.Errorf("ERROR: merge should keep all subjects of the target, got %v subjects", n)
+	}
+
+	target = newTarget()
+	added, removed = copySubjects(source, target, true, "U222222")
+	if len(added) != 1 || len(removed) != 1 || removed[0].Name != "u333333" {
+		t.Errorf("ERROR: replace should remove u333333, added: %v, removed: %v", added, removed)
+	}
+	// the added subjects are structs until they are serialized
+	json, err := gabs.ParseJSON(target.Bytes())
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	var names []string
+	for _, subject := range json.S("subjects").Children() {
+		names = append(names, subject.S("name").Data().(string))
+	}
+	expected := "u111111 u222222 operator jenkins team-a"
+	if strings.Join(names, " ") != expected {
+		t.Errorf("ERROR: replace should keep the caller, the operator group and service accounts. Expected: %v, got: %v", expected, names)
+	}
+}
+
+func TestEffectiveRole(t *testing.T) {
+	roleBindings, _ := gabs.ParseJSON([]byte(`{"items": [
+		{"roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u111111"}]},
+		{"roleRef": {"name": "edit"}, "subjects": [{"kind": "Group", "name": "team-a"}]},
+		{"roleRef": {"name": "view"}, "subjects": [{"kind": "User", "name": "U333333"}]}
+	]}`))
+	inTeamA := func(groups []string) (bool, error) {
+		return len(groups) == 1 && groups[0] == "team-a", nil
+	}
+	noGroups := func(groups []string) (bool, error) {
+		return false, nil
+	}
+
+	var testsets = []struct {
+		username     string
+		isInAnyGroup func(groups []string) (bool, error)
+		role         string
+	}{
+		{"u111111", noGroups, "admin"},
+		{"u222222", inTeamA, "edit"},
+		{"u333333", noGroups, "view"},
+		{"u444444", noGroups, "none"},
+	}
+	for _, set := range testsets {
+		if role, err := effectiveRole(roleBindings, set.username, set.isInAnyGroup); err != nil || role != set.role {
+			t.Errorf("ERROR: role of %v should be %v, but is: %v (error: %v)", set.username, set.role, role, err)
+		}
+	}
+
+	// the members of the operator group are already admins, LDAP is not needed for it
+	roleBindings, _ = gabs.ParseJSON([]byte(`{"items": [
+		{"roleRef": {"name": "admin"}, "subjects": [{"kind": "Group", "name": "operator"}]}
+	]}`))
+	ldapDown := func(groups []string) (bool, error) {
+		return false, errors.New("LDAP is down")
+	}
+	if role, err := effectiveRole(roleBindings, "u444444", ldapDown); err != nil || role != "none" {
+		t.Errorf("ERROR: the operator group should not be looked up in LDAP, got: %v (error: %v)", role, err)
+	}
+}
+
+func TestGetLdapGroupsOfUnknownUsers(t *testing.T) {
+	// without LDAP config and for names which can't be LDAP users
+	config.Init("bla")
+	for _, username := range []string{"u123456", "ci pipeline"} {
+		if groups, err := getLdapGroupsOfUser(username); err != nil || len(groups) != 0 {
+			t.Errorf("ERROR: %v should have no groups, got: %v (error: %v)", username, groups, err)
+		}
+	}
+}
+
+func TestValidateOperatorCount(t *testing.T) {
+	config.Init("bla")
+	operators := make([]string, defaultMaxProjectOperators)
+	if err := validateOperatorCount(operators); err != nil {
+		t.Errorf("ERROR: %v operators should be allowed, got: %v", len(operators), err)
+	}
+	if err := validateOperatorCount(append(operators, "u123456")); err == nil {
+		t.Errorf("ERROR: more than %v operators should be refused", defaultMaxProjectOperators)
+	} else if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Validation {
+		t.Errorf("ERROR: too many operators should be refused with 400, got: %v", err)
+	}
+
+	config.Config().Set("max_project_operators", 2)
+	if err := validateOperatorCount([]string{"u1", "u2", "u3"}); err == nil {
+		t.Error("ERROR: max_project_operators should be used")
+	}
+}
+
+func TestSanitizeUsername(t *testing.T) {
+	config.Init("bla")
+	var testsets = []struct {
+		username  string
+		sanitized string
+		valid     bool
+	}{
+		{"u123456", "u123456", true},
+		{"  U123456\n", "U123456", true},
+		{"svc-ci.deploy_1", "svc-ci.deploy_1", true},
+		{"u123 456", "", false},
+		{"u123456,u654321", "", false},
+		{"u123456)(cn=*", "", false},
+		{"ü123456", "", false},
+		{"u123456\u200b", "", false},
+		{"", "", false},
+		{"-u123456", "", false},
+	}
+	for _, set := range testsets {
+		sanitized, err := sanitizeUsername(set.username)
+		if set.valid != (err == nil) || sanitized != set.sanitized {
+			t.Errorf("ERROR: '%v' should be valid: %v and sanitized '%v', got '%v' (error: %v)", set.username, set.valid, set.sanitized, sanitized, err)
+		}
+		if err != nil {
+			if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeInvalidRequest {
+				t.Errorf("ERROR: expected INVALID_REQUEST for '%v', got: %v", set.username, err)
+			}
+		}
+	}
+
+	config.Config().Set("username_pattern", `^[a-z0-9]+@example\.com$`)
+	if _, err := sanitizeUsername("user@example.com"); err != nil {
+		t.Errorf("ERROR: username_pattern should be used, got: %v", err)
+	}
+	if _, err := sanitizeUsernames([]string{"user@example.com", "u123456"}); err == nil {
+		t.Error("ERROR: all usernames should be checked")
+	}
+}
+
+func TestCheckAdminPermissionsMatchesProjectRole(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": [
+			{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u111111"}, {"kind": "Group", "name": "team-admins"}]},
+			{"metadata": {"name": "edit"}, "roleRef": {"name": "edit"}, "subjects": [{"kind": "Group", "name": "team-devs"}]}
+		]}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	groups := map[string][]string{
+		"u222222": {"team-admins"},
+		"u333333": {"team-devs"},
+	}
+	ldapGroupsOfUser = func(username string) ([]string, error) {
+		return groups[username], nil
+	}
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+
+	var testsets = []struct {
+		username string
+		role     string
+	}{
+		{"u111111", "admin"},
+		// only admin through the group of the admin rolebinding
+		{"u222222", "admin"},
+		{"u333333", "edit"},
+		{"u444444", "none"},
+	}
+	for _, set := range testsets {
+		role, err := getProjectRole("test", "project", set.username)
+		if err != nil || role != set.role {
+			t.Errorf("ERROR: role of %v should be %v, got: %v (error: %v)", set.username, set.role, role, err)
+		}
+		err = checkAdminPermissions("test", set.username, "project")
+		if (set.role == "admin") != (err == nil) {
+			t.Errorf("ERROR: admin check of %v doesn't match the role %v, got: %v", set.username, set.role, err)
+		}
+	}
+}
+
+func TestCheckAdminPermissionsForProjects(t *testing.T) {
+	var inFlight, maxSeen int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxSeen)
+			if n <= m || atomic.CompareAndSwapInt32(&maxSeen, m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		switch {
+		case strings.Contains(r.URL.Path, "/namespaces/broken/"):
+			w.WriteHeader(http.StatusInternalServerError)
+		case strings.Contains(r.URL.Path, "/namespaces/mine"):
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u111111"}]}]}`))
+		default:
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u222222"}]}]}`))
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	ldapGroupsOfUser = func(username string) ([]string, error) {
+		return nil, nil
+	}
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+
+	projects := []string{"other", "broken", "mine"}
+	for i := 0; i < 2*maxParallelAdminChecks; i++ {
+		projects = append(projects, fmt.Sprintf("mine-%v", i))
+	}
+	results := checkAdminPermissionsForProjects("test", "u111111", projects)
+	if len(results) != len(projects) {
+		t.Fatalf("ERROR: expected a result for each of the %v projects, got %v", len(projects), len(results))
+	}
+	for _, project := range projects {
+		err := results[project]
+		switch project {
+		case "other":
+			if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeForbidden {
+				t.Errorf("ERROR: %v should be FORBIDDEN, got: %v", project, err)
+			}
+		case "broken":
+			if err == nil {
+				t.Errorf("ERROR: %v should return the OpenShift error", project)
+			}
+		default:
+			if err != nil {
+				t.Errorf("ERROR: u111111 should be admin of %v, got: %v", project, err)
+			}
+		}
+	}
+	if maxSeen > maxParallelAdminChecks {
+		t.Errorf("ERROR: at most %v requests should run at the same time, got %v", maxParallelAdminChecks, maxSeen)
+	}
+
+	results = checkAdminPermissionsForProjects("unknown", "u111111", []string{"mine"})
+	if results["mine"] == nil {
+		t.Error("ERROR: an unknown cluster should fail all projects")
+	}
+}
+
+func TestProjectGroupHandlersValidationStatus(t *testing.T) {
+	config.Init("bla")
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("POST", "/ose/project/groups", strings.NewReader(`{"clusterid": "test", "project": "project", "role": "admin"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	addProjectGroupHandler(c)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), common.ErrorCodeInvalidRequest) {
+		t.Errorf("ERROR: a missing group should return 422 with INVALID_REQUEST, but returned %v: %v", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/ose/project/groups?clusterid=test&project=project&group=group&role=owner", nil)
+	removeProjectGroupHandler(c)
+	if w.Code != http.StatusUnprocessableEntity || !strings.Contains(w.Body.String(), common.ErrorCodeInvalidRequest) {
+		t.Errorf("ERROR: an invalid role should return 422 with INVALID_REQUEST, but returned %v: %v", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("DELETE", "/ose/project/groups?clusterid=test&project=project&group=Operator&role=admin", nil)
+	removeProjectGroupHandler(c)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), common.ErrorCodeForbidden) {
+		t.Errorf("ERROR: the operator group should not be removed, but returned %v: %v", w.Code, w.Body.String())
+	}
+}
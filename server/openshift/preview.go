@@ -0,0 +1,81 @@
+package openshift
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProjectPreview is the ProjectRequest of a new project and the metadata which is written after the creation
+type ProjectPreview struct {
+	ProjectRequest interface{} `json:"projectRequest"`
+	// Annotations of the namespace, e.g. billing, requester and node selector
+	Annotations      map[string]interface{} `json:"annotations"`
+	Requester        string                 `json:"requester"`
+	ApprovalRequired bool                   `json:"approvalRequired"`
+	// Warnings which wouldn't block the project, like in the response of /ose/project
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// previewProjectHandler validates the command like /ose/project and returns the ProjectRequest
+// without creating the project. The new project webhook is not called.
+func previewProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.NewProjectCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	requester, warnings, ok := validateNewProjectCommand(c, &data, username)
+	if !ok {
+		return
+	}
+
+	cluster, err := getOpenshiftCluster(data.ClusterId)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	preview, err := previewProject(cluster, data, requester)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		common.RespondError(c, http.StatusBadRequest, errors.New(common.ConfigNotSetError))
+		return
+	}
+	preview.Warnings = warnings
+	c.JSON(http.StatusOK, preview)
+}
+
+// previewProject builds the ProjectRequest like createNewProject and the annotations like createOrUpdateMetadata
+func previewProject(cluster OpenshiftCluster, data common.NewProjectCommand, requester string) (*ProjectPreview, error) {
+	project := strings.ToLower(data.Project)
+	p, err := newProjectRequest(cluster, project)
+	if err != nil {
+		return nil, err
+	}
+
+	namespace := gabs.New()
+	setProjectMetadata(namespace, data.Billing, data.MegaId, data.OwnerGroup, data.Classification, requester, false)
+	if nodeSelector := projectNodeSelector(cluster, p, data.NodeSelector); nodeSelector != "" {
+		namespace.Set(nodeSelector, "metadata", "annotations", nodeSelectorAnnotation)
+	}
+	annotations := map[string]interface{}{}
+	for key, value := range namespace.Path("metadata.annotations").ChildrenMap() {
+		annotations[key] = value.Data()
+	}
+
+	return &ProjectPreview{
+		ProjectRequest:   p.Data(),
+		Annotations:      annotations,
+		Requester:        requester,
+		ApprovalRequired: cluster.ApprovalRequired,
+	}, nil
+}
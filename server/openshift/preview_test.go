@@ -0,0 +1,51 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestPreviewProject(t *testing.T) {
+	config.Init("bla")
+	cluster := OpenshiftCluster{
+		ID:                     "test",
+		ProjectRequestTemplate: `{"metadata": {"labels": {"team": "a"}}, "description": "template"}`,
+		DefaultNodeSelector:    "zone=prod",
+	}
+	data := common.NewProjectCommand{
+		OpenshiftBase:  common.OpenshiftBase{ClusterId: "test", Project: "My-Project"},
+		Billing:        "5678",
+		MegaId:         "1234",
+		Classification: "internal",
+	}
+
+	preview, err := previewProject(cluster, data, "u123456")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	p, ok := preview.ProjectRequest.(map[string]interface{})
+	if !ok || p["kind"] != "ProjectRequest" || p["description"] != "template" {
+		t.Errorf("ERROR: unexpected ProjectRequest: %v", preview.ProjectRequest)
+	}
+	if metadata, _ := p["metadata"].(map[string]interface{}); metadata["name"] != "my-project" {
+		t.Errorf("ERROR: the project name should be lowercase, got: %v", p["metadata"])
+	}
+	keys := getAnnotationKeys()
+	expected := map[string]string{
+		keys.Billing:                 "5678",
+		keys.Requester:               "u123456",
+		"openshift.io/MEGAID":        "1234",
+		dataClassificationAnnotation: "internal",
+		nodeSelectorAnnotation:       "zone=prod",
+	}
+	for key, value := range expected {
+		if preview.Annotations[key] != value {
+			t.Errorf("ERROR: annotation %v should be %v, got: %v", key, value, preview.Annotations[key])
+		}
+	}
+	if _, ok := preview.Annotations[billingHistoryAnnotation]; !ok {
+		t.Error("ERROR: the billing history should be in the preview")
+	}
+}
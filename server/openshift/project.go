@@ -2,21 +2,27 @@ package openshift
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"fmt"
 
-	"crypto/tls"
 	"os"
 
 	"github.com/Jeffail/gabs/v2"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/publisher"
 	"github.com/gin-gonic/gin"
 	"gopkg.in/gomail.v2"
 )
@@ -26,26 +32,163 @@ func newProjectHandler(c *gin.Context) {
 
 	var data common.NewProjectCommand
 	if c.BindJSON(&data) == nil {
-		if err := validateNewProject(data.Project, data.Billing, false); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		requester, warnings, ok := validateNewProjectCommand(c, &data, username)
+		if !ok {
 			return
 		}
 
-		if err := createNewProject(data.ClusterId, data.Project, username, data.Billing, data.MegaId, false); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
-		} else {
-			err := sendNewProjectMail(data.ClusterId, data.Project, username, data.MegaId)
-			if err != nil {
-				log.Printf("Can't send e-mail about new project (%v) on cluster %v.", err, data.ClusterId)
+		if err := validateNewProjectWebhook(data, username, requester); err != nil {
+			status := http.StatusForbidden
+			if apiErr, ok := err.(*common.ApiError); ok && apiErr.Code == common.ErrorCodeBackendError {
+				status = http.StatusBadGateway
 			}
+			common.RespondError(c, status, err)
+			return
+		}
 
-			c.JSON(http.StatusOK, common.ApiResponse{
-				Message: fmt.Sprintf("Das Projekt %v wurde erstellt auf Cluster %v", data.Project, data.ClusterId),
+		cluster, err := getOpenshiftCluster(data.ClusterId)
+		if err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+		if cluster.ApprovalRequired {
+			if err := requestProjectApproval(data, username, requester, common.GetUserMail(c)); err != nil {
+				common.RespondError(c, http.StatusBadRequest, err)
+				return
+			}
+			c.JSON(http.StatusAccepted, common.ApiResponse{
+				Message:  common.T(c, common.MsgProjectPending, data.Project, data.ClusterId),
+				Warnings: warnings,
 			})
+			return
 		}
+
+		message, err := createProjectFromCommand(c, data, username, requester)
+		if err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+		c.JSON(http.StatusOK, common.ApiResponse{
+			Message:  message,
+			Warnings: warnings,
+		})
 	} else {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+	}
+}
+
+// validateNewProjectCommand checks the command of a new project and responds with the error if it is invalid.
+// It returns the user who requests the project and the warnings, which don't block the project.
+func validateNewProjectCommand(c *gin.Context, data *common.NewProjectCommand, username string) (string, []string, bool) {
+	if err := validateOperatorCount(data.Operators); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	fields := projectFields{Billing: data.Billing, MegaId: data.MegaId, OwnerGroup: data.OwnerGroup, Classification: data.Classification}
+	warnings, err := validateNewProject(data.Project, fields, false)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := validateClusterAccess(username, data.ClusterId); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return "", nil, false
+	}
+
+	operators, err := sanitizeUsernames(data.Operators)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+	data.Operators = operators
+	if data.OnBehalfOf != "" {
+		if data.OnBehalfOf, err = sanitizeUsername(data.OnBehalfOf); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return "", nil, false
+		}
+	}
+
+	if err := validateLdapUsers(data.Operators); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := validateOwnerGroup(data.OwnerGroup); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	// checked before the project is created, the metadata is written afterwards
+	if err := validateAnnotationValues(map[string]string{
+		getAnnotationKeys().Billing: data.Billing,
+		"openshift.io/MEGAID":       data.MegaId,
+		"openshift.io/owner-group":  data.OwnerGroup,
+	}); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := validateProjectTemplate(data.Template); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := validateQuotaTier(username, data.QuotaTier); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	if err := validateNodeSelectorOverride(username, data.NodeSelector); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
+	}
+
+	requester, err := getProjectRequester(username, data.OnBehalfOf)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return "", nil, false
 	}
+
+	if err := checkProjectLimit(data.ClusterId, requester); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return "", nil, false
+	}
+	return requester, append(warnings, similarProjectNameWarnings(data.ClusterId, data.Project)...), true
+}
+
+// createProjectFromCommand creates the project of a validated NewProjectCommand and applies
+// the quota tier and the template. It returns the message for the user.
+func createProjectFromCommand(c *gin.Context, data common.NewProjectCommand, username, requester string) (string, error) {
+	if err := createNewProject(data.ClusterId, data.Project, requester, data.Billing, data.MegaId, data.OwnerGroup, data.Classification, data.Operators, data.NodeSelector, false); err != nil {
+		return "", err
+	}
+	if requester != username {
+		auditProjectOnBehalfOf(data.ClusterId, data.Project, username, requester)
+	}
+	publisher.Publish(publisher.ProjectCreated, data.ClusterId, strings.ToLower(data.Project), username)
+
+	err := sendNewProjectMail(data.ClusterId, data.Project, requester, data.MegaId)
+	if err != nil {
+		log.Printf("Can't send e-mail about new project (%v) on cluster %v.", err, data.ClusterId)
+	}
+
+	message := common.T(c, common.MsgProjectCreated, data.Project, data.ClusterId)
+	if data.QuotaTier != "" {
+		if err := applyQuotaTier(data.ClusterId, username, strings.ToLower(data.Project), data.QuotaTier); err != nil {
+			message += common.T(c, common.MsgQuotaTierFailed, data.QuotaTier, err.Error())
+		}
+	}
+	if data.Template != "" {
+		failed, err := applyProjectTemplate(data.ClusterId, strings.ToLower(data.Project), data.Template)
+		if err != nil {
+			message += common.T(c, common.MsgTemplateFailed, data.Template, err.Error())
+		} else if len(failed) > 0 {
+			message += common.T(c, common.MsgTemplateObjectsFailed, strings.Join(failed, ", "))
+		}
+	}
+	return message, nil
 }
 
 func newTestProjectHandler(c *gin.Context) {
@@ -55,22 +198,33 @@ func newTestProjectHandler(c *gin.Context) {
 	if c.BindJSON(&data) == nil {
 		// Special values for a test project
 		billing := "keine-verrechnung"
-		data.Project = username + "-" + data.Project
+		project, err := getTestProjectName(username, data.Project)
+		if err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+		data.Project = project
 
-		if err := validateNewProject(data.Project, billing, true); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		if _, err := validateNewProject(data.Project, projectFields{Billing: billing}, true); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := createNewProject(data.ClusterId, data.Project, username, billing, "", true); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		if err := validateClusterAccess(username, data.ClusterId); err != nil {
+			common.RespondError(c, http.StatusForbidden, err)
+			return
+		}
+
+		if err := createNewProject(data.ClusterId, data.Project, username, billing, "", "", "", nil, "", true); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
 		} else {
+			publisher.Publish(publisher.ProjectCreated, data.ClusterId, data.Project, username)
 			c.JSON(http.StatusOK, common.ApiResponse{
-				Message: fmt.Sprintf("Das Test-Projekt %v wurde erstellt auf Cluster %v", data.Project, data.ClusterId),
+				Message: common.T(c, common.MsgTestProjectCreated, data.Project, data.ClusterId),
 			})
 		}
 	} else {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
 	}
 }
 
@@ -79,26 +233,56 @@ func getProjectsHandler(c *gin.Context) {
 	params := c.Request.URL.Query()
 	clusterId := params.Get("clusterid")
 	if clusterId == "" {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
 		return
 	}
 	log.Printf("%v has queried all his projects in clusterid: %v", username, clusterId)
 	projects, err := getProjects(clusterId, username)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 	filteredProjects := filterProjects(projects, params)
+	if detailed, _ := strconv.ParseBool(params.Get("detailed")); detailed {
+		c.JSON(http.StatusOK, getProjectSummaries(filteredProjects))
+		return
+	}
 	c.JSON(http.StatusOK, getProjectNames(filteredProjects))
 }
 
+// ProjectSummary is returned by the project list with detailed=true
+type ProjectSummary struct {
+	Name              string `json:"name"`
+	CreationTimestamp string `json:"creationTimestamp"`
+	// Active or Terminating. Empty if OpenShift didn't return a phase
+	Phase string `json:"phase"`
+}
+
+func getProjectSummaries(projects *gabs.Container) []ProjectSummary {
+	summaries := []ProjectSummary{}
+	for _, project := range projects.Children() {
+		name, ok := project.Path("metadata.name").Data().(string)
+		if !ok {
+			continue
+		}
+		creationTimestamp, _ := project.Path("metadata.creationTimestamp").Data().(string)
+		phase, _ := project.Path("status.phase").Data().(string)
+		summaries = append(summaries, ProjectSummary{
+			Name:              name,
+			CreationTimestamp: creationTimestamp,
+			Phase:             phase,
+		})
+	}
+	return summaries
+}
+
 // generic filter for projects
 // this is used by ESTA
 func filterProjects(projects *gabs.Container, params url.Values) *gabs.Container {
 	filtered, _ := gabs.New().Array()
 	// possible filters:
 	var filterMap = map[string]string{
-		"sbb_accounting_number": "openshift.io/kontierung-element",
+		"sbb_accounting_number": getAnnotationKeys().Billing,
 		"sbb_mega_id":           "openshift.io/MEGAID"}
 	// "filters" is a map containing only the parameters with valid
 	// filter names
@@ -163,15 +347,58 @@ func getProjects(clusterid, username string) (*gabs.Container, error) {
 
 	defer resp.Body.Close()
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Println("error decoding json:", err, resp.StatusCode)
-		return nil, errors.New(genericAPIError)
+		return nil, err
 	}
 	projects := json.Search("items")
 	return projects, nil
 }
 
+// checkProjectLimit checks if the user has reached the max_projects_per_user limit.
+// Members of the power_user_group have the limit max_projects_per_power_user instead.
+// A limit of 0 means unlimited.
+func checkProjectLimit(clusterId, username string) error {
+	cfg := config.Config()
+	limit := cfg.GetInt("max_projects_per_user")
+	if powerUserGroup := cfg.GetString("power_user_group"); powerUserGroup != "" {
+		isPowerUser, err := isInLdapGroup(username, powerUserGroup)
+		if err != nil {
+			return err
+		}
+		if isPowerUser {
+			limit = cfg.GetInt("max_projects_per_power_user")
+		}
+	}
+	if limit <= 0 {
+		return nil
+	}
+
+	projects, err := getProjects(clusterId, username)
+	if err != nil {
+		return err
+	}
+	count := countRequestedProjects(projects, username)
+	if count >= limit {
+		log.Printf("%v has reached the project limit on cluster %v (%v of %v)", username, clusterId, count, limit)
+		return common.NewApiError(common.ErrorCodeForbidden,
+			fmt.Sprintf("Du hast bereits %v von maximal %v Projekten auf diesem Cluster", count, limit))
+	}
+	return nil
+}
+
+// countRequestedProjects counts the projects which were requested by the user
+func countRequestedProjects(projects *gabs.Container, username string) int {
+	count := 0
+	for _, project := range projects.Children() {
+		requester := projectAnnotation([]*gabs.Container{project}, getAnnotationKeys().Requester, "openshift.io/requester")
+		if strings.EqualFold(requester, username) {
+			count++
+		}
+	}
+	return count
+}
+
 func getProjectAdminsHandler(c *gin.Context) {
 	username := common.GetUserName(c)
 
@@ -180,14 +407,14 @@ func getProjectAdminsHandler(c *gin.Context) {
 	project := params.Get("project")
 
 	if clusterId == "" || project == "" {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
 		return
 	}
 
 	log.Printf("%v has queried all the admins of project %v on cluster %v", username, project, clusterId)
 
 	if admins, _, err := getProjectAdminsAndOperators(clusterId, project); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 	} else {
 		c.JSON(http.StatusOK, common.AdminList{
 			Admins: admins,
@@ -195,6 +422,60 @@ func getProjectAdminsHandler(c *gin.Context) {
 	}
 }
 
+func getProjectRoleBindingsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	params := c.Request.URL.Query()
+	clusterId := params.Get("clusterid")
+	project := params.Get("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.Printf("%v has queried all the rolebindings of project %v on cluster %v", username, project, clusterId)
+
+	json, err := getRoleBindings(clusterId, project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, normalizeRoleBindings(json))
+}
+
+// normalizeRoleBindings flattens the subjects of all rolebindings into users and groups.
+// Service accounts are returned as users with their full name (system:serviceaccount:<namespace>:<name>)
+func normalizeRoleBindings(json *gabs.Container) []RoleBinding {
+	roleBindings := []RoleBinding{}
+	for _, rb := range json.S("items").Children() {
+		name, _ := rb.Path("metadata.name").Data().(string)
+		role, _ := rb.Path("roleRef.name").Data().(string)
+		users := []string{}
+		groups := []string{}
+		for _, subject := range rb.S("subjects").Children() {
+			subjectName, _ := subject.S("name").Data().(string)
+			switch subject.S("kind").Data() {
+			case "Group":
+				groups = append(groups, subjectName)
+			case "ServiceAccount":
+				namespace, _ := subject.S("namespace").Data().(string)
+				users = append(users, "system:serviceaccount:"+namespace+":"+subjectName)
+			default:
+				users = append(users, strings.ToLower(subjectName))
+			}
+		}
+		roleBindings = append(roleBindings, RoleBinding{
+			Name:   name,
+			Role:   role,
+			Users:  common.RemoveDuplicates(users),
+			Groups: common.RemoveDuplicates(groups),
+		})
+	}
+	return roleBindings
+}
+
 func getProjectInformationHandler(c *gin.Context) {
 	username := common.GetUserName(c)
 
@@ -203,38 +484,170 @@ func getProjectInformationHandler(c *gin.Context) {
 	project := params.Get("project")
 
 	if err := validateAdminAccess(clusterId, username, project); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	pi, err := getProjectInformation(clusterId, project)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
 	}
 
+	if pi.ResourceVersion != "" {
+		c.Header("ETag", `"`+pi.ResourceVersion+`"`)
+	}
 	c.JSON(http.StatusOK, pi)
 }
 
+// Maximum number of projects in a batch request
+const maxProjectInformationBatch = 100
+
+// ProjectInformationResult is the result for a project of a batch request.
+// Error is set, if the user has no access or the information could not be read.
+type ProjectInformationResult struct {
+	ClusterId   string              `json:"clusterid"`
+	Project     string              `json:"project"`
+	Information *ProjectInformation `json:"information,omitempty"`
+	Error       string              `json:"error,omitempty"`
+	Code        string              `json:"code,omitempty"`
+}
+
+// getProjectInformationBatchHandler returns the project information of multiple projects.
+// Projects without admin access return an error instead of failing the whole request.
+func getProjectInformationBatchHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectInformationBatchCommand
+	if c.BindJSON(&data) != nil || len(data.Projects) == 0 {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if len(data.Projects) > maxProjectInformationBatch {
+		c.JSON(http.StatusUnprocessableEntity, common.ApiResponse{
+			Message: common.T(c, common.MsgTooManyProjects, maxProjectInformationBatch),
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, getProjectInformationBatch(username, data.Projects))
+}
+
+func getProjectInformationBatch(username string, projects []common.OpenshiftBase) []ProjectInformationResult {
+	// The clusters are resolved before the workers start, so they don't read the config concurrently
+	clients := map[string]*oseClient{}
+	clientErrors := map[string]error{}
+	for _, p := range projects {
+		if _, ok := clients[p.ClusterId]; ok || p.ClusterId == "" {
+			continue
+		}
+		clients[p.ClusterId], clientErrors[p.ClusterId] = newOseClient(p.ClusterId)
+	}
+
+	results := make([]ProjectInformationResult, len(projects))
+	// limit the number of parallel requests to the OpenShift API
+	common.Parallel(len(projects), maxParallelAdminChecks, nil, func(i int) {
+		p := projects[i]
+		result := ProjectInformationResult{ClusterId: p.ClusterId, Project: p.Project}
+		err := validateProjectReference(p.ClusterId, p.Project)
+		if err == nil {
+			err = clientErrors[p.ClusterId]
+		}
+		if err == nil {
+			err = clients[p.ClusterId].checkAdminPermissions(username, p.Project)
+		}
+		if err == nil {
+			result.Information, err = clients[p.ClusterId].getProjectInformation(p.Project)
+		}
+		if err != nil {
+			response := common.ErrorResponse(err)
+			result.Error = response.Message
+			result.Code = response.Code
+		}
+		// every call writes its own index
+		results[i] = result
+	})
+	return results
+}
+
 func updateProjectInformationHandler(c *gin.Context) {
 	username := common.GetUserName(c)
 
 	var data common.UpdateProjectInformationCommand
 	if c.BindJSON(&data) == nil {
+		// The permissions are checked first, so that the current information isn't read for other users
+		if err := validateProjectPermissions(data.ClusterId, username, data.Project); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		// Fields which are not sent keep their current value
+		current, err := getProjectInformation(data.ClusterId, data.Project)
+		if err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
+			return
+		}
+		// Without If-Match, the last write wins
+		resourceVersion := ""
+		if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+			if !matchesETag(ifMatch, current.ResourceVersion) {
+				common.RespondError(c, http.StatusPreconditionFailed, errProjectChanged())
+				return
+			}
+			resourceVersion = current.ResourceVersion
+		}
+		data = mergeProjectInformation(data, current)
+
 		if err := validateProjectInformation(data, username); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := createOrUpdateMetadata(data.ClusterId, data.Project, data.Billing, data.MegaID, username, false); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		if err := validateClusterAccess(username, data.ClusterId); err != nil {
+			common.RespondError(c, http.StatusForbidden, err)
+			return
+		}
+
+		if err := updateMetadataIfMatch(data.ClusterId, data.Project, resourceVersion, data.Billing, data.MegaID, data.OwnerGroup, data.Classification, username); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
 		} else {
+			publisher.Publish(publisher.ProjectUpdated, data.ClusterId, data.Project, username)
 			c.JSON(http.StatusOK, common.ApiResponse{
-				Message: fmt.Sprintf("The details for project %v on cluster %v has been saved", data.Project, data.ClusterId),
+				Message: common.T(c, common.MsgProjectInformationSaved, data.Project, data.ClusterId),
 			})
 		}
 	} else {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+	}
+}
+
+// updateProjectDisplayNameHandler only changes the display name of a project.
+// Namespaces can't be renamed, but the display name annotation can.
+func updateProjectDisplayNameHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.UpdateProjectDisplayNameCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
 	}
+	if err := validateProjectDisplayName(data, username); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateClusterAccess(username, data.ClusterId); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+	if err := updateProjectDisplayName(data.ClusterId, data.Project, strings.TrimSpace(data.DisplayName), username); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	publisher.Publish(publisher.ProjectUpdated, data.ClusterId, data.Project, username)
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: common.T(c, common.MsgProjectDisplayNameSaved, data.Project, data.ClusterId),
+	})
 }
 
 // Used by ESTA frontend
@@ -243,58 +656,201 @@ func addProjectAdminHandler(c *gin.Context) {
 
 	var data common.AddProjectAdminCommand
 	if c.BindJSON(&data) != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
 	}
 
 	if data.ClusterId == "" {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "ClusterId must be provided"})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: common.T(c, common.MsgClusterIdMissing), Code: common.ErrorCodeInvalidRequest})
 		return
 	}
 
 	if data.Project == "" {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "Project must be provided"})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: common.T(c, common.MsgProjectMissing), Code: common.ErrorCodeInvalidRequest})
 		return
 	}
 
 	if data.Username == "" {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "Username must be provided"})
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: common.T(c, common.MsgUsernameMissing), Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	newAdmin, err := sanitizeUsername(data.Username)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
+	data.Username = newAdmin
 
 	// Validate permissions
 	if err := checkAdminPermissions(data.ClusterId, username, data.Project); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := changeProjectPermission(data.ClusterId, data.Project, data.Username); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 	c.JSON(http.StatusOK, common.ApiResponse{
-		Message: fmt.Sprintf("The user %v has been sucessfully added to the %v project", data.Username, data.Project),
+		Message: common.T(c, common.MsgProjectAdminAdded, data.Username, data.Project),
 	})
 }
 
-func validateNewProject(project string, billing string, testProject bool) error {
+// validateNewProject returns the warnings of the validators, e.g. an accounting number which is not active yet.
+// Warnings don't block the project.
+func validateNewProject(project string, fields projectFields, testProject bool) ([]string, error) {
 	if len(project) == 0 {
-		return errors.New("Project name has to be provided")
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, "Project name has to be provided")
 	}
 
-	if !testProject && len(billing) == 0 {
-		return errors.New("Accounting number must be provided")
+	if err := validateRequiredProjectFields(fields, testProject); err != nil {
+		return nil, err
 	}
 
+	if testProject {
+		return nil, nil
+	}
+
+	var warnings []string
+	if len(fields.Billing) > 0 {
+		billingWarnings, err := validateBilling(fields.Billing)
+		if err != nil {
+			return nil, err
+		}
+		warnings = append(warnings, billingWarnings...)
+	}
+
+	if len(fields.Classification) > 0 {
+		if err := validateClassification(fields.Classification); err != nil {
+			return nil, err
+		}
+	}
+	return warnings, nil
+}
+
+const (
+	// How long the project names of a cluster are reused to find similar names
+	projectNamesCacheTTL        = time.Minute
+	maxProjectNamesCacheEntries = 100
+)
+
+// The project names per cluster
+var projectNamesCache = common.NewCache(maxProjectNamesCacheEntries)
+
+// similarProjectNameWarnings warns if an existing project's name differs only by one character from the new one,
+// e.g. a typo of an existing project. The existing name is not returned, the user might not be allowed to see it.
+// The warning is skipped if the projects can't be listed.
+func similarProjectNameWarnings(clusterId, project string) []string {
+	names, err := getCachedProjectNames(clusterId)
+	if err != nil {
+		log.Printf("Can't list the projects of cluster %v to find similar names: %v", clusterId, err)
+		return nil
+	}
+	project = strings.ToLower(project)
+	for _, name := range names {
+		if name != project && editDistance(name, project) == 1 {
+			return []string{fmt.Sprintf("The project name %v is similar to an existing project. Please check that it isn't a typo", project)}
+		}
+	}
 	return nil
 }
 
-func validateAdminAccess(clusterId, username, project string) error {
-	if clusterId == "" {
-		return errors.New("Cluster must be provided")
+// getCachedProjectNames returns the project names of the cluster, listed at most once per projectNamesCacheTTL
+func getCachedProjectNames(clusterId string) ([]string, error) {
+	if names, ok := projectNamesCache.Get(clusterId); ok {
+		return names.([]string), nil
 	}
 
-	if project == "" {
-		return errors.New("Project name must be provided")
+	projects, err := getProjects(clusterId, "")
+	if err != nil {
+		return nil, err
+	}
+	names := getProjectNames(projects)
+	projectNamesCache.Set(clusterId, names, projectNamesCacheTTL)
+	return names, nil
+}
+
+// editDistance returns the Levenshtein distance of the strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = previous[j-1] + cost
+			if previous[j]+1 < current[j] {
+				current[j] = previous[j] + 1
+			}
+			if current[j-1]+1 < current[j] {
+				current[j] = current[j-1] + 1
+			}
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+var defaultDataClassifications = []string{"public", "internal", "confidential"}
+
+// validateClassification checks if the classification is one of data_classifications
+func validateClassification(classification string) error {
+	allowed := defaultDataClassifications
+	if config.Config().IsSet("data_classifications") {
+		allowed = config.Config().GetStringSlice("data_classifications")
+	}
+	for _, c := range allowed {
+		if c == classification {
+			return nil
+		}
+	}
+	return common.NewValidationError(common.ErrorCodeInvalidRequest,
+		fmt.Sprintf("Invalid data classification %v. Allowed values: %v", classification, strings.Join(allowed, ", ")))
+}
+
+const defaultTestProjectNameTemplate = "{{.User}}-{{.Name}}"
+
+// Project names must be valid DNS labels
+var projectNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// getTestProjectName returns the name of a test project from test_project_name_template.
+// The template can use {{.User}} and {{.Name}}.
+func getTestProjectName(username, name string) (string, error) {
+	if name == "" {
+		return "", common.NewApiError(common.ErrorCodeInvalidRequest, "Project name has to be provided")
+	}
+	nameTemplate := config.Config().GetString("test_project_name_template")
+	if nameTemplate == "" {
+		nameTemplate = defaultTestProjectNameTemplate
+	}
+	t, err := template.New("testproject").Parse(nameTemplate)
+	if err != nil {
+		log.Printf("WARNING: invalid test_project_name_template: %v", err)
+		return "", errors.New(common.ConfigNotSetError)
+	}
+	var project bytes.Buffer
+	data := struct{ User, Name string }{username, name}
+	if err := t.Execute(&project, data); err != nil {
+		log.Printf("WARNING: invalid test_project_name_template: %v", err)
+		return "", errors.New(common.ConfigNotSetError)
+	}
+
+	projectName := strings.ToLower(project.String())
+	if len(projectName) > 63 || !projectNameRegex.MatchString(projectName) {
+		return "", common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("Der Projektname %v ist ungültig. Erlaubt sind maximal 63 Kleinbuchstaben, Zahlen und '-'", projectName))
+	}
+	return projectName, nil
+}
+
+func validateAdminAccess(clusterId, username, project string) error {
+	if err := validateProjectReference(clusterId, project); err != nil {
+		return err
 	}
 
 	// Validate permissions
@@ -305,13 +861,25 @@ func validateAdminAccess(clusterId, username, project string) error {
 	return nil
 }
 
+// validateProjectReference checks that the cluster and the project are provided
+func validateProjectReference(clusterId, project string) error {
+	if clusterId == "" {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Cluster must be provided")
+	}
+
+	if project == "" {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Project name must be provided")
+	}
+	return nil
+}
+
 func validateProjectPermissions(clusterId, username, project string) error {
 	if clusterId == "" {
-		return errors.New("Cluster must be provided")
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Cluster must be provided")
 	}
 
 	if project == "" {
-		return errors.New("Project name must be provided")
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Project name must be provided")
 	}
 
 	// Allow functional account
@@ -334,48 +902,109 @@ func validateProjectPermissions(clusterId, username, project string) error {
 	return nil
 }
 
-func validateProjectInformation(data common.UpdateProjectInformationCommand, username string) error {
+func validateProjectDisplayName(data common.UpdateProjectDisplayNameCommand, username string) error {
 	if data.ClusterId == "" {
-		return errors.New("Cluster must be provided")
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Cluster must be provided")
 	}
 
 	if data.Project == "" {
-		return errors.New("Project name must be provided")
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Project name must be provided")
 	}
 
-	if data.Billing == "" {
-		return errors.New("Accounting number must be provided")
+	displayName := strings.TrimSpace(data.DisplayName)
+	if displayName == "" {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Display name must be provided")
+	}
+	if len(displayName) > maxDisplayNameLength {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("Display name must not be longer than %v characters", maxDisplayNameLength))
 	}
 
-	// Validate permissions
-	if err := validateProjectPermissions(data.ClusterId, username, data.Project); err != nil {
+	return validateProjectPermissions(data.ClusterId, username, data.Project)
+}
+
+// mergeProjectInformation fills the fields which are missing or empty in the command with
+// the current information of the project, so that a sparse update doesn't clear them
+func mergeProjectInformation(data common.UpdateProjectInformationCommand, current *ProjectInformation) common.UpdateProjectInformationCommand {
+	if strings.TrimSpace(data.Billing) == "" {
+		data.Billing = current.Kontierungsnummer
+	}
+	if strings.TrimSpace(data.MegaID) == "" {
+		data.MegaID = current.MegaID
+	}
+	if strings.TrimSpace(data.OwnerGroup) == "" {
+		data.OwnerGroup = current.OwnerGroup
+	}
+	if strings.TrimSpace(data.Classification) == "" {
+		data.Classification = current.Classification
+	}
+	return data
+}
+
+func validateProjectInformation(data common.UpdateProjectInformationCommand, username string) error {
+	if data.ClusterId == "" {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Cluster must be provided")
+	}
+
+	if data.Project == "" {
+		return common.NewApiError(common.ErrorCodeInvalidRequest, "Project name must be provided")
+	}
+
+	// The fields of the update are merged with the current values, so projects without the
+	// required fields can only be updated if the missing fields are sent
+	fields := projectFields{Billing: data.Billing, MegaId: data.MegaID, OwnerGroup: data.OwnerGroup, Classification: data.Classification}
+	if err := validateRequiredProjectFields(fields, false); err != nil {
 		return err
 	}
 
-	return nil
+	// Warnings are only shown when a project is created
+	if data.Billing != "" {
+		if _, err := validateBilling(data.Billing); err != nil {
+			return err
+		}
+	}
+
+	// An empty classification keeps the existing annotation
+	if data.Classification != "" {
+		if err := validateClassification(data.Classification); err != nil {
+			return err
+		}
+	}
+
+	return validateOwnerGroup(data.OwnerGroup)
 }
 
 func sendNewProjectMail(clusterId string, projectName string, userName string, megaID string) error {
-
-	mailServer, ok := os.LookupEnv("MAIL_SERVER")
-	if !ok {
-		return errors.New("Error looking up MAIL_SERVER from environment.")
+	mailer, err := getMailer()
+	if err != nil {
+		return err
 	}
 
 	fromMail, ok := os.LookupEnv("MAIL_ADMIN_SENDER")
 	if !ok {
 		return errors.New("Error looking up MAIL_ADMIN_SENDER from environment.")
 	}
+	from := parseMailAddresses(fromMail)
+	if len(from) != 1 {
+		return errors.New("MAIL_ADMIN_SENDER must be a valid e-mail address.")
+	}
 
 	newProjectMail, ok := os.LookupEnv("MAIL_NEW_PROJECT_RECIPIENT")
 	if !ok {
 		return errors.New("Error looking up MAIL_NEW_PROJECT_RECIPIENT from environment.")
 	}
+	to := parseMailAddresses(newProjectMail)
+	if len(to) == 0 {
+		return errors.New("MAIL_NEW_PROJECT_RECIPIENT contains no valid e-mail address.")
+	}
 
 	m := gomail.NewMessage()
-	m.SetHeader("From", fromMail)
+	m.SetHeader("From", from[0])
 
-	m.SetHeader("To", newProjectMail)
+	m.SetHeader("To", to...)
+	// optional
+	if cc := parseMailAddresses(os.Getenv("MAIL_NEW_PROJECT_CC")); len(cc) > 0 {
+		m.SetHeader("Cc", cc...)
+	}
 	m.SetHeader("Subject", fmt.Sprintf("New Project '%v' on OpenShift", projectName))
 
 	m.SetBody("text/html", fmt.Sprintf(`
@@ -393,21 +1022,26 @@ func sendNewProjectMail(clusterId string, projectName string, userName string, m
 	IT-OM-SDL-CLP
 	`, clusterId, projectName, userName, megaID))
 
-	d := gomail.Dialer{Host: mailServer, Port: 25}
-	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
-	err := d.DialAndSend(m)
+	return mailer.Send(m)
+}
 
+func createNewProject(clusterId string, project string, username string, billing string, megaid string, ownerGroup string, classification string, operators []string, nodeSelector string, testProject bool) error {
+	project = strings.ToLower(project)
+	cluster, err := getOpenshiftCluster(clusterId)
 	if err != nil {
 		return err
 	}
-
-	return nil
+	p, err := newProjectRequest(cluster, project)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		return errors.New(common.ConfigNotSetError)
+	}
+	nodeSelector = projectNodeSelector(cluster, p, nodeSelector)
+	return submitProjectRequest(clusterId, project, p, username, billing, megaid, ownerGroup, classification, operators, nodeSelector, testProject)
 }
 
-func createNewProject(clusterId string, project string, username string, billing string, megaid string, testProject bool) error {
-	project = strings.ToLower(project)
-	p := newObjectRequest("ProjectRequest", project, "project.openshift.io/v1")
-
+// submitProjectRequest posts the ProjectRequest and sets the permissions, metadata, node selector and pull secret of the new project
+func submitProjectRequest(clusterId string, project string, p *gabs.Container, username string, billing string, megaid string, ownerGroup string, classification string, operators []string, nodeSelector string, testProject bool) error {
 	resp, err := getOseHTTPClient("POST", clusterId, "apis/project.openshift.io/v1/projectrequests", bytes.NewReader(p.Bytes()))
 	if err != nil {
 		return err
@@ -421,135 +1055,438 @@ func createNewProject(clusterId string, project string, username string, billing
 			return err
 		}
 
-		if err := createOrUpdateMetadata(clusterId, project, billing, megaid, username, testProject); err != nil {
+		if err := addProjectOperators(clusterId, project, operators); err != nil {
 			return err
 		}
+
+		if err := createOrUpdateMetadata(clusterId, project, billing, megaid, ownerGroup, classification, username, testProject); err != nil {
+			return err
+		}
+
+		if err := setProjectNodeSelector(clusterId, project, nodeSelector); err != nil {
+			return err
+		}
+
+		// The project is usable without the pull secret, so the creation doesn't fail because of it
+		if err := createProjectPullSecret(clusterId, project); err != nil {
+			log.Printf("WARNING: Pull secret couldn't be created in project %v on cluster %v: %v", project, clusterId, err)
+		}
 		return nil
 	}
 	if resp.StatusCode == http.StatusConflict {
-		return errors.New("The project already exists")
+		return common.NewApiError(common.ErrorCodeProjectExists, "The project already exists")
 	}
 
 	errMsg, _ := ioutil.ReadAll(resp.Body)
 	log.Println("Error creating new project:", err, resp.StatusCode, string(errMsg))
 
-	return errors.New(genericAPIError)
+	return newUpstreamError("POST", resp.StatusCode, errMsg)
 }
 
 func changeProjectPermission(clusterId string, project string, username string) error {
-	adminRoleBinding, err := getAdminRoleBinding(clusterId, project)
+	username, err := sanitizeUsername(username)
 	if err != nil {
 		return err
 	}
+	return retryOnConflict(func() error {
+		adminRoleBinding, err := getAdminRoleBinding(clusterId, project)
+		if err != nil {
+			return err
+		}
 
-	current_user_low := OpenshiftSubject{
-		ApiGroup: "rbac.authorization.k8s.io",
-		Kind:     "User",
-		Name:     strings.ToLower(username),
-	}
-	current_user_up := OpenshiftSubject{
-		ApiGroup: "rbac.authorization.k8s.io",
-		Kind:     "User",
-		Name:     strings.ToUpper(username),
-	}
+		appendUserSubjects(adminRoleBinding, []string{username})
 
-	adminRoleBinding.ArrayAppend(current_user_low, "subjects")
-	adminRoleBinding.ArrayAppend(current_user_up, "subjects")
+		// Update the policyBindings on the api
+		resp, err := getOseHTTPClient("PUT",
+			clusterId,
+			"apis/rbac.authorization.k8s.io/v1/namespaces/"+project+"/rolebindings/admin",
+			bytes.NewReader(adminRoleBinding.Bytes()))
+		if err != nil {
+			return err
+		}
 
-	// Update the policyBindings on the api
-	resp, err := getOseHTTPClient("PUT",
-		clusterId,
-		"apis/rbac.authorization.k8s.io/v1/namespaces/"+project+"/rolebindings/admin",
-		bytes.NewReader(adminRoleBinding.Bytes()))
-	if err != nil {
-		return err
-	}
+		defer resp.Body.Close()
 
-	defer resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			log.Print(username + " is now admin of " + project)
+			return nil
+		}
 
-	if resp.StatusCode == http.StatusOK {
-		log.Print(username + " is now admin of " + project)
-		return nil
-	}
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		log.Println("Error updating project permissions:", err, resp.StatusCode, string(errMsg))
+		return newUpstreamError("PUT", resp.StatusCode, errMsg)
+	})
+}
 
-	errMsg, _ := ioutil.ReadAll(resp.Body)
-	log.Println("Error updating project permissions:", err, resp.StatusCode, string(errMsg))
-	return errors.New(genericAPIError)
+// AnnotationKeys are the names of the project annotations. They can be changed
+// with openshift_annotations in the config, if a cluster policy requires other names
+type AnnotationKeys struct {
+	Requester string `mapstructure:"requester"`
+	Billing   string `mapstructure:"billing"`
+}
+
+var (
+	annotationKeys     AnnotationKeys
+	annotationKeysOnce sync.Once
+)
+
+// getAnnotationKeys reads the annotation keys once from the config
+func getAnnotationKeys() AnnotationKeys {
+	annotationKeysOnce.Do(func() {
+		annotationKeys = AnnotationKeys{
+			Requester: "openshift.io/requester",
+			Billing:   "openshift.io/kontierung-element",
+		}
+		if cfg := config.Config(); cfg != nil {
+			if err := config.UnmarshalKey("openshift_annotations", &annotationKeys); err != nil {
+				log.Printf("WARNING: invalid openshift_annotations config: %v", err)
+			}
+		}
+	})
+	return annotationKeys
 }
 
+const dataClassificationAnnotation = "openshift.io/data-classification"
+
+const (
+	displayNameAnnotation = "openshift.io/display-name"
+	maxDisplayNameLength  = 200
+)
+
 type ProjectInformation struct {
 	Kontierungsnummer string `json:"kontierungsnummer"`
 	MegaID            string `json:"megaid"`
+	Requester         string `json:"requester,omitempty"`
+	OwnerGroup        string `json:"ownerGroup,omitempty"`
+	Classification    string `json:"classification,omitempty"`
+	// Only set for test projects
+	IsTestProject bool   `json:"isTestProject,omitempty"`
+	DeletionDate  string `json:"deletionDate,omitempty"`
+	// Only set if the cmdb is configured and knows the project
+	Ownership *ProjectOwnership `json:"ownership,omitempty"`
+	// resourceVersion of the namespace, returned as ETag
+	ResourceVersion string `json:"-"`
 }
 
+// matchesETag checks if one of the ETags of an If-Match header is the resourceVersion
+func matchesETag(ifMatch, resourceVersion string) bool {
+	for _, etag := range strings.Split(ifMatch, ",") {
+		etag = strings.TrimSpace(etag)
+		if etag == "*" {
+			return true
+		}
+		etag = strings.Trim(strings.TrimPrefix(etag, "W/"), `"`)
+		if etag != "" && etag == resourceVersion {
+			return true
+		}
+	}
+	return false
+}
+
+// getProjectInformation reads the project information from the annotations and adds the ownership from the CMDB
 func getProjectInformation(clusterId, project string) (*ProjectInformation, error) {
-	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+	client, err := newOseClient(clusterId)
 	if err != nil {
 		return nil, err
 	}
+	return client.getProjectInformation(project)
+}
 
-	defer resp.Body.Close()
+func (o *oseClient) getProjectInformation(project string) (*ProjectInformation, error) {
+	pi, err := o.readProjectInformation(project)
+	if err != nil {
+		return nil, err
+	}
+	pi.Ownership = getProjectOwnership(project, pi.MegaID)
+	return pi, nil
+}
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+// readProjectInformation reads the annotations from the namespace. If the service account can't read the
+// namespace or the billing is missing, the Project object is read as well, because depending on the
+// version of OpenShift the annotations are only visible on one of them.
+func (o *oseClient) readProjectInformation(project string) (*ProjectInformation, error) {
+	var objects []*gabs.Container
+	namespace, err := o.getProjectObject("api/v1/namespaces/" + project)
 	if err != nil {
-		log.Println("error decoding json:", err, resp.StatusCode)
-		return nil, errors.New(genericAPIError)
+		return nil, err
+	}
+	if namespace != nil {
+		objects = append(objects, namespace)
+		if pi := parseProjectInformation(namespace); pi.Kontierungsnummer != "" {
+			return pi, nil
+		}
 	}
 
-	billing := json.Path("metadata.annotations").S("openshift.io/kontierung-element").Data()
-	if billing == nil {
-		billing = ""
+	projectObject, err := o.getProjectObject("apis/project.openshift.io/v1/projects/" + project)
+	if err != nil {
+		return nil, err
+	}
+	if projectObject != nil {
+		objects = append(objects, projectObject)
 	}
-	megaid := json.Path("metadata.annotations").S("openshift.io/MEGAID").Data()
-	if megaid == nil {
-		megaid = ""
+	if len(objects) == 0 {
+		return nil, common.NewApiError(common.ErrorCodeProjectNotFound, fmt.Sprintf("The project %v doesn't exist", project))
 	}
-	return &ProjectInformation{
-		Kontierungsnummer: billing.(string),
-		MegaID:            megaid.(string),
-	}, nil
+	return parseProjectInformation(objects...), nil
 }
 
-func createOrUpdateMetadata(clusterId, project string, billing string, megaid string, username string, testProject bool) error {
-	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+// getProjectObject returns nil if the object doesn't exist or can't be read
+func (o *oseClient) getProjectObject(url string) (*gabs.Container, error) {
+	resp, err := o.request(context.Background(), "GET", url, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
 	defer resp.Body.Close()
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, nil
+	}
+	return parseJSONResponse(resp)
+}
+
+// projectAnnotation returns the first annotation of the objects with one of the keys.
+// The keys are compared case-insensitive, because older projects have e.g. openshift.io/megaid.
+func projectAnnotation(objects []*gabs.Container, keys ...string) string {
+	for _, object := range objects {
+		annotations := object.Path("metadata.annotations").ChildrenMap()
+		for _, key := range keys {
+			if value, ok := annotations[key].Data().(string); ok && value != "" {
+				return value
+			}
+		}
+		for _, key := range keys {
+			for name, value := range annotations {
+				if s, ok := value.Data().(string); ok && s != "" && strings.EqualFold(name, key) {
+					return s
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// parseProjectInformation reads the annotations of the namespace and the project.
+// The metadata is taken from the first object.
+func parseProjectInformation(objects ...*gabs.Container) *ProjectInformation {
+	json := objects[0]
+	// the annotations may be missing completely, this results in empty strings
+	resourceVersion, _ := json.Path("metadata.resourceVersion").Data().(string)
+	pi := &ProjectInformation{
+		Kontierungsnummer: projectAnnotation(objects, getAnnotationKeys().Billing, "openshift.io/kontierung-element"),
+		MegaID:            projectAnnotation(objects, "openshift.io/MEGAID"),
+		Requester:         projectAnnotation(objects, getAnnotationKeys().Requester, "openshift.io/requester"),
+		OwnerGroup:        projectAnnotation(objects, "openshift.io/owner-group"),
+		Classification:    projectAnnotation(objects, dataClassificationAnnotation),
+		ResourceVersion:   resourceVersion,
+	}
+
+	if projectAnnotation(objects, testProjectDaysAnnotation) == "" {
+		return pi
+	}
+	pi.IsTestProject = true
+	deletion, err := testProjectDeletionDate(objects...)
 	if err != nil {
-		log.Println("error decoding json:", err, resp.StatusCode)
-		return errors.New(genericAPIError)
+		log.Printf("%v", err)
+		return pi
 	}
+	pi.DeletionDate = deletion.Format("2006-01-02")
+	return pi
+}
 
+// testProjectDeletionDate returns the creation of the test project plus the days of openshift.io/testproject-daystodeletion.
+// Like the other annotations, the days and the creation are read from the first of the objects which has them.
+func testProjectDeletionDate(objects ...*gabs.Container) (time.Time, error) {
+	daysToDeletion := projectAnnotation(objects, testProjectDaysAnnotation)
+	days, err := strconv.Atoi(daysToDeletion)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid testproject-daystodeletion annotation: %v", daysToDeletion)
+	}
+	creationTimestamp := ""
+	for _, object := range objects {
+		if creationTimestamp, _ = object.Path("metadata.creationTimestamp").Data().(string); creationTimestamp != "" {
+			break
+		}
+	}
+	created, err := time.Parse(time.RFC3339, creationTimestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid creationTimestamp: %v", err)
+	}
+	return created.AddDate(0, 0, days), nil
+}
+
+func setProjectMetadata(json *gabs.Container, billing string, megaid string, ownerGroup string, classification string, username string, testProject bool) {
+	// Namespaces created outside of the SSP may not have any annotations
+	if !json.ExistsP("metadata.annotations") {
+		json.Object("metadata", "annotations")
+	}
+	appendBillingHistory(json, billing, username, time.Now())
 	annotations := json.Path("metadata.annotations")
-	annotations.Set(billing, "openshift.io/kontierung-element")
-	annotations.Set(username, "openshift.io/requester")
+	annotations.Set(billing, getAnnotationKeys().Billing)
+	annotations.Set(username, getAnnotationKeys().Requester)
 
 	if testProject {
-		annotations.Set(testProjectDeletionDays, "openshift.io/testproject-daystodeletion")
+		annotations.Set(testProjectDeletionDays, testProjectDaysAnnotation)
 		annotations.Set(fmt.Sprintf("Dieses Testprojekt wird in %v Tagen automatisch gelöscht!", testProjectDeletionDays), "openshift.io/description")
 	}
 
+	// An empty MegaID keeps the existing annotation, so the billing can be updated on its own
 	if len(megaid) > 0 {
 		annotations.Set(megaid, "openshift.io/MEGAID")
 	}
 
-	resp, err = getOseHTTPClient("PUT", clusterId, "api/v1/namespaces/"+project, bytes.NewReader(json.Bytes()))
+	if len(ownerGroup) > 0 {
+		annotations.Set(ownerGroup, "openshift.io/owner-group")
+	}
+
+	if len(classification) > 0 {
+		annotations.Set(classification, dataClassificationAnnotation)
+	}
+}
+
+func createOrUpdateMetadata(clusterId, project string, billing string, megaid string, ownerGroup string, classification string, username string, testProject bool) error {
+	return patchProjectMetadata(clusterId, project, "", billing, megaid, ownerGroup, classification, username, testProject)
+}
+
+// updateMetadataIfMatch only updates the metadata if the namespace still has the resourceVersion.
+// An empty resourceVersion updates the namespace like createOrUpdateMetadata.
+func updateMetadataIfMatch(clusterId, project, resourceVersion, billing, megaid, ownerGroup, classification, username string) error {
+	return patchProjectMetadata(clusterId, project, resourceVersion, billing, megaid, ownerGroup, classification, username, false)
+}
+
+func patchProjectMetadata(clusterId, project, resourceVersion, billing, megaid, ownerGroup, classification, username string, testProject bool) error {
+	username, err := sanitizeUsername(username)
+	if err != nil {
+		return err
+	}
+	err = patchNamespaceAnnotationsIfMatch(clusterId, project, resourceVersion, func(json *gabs.Container) {
+		setProjectMetadata(json, billing, megaid, ownerGroup, classification, username, testProject)
+	})
+	if err != nil {
+		return err
+	}
+	log.Println("User "+username+" changed config of project "+project+" on cluster "+clusterId+". Kontierungsnummer: "+billing, ", MegaID: "+megaid, ", Owner group: "+ownerGroup)
+	return nil
+}
+
+// errProjectChanged is returned if the project doesn't have the resourceVersion of If-Match anymore
+func errProjectChanged() error {
+	return common.NewApiError(common.ErrorCodePreconditionFailed, "The project has been changed in the meantime. Please reload it and try again")
+}
+
+// updateProjectDisplayName sets the display name annotation. The other metadata is not changed.
+func updateProjectDisplayName(clusterId, project, displayName, username string) error {
+	err := patchNamespaceAnnotations(clusterId, project, func(json *gabs.Container) {
+		json.Set(displayName, "metadata", "annotations", displayNameAnnotation)
+	})
 	if err != nil {
 		return err
 	}
+	log.Printf("User %v changed the display name of project %v on cluster %v to '%v'", username, project, clusterId, displayName)
+	return nil
+}
 
-	if resp.StatusCode == http.StatusOK {
-		resp.Body.Close()
-		log.Println("User "+username+" changed config of project "+project+" on cluster "+clusterId+". Kontierungsnummer: "+billing, ", MegaID: "+megaid)
-		return nil
+// patchNamespaceAnnotations reads the namespace, lets update change it and only writes
+// the changed annotations with a JSON merge patch. The patch contains the resourceVersion
+// which was read, so a concurrent change fails with a conflict and is retried with the
+// latest namespace. If the API rejects the PATCH, the whole namespace is written with a PUT.
+func patchNamespaceAnnotations(clusterId, project string, update func(json *gabs.Container)) error {
+	return patchNamespaceAnnotationsIfMatch(clusterId, project, "", update)
+}
+
+// patchNamespaceAnnotationsIfMatch is patchNamespaceAnnotations, but fails with PRECONDITION_FAILED
+// if resourceVersion is set and the namespace has another resourceVersion
+func patchNamespaceAnnotationsIfMatch(clusterId, project, resourceVersion string, update func(json *gabs.Container)) error {
+	return retryOnConflict(func() error {
+		return patchNamespaceAnnotationsOnce(clusterId, project, resourceVersion, update)
+	})
+}
+
+func patchNamespaceAnnotationsOnce(clusterId, project, resourceVersion string, update func(json *gabs.Container)) error {
+	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	errMsg, _ := ioutil.ReadAll(resp.Body)
-	log.Println("Error updating project config:", err, resp.StatusCode, string(errMsg))
+	json, err := parseJSONResponse(resp)
+	if err != nil {
+		return err
+	}
+	currentVersion, _ := json.Path("metadata.resourceVersion").Data().(string)
+	if resourceVersion != "" && currentVersion != resourceVersion {
+		return errProjectChanged()
+	}
+	// Namespaces created outside of the SSP may not have any annotations
+	if !json.ExistsP("metadata.annotations") {
+		json.Object("metadata", "annotations")
+	}
+	before := map[string]interface{}{}
+	for key, value := range json.Path("metadata.annotations").ChildrenMap() {
+		before[key] = value.Data()
+	}
+
+	update(json)
 
-	return errors.New(genericAPIError)
+	after := json.Path("metadata.annotations").ChildrenMap()
+	changes := annotationsPatch(before, after)
+	changed := map[string]string{}
+	for key, value := range changes {
+		if s, ok := value.(string); ok {
+			changed[key] = s
+		}
+	}
+	if err := validateAnnotationValues(changed); err != nil {
+		return err
+	}
+	all := map[string]interface{}{}
+	for key, value := range after {
+		all[key] = value.Data()
+	}
+	if err := validateAnnotationsSize(all); err != nil {
+		return err
+	}
+
+	patch := gabs.New()
+	patch.Set(changes, "metadata", "annotations")
+	if currentVersion != "" {
+		patch.Set(currentVersion, "metadata", "resourceVersion")
+	}
+	resp, err = doOseRequest("PATCH", "application/merge-patch+json", clusterId, "api/v1/namespaces/"+project, bytes.NewReader(patch.Bytes()))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusUnsupportedMediaType {
+		log.Printf("PATCH of namespace %v rejected (%v), falling back to PUT", project, resp.StatusCode)
+		resp, err = getOseHTTPClient("PUT", clusterId, "api/v1/namespaces/"+project, bytes.NewReader(json.Bytes()))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		log.Println("Error updating project annotations:", resp.StatusCode, string(errMsg))
+		return newUpstreamError("PATCH", resp.StatusCode, errMsg)
+	}
+	return nil
+}
+
+// annotationsPatch returns the changed and added annotations.
+// Removed annotations are set to nil, which deletes them in a JSON merge patch.
+func annotationsPatch(before map[string]interface{}, after map[string]*gabs.Container) map[string]interface{} {
+	patch := map[string]interface{}{}
+	for key, value := range after {
+		if old, ok := before[key]; !ok || old != value.Data() {
+			patch[key] = value.Data()
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			patch[key] = nil
+		}
+	}
+	return patch
 }
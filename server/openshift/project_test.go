@@ -1,12 +1,19 @@
 package openshift
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
 	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/gin-gonic/gin"
 )
 
 func TestProjectFilter(t *testing.T) {
@@ -127,3 +134,847 @@ func TestValidateProjectPermissions(t *testing.T) {
 		t.Error("ERROR! function \"validateProjectPermissions\" not checking the functional account")
 	}
 }
+
+func TestParseProjectInformation(t *testing.T) {
+	var testsets = []struct {
+		name          string
+		namespace     string
+		project       string
+		isTestProject bool
+		deletionDate  string
+	}{
+		{"project", `{
+			"metadata": {
+				"creationTimestamp": "2020-08-01T10:00:00Z",
+				"annotations": {
+					"openshift.io/kontierung-element": "5678",
+					"openshift.io/MEGAID": "1234"
+				}
+			}
+		}`, "", false, ""},
+		{"testproject", `{
+			"metadata": {
+				"creationTimestamp": "2020-08-01T10:00:00Z",
+				"annotations": {
+					"openshift.io/kontierung-element": "keine-verrechnung",
+					"openshift.io/testproject-daystodeletion": "30"
+				}
+			}
+		}`, "", true, "2020-08-31"},
+		// depending on the version of OpenShift, the annotation is only on the Project object
+		{"testproject on the project object", `{
+			"metadata": {
+				"creationTimestamp": "2020-08-01T10:00:00Z",
+				"annotations": {}
+			}
+		}`, `{
+			"metadata": {
+				"creationTimestamp": "2020-08-01T10:00:00Z",
+				"annotations": {
+					"openshift.io/kontierung-element": "keine-verrechnung",
+					"openshift.io/TestProject-DaysToDeletion": "10"
+				}
+			}
+		}`, true, "2020-08-11"},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.name, func(t *testing.T) {
+			json, err := gabs.ParseJSON([]byte(set.namespace))
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			objects := []*gabs.Container{json}
+			if set.project != "" {
+				project, err := gabs.ParseJSON([]byte(set.project))
+				if err != nil {
+					t.Fatal("Invalid JSON!")
+				}
+				objects = append(objects, project)
+			}
+			pi := parseProjectInformation(objects...)
+			if pi.IsTestProject != set.isTestProject {
+				t.Errorf("ERROR: isTestProject should be %v, but is: %v", set.isTestProject, pi.IsTestProject)
+			}
+			if pi.DeletionDate != set.deletionDate {
+				t.Errorf("ERROR: deletionDate should be '%v', but is: '%v'", set.deletionDate, pi.DeletionDate)
+			}
+		})
+	}
+}
+
+func TestSetProjectMetadataWithoutAnnotations(t *testing.T) {
+	json, err := gabs.ParseJSON([]byte(`{
+		"metadata": {
+			"name": "project"
+		}
+	}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	setProjectMetadata(json, "5678", "1234", "DG_TEAM", "internal", "user", false)
+	if v, _ := json.Search("metadata", "annotations", "openshift.io/kontierung-element").Data().(string); v != "5678" {
+		t.Errorf("ERROR: kontierung-element should be 5678, but is: '%v'", v)
+	}
+	if v, _ := json.Search("metadata", "annotations", "openshift.io/MEGAID").Data().(string); v != "1234" {
+		t.Errorf("ERROR: MEGAID should be 1234, but is: '%v'", v)
+	}
+
+	pi := parseProjectInformation(json)
+	if pi.Kontierungsnummer != "5678" || pi.MegaID != "1234" || pi.OwnerGroup != "DG_TEAM" || pi.Classification != "internal" {
+		t.Errorf("ERROR: unexpected project information: %+v", pi)
+	}
+
+	pi = parseProjectInformation(gabs.New())
+	if pi.Kontierungsnummer != "" || pi.MegaID != "" {
+		t.Errorf("ERROR: project information should be empty, but is: %+v", pi)
+	}
+}
+
+func TestSetProjectMetadataKeepsMegaID(t *testing.T) {
+	json, err := gabs.ParseJSON([]byte(`{
+		"metadata": {
+			"annotations": {
+				"openshift.io/kontierung-element": "5678",
+				"openshift.io/MEGAID": "1234"
+			}
+		}
+	}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	setProjectMetadata(json, "9999", "", "", "", "user", false)
+	if v, _ := json.Search("metadata", "annotations", "openshift.io/kontierung-element").Data().(string); v != "9999" {
+		t.Errorf("ERROR: kontierung-element should be 9999, but is: '%v'", v)
+	}
+	if v, _ := json.Search("metadata", "annotations", "openshift.io/MEGAID").Data().(string); v != "1234" {
+		t.Errorf("ERROR: MEGAID should be 1234, but is: '%v'", v)
+	}
+}
+
+func TestCountRequestedProjects(t *testing.T) {
+	projects, err := gabs.ParseJSON([]byte(`[
+		{"metadata": {"name": "a", "annotations": {"openshift.io/requester": "u123456"}}},
+		{"metadata": {"name": "b", "annotations": {"openshift.io/requester": "U123456"}}},
+		{"metadata": {"name": "c", "annotations": {"openshift.io/requester": "u654321"}}},
+		{"metadata": {"name": "d"}}
+	]`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	if count := countRequestedProjects(projects, "u123456"); count != 2 {
+		t.Errorf("ERROR: expected 2 projects, got %v", count)
+	}
+}
+
+func TestGetTestProjectName(t *testing.T) {
+	config.Init("bla")
+	var testsets = []struct {
+		template string
+		name     string
+		expected string
+		valid    bool
+	}{
+		{"", "test", "u123456-test", true},
+		{"{{.Name}}-{{.User}}-test", "Demo", "demo-u123456-test", true},
+		{"", "my_project", "", false},
+		{"", strings.Repeat("a", 60), "", false},
+		{"{{.User", "test", "", false},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.template+"/"+set.name, func(t *testing.T) {
+			config.Config().Set("test_project_name_template", set.template)
+			project, err := getTestProjectName("u123456", set.name)
+			if set.valid && err != nil {
+				t.Fatalf("ERROR: unexpected error: %v", err)
+			}
+			if !set.valid && err == nil {
+				t.Fatalf("ERROR: expected an error for %v", project)
+			}
+			if project != set.expected {
+				t.Errorf("ERROR: project should be '%v', but is: '%v'", set.expected, project)
+			}
+		})
+	}
+}
+
+func TestGetProjectSummaries(t *testing.T) {
+	projects, err := gabs.ParseJSON([]byte(`[
+		{"metadata": {"name": "a", "creationTimestamp": "2020-08-01T10:00:00Z"}, "status": {"phase": "Active"}},
+		{"metadata": {"name": "b", "creationTimestamp": "2020-09-01T10:00:00Z"}, "status": {"phase": "Terminating"}},
+		{"metadata": {"name": "c"}},
+		{"metadata": {}}
+	]`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	summaries := getProjectSummaries(projects)
+	expected := []ProjectSummary{
+		{"a", "2020-08-01T10:00:00Z", "Active"},
+		{"b", "2020-09-01T10:00:00Z", "Terminating"},
+		{"c", "", ""},
+	}
+	if len(summaries) != len(expected) {
+		t.Fatalf("ERROR: expected %v projects, got %v", len(expected), len(summaries))
+	}
+	for i := range expected {
+		if summaries[i] != expected[i] {
+			t.Errorf("ERROR: project should be %+v, but is: %+v", expected[i], summaries[i])
+		}
+	}
+}
+
+func TestCreateOrUpdateMetadataKeepsConcurrentChanges(t *testing.T) {
+	annotations := map[string]interface{}{
+		"openshift.io/kontierung-element": "5678",
+		"openshift.io/description":        "old",
+	}
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			ns := gabs.New()
+			ns.Set("project", "metadata", "name")
+			ns.Set(annotations, "metadata", "annotations")
+			w.Write(ns.Bytes())
+			// another actor changes the namespace between our read and write
+			annotations["operator.io/managed"] = "true"
+			annotations["openshift.io/description"] = "changed by operator"
+		case "PATCH":
+			contentType = r.Header.Get("Content-Type")
+			patch, err := gabs.ParseJSONBuffer(r.Body)
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			for key, value := range patch.Path("metadata.annotations").ChildrenMap() {
+				annotations[key] = value.Data()
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected %v request", r.Method)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := createOrUpdateMetadata("test", "project", "9999", "1234", "", "", "user", false); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if contentType != "application/merge-patch+json" {
+		t.Errorf("ERROR: annotations should be updated with a merge patch, but Content-Type is: '%v'", contentType)
+	}
+	if annotations["openshift.io/kontierung-element"] != "9999" || annotations["openshift.io/MEGAID"] != "1234" {
+		t.Errorf("ERROR: annotations were not updated: %v", annotations)
+	}
+	if annotations["operator.io/managed"] != "true" || annotations["openshift.io/description"] != "changed by operator" {
+		t.Errorf("ERROR: concurrent changes were overwritten: %v", annotations)
+	}
+}
+
+func TestAnnotationsPatch(t *testing.T) {
+	after, _ := gabs.ParseJSON([]byte(`{"a": "1", "b": "changed", "d": "new"}`))
+	patch := annotationsPatch(map[string]interface{}{"a": "1", "b": "2", "c": "3"}, after.ChildrenMap())
+	if len(patch) != 3 || patch["b"] != "changed" || patch["d"] != "new" || patch["c"] != nil {
+		t.Errorf("ERROR: unexpected patch: %v", patch)
+	}
+	if _, ok := patch["c"]; !ok {
+		t.Error("ERROR: removed annotation should be set to null")
+	}
+}
+
+func TestValidateNewProjectClassification(t *testing.T) {
+	config.Init("bla")
+	var testsets = []struct {
+		classification string
+		testProject    bool
+		valid          bool
+	}{
+		{"internal", false, true},
+		{"", false, false},
+		{"secret", false, false},
+		{"", true, true},
+	}
+
+	for _, set := range testsets {
+		_, err := validateNewProject("project", projectFields{Billing: "5678", Classification: set.classification}, set.testProject)
+		if set.valid && err != nil {
+			t.Errorf("ERROR: classification '%v' should be valid, but got: %v", set.classification, err)
+		}
+		if !set.valid && err == nil {
+			t.Errorf("ERROR: classification '%v' should be invalid", set.classification)
+		}
+	}
+
+	config.Config().Set("data_classifications", []string{"secret"})
+	if _, err := validateNewProject("project", projectFields{Billing: "5678", Classification: "secret"}, false); err != nil {
+		t.Errorf("ERROR: configured classification should be valid, but got: %v", err)
+	}
+}
+
+func TestGetProjectInformationBatch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/mine/rolebindings":
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u123456"}]}]}`))
+		case "/apis/rbac.authorization.k8s.io/v1/namespaces/other/rolebindings":
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u654321"}]}]}`))
+		case "/api/v1/namespaces/mine":
+			w.Write([]byte(`{"metadata": {"name": "mine", "annotations": {"openshift.io/kontierung-element": "5678"}}}`))
+		default:
+			t.Errorf("ERROR: unexpected request %v", r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	results := getProjectInformationBatch("u123456", []common.OpenshiftBase{
+		{ClusterId: "test", Project: "mine"},
+		{ClusterId: "test", Project: "other"},
+	})
+	if len(results) != 2 {
+		t.Fatalf("ERROR: expected 2 results, got %v", len(results))
+	}
+	if results[0].Project != "mine" || results[0].Information == nil || results[0].Information.Kontierungsnummer != "5678" || results[0].Error != "" {
+		t.Errorf("ERROR: unexpected result for mine: %+v", results[0])
+	}
+	if results[1].Project != "other" || results[1].Information != nil || results[1].Code != common.ErrorCodeForbidden {
+		t.Errorf("ERROR: other should return a forbidden error, got: %+v", results[1])
+	}
+}
+
+func TestUpdateProjectDisplayName(t *testing.T) {
+	var patch *gabs.Container
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"metadata": {"name": "project", "annotations": {
+				"openshift.io/display-name": "Old Name",
+				"openshift.io/kontierung-element": "5678",
+				"openshift.io/MEGAID": "1234"}}}`))
+		case "PATCH":
+			var err error
+			if patch, err = gabs.ParseJSONBuffer(r.Body); err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected %v request", r.Method)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := updateProjectDisplayName("test", "project", "New Name", "user"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	expected := `{"metadata":{"annotations":{"openshift.io/display-name":"New Name"}}}`
+	if patch == nil || patch.String() != expected {
+		t.Errorf("ERROR: only the display name should change. Expected: %v, got: %v", expected, patch)
+	}
+}
+
+func TestValidateProjectDisplayName(t *testing.T) {
+	for _, displayName := range []string{"", "  ", strings.Repeat("a", maxDisplayNameLength+1)} {
+		data := common.UpdateProjectDisplayNameCommand{
+			OpenshiftBase: common.OpenshiftBase{ClusterId: "test", Project: "project"},
+			DisplayName:   displayName,
+		}
+		if err := validateProjectDisplayName(data, "user"); err == nil {
+			t.Errorf("ERROR: display name '%v' should be invalid", displayName)
+		}
+	}
+}
+
+func TestCreateNewProjectConflict(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`{"kind": "Status", "reason": "AlreadyExists"}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	err := createNewProject("test", "project", "user", "1234", "", "", "", nil, "", false)
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeProjectExists {
+		t.Fatalf("ERROR: expected a PROJECT_EXISTS error, got: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	common.RespondError(c, http.StatusBadRequest, err)
+	if w.Code != http.StatusConflict {
+		t.Errorf("ERROR: existing project should return 409, but returned %v", w.Code)
+	}
+
+	if apiErr, ok := newUpstreamError("POST", http.StatusConflict, nil).(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict || apiErr.Message != "The object already exists" {
+		t.Errorf("ERROR: conflicts of OpenShift should return a CONFLICT error, got: %v", apiErr)
+	}
+	// for updates, a conflict means that the object was changed in the meantime
+	for _, method := range []string{"PUT", "PATCH"} {
+		if apiErr, ok := newUpstreamError(method, http.StatusConflict, nil).(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict || apiErr.Message != "The object was changed concurrently, please retry" {
+			t.Errorf("ERROR: conflicts of a %v should be reported as concurrent change, got: %v", method, apiErr)
+		}
+	}
+}
+
+func TestNewProjectHandlerValidationStatus(t *testing.T) {
+	config.Init("bla")
+	gin.SetMode(gin.TestMode)
+	var testsets = []struct {
+		body   string
+		status int
+	}{
+		// malformed requests
+		{`{"project": `, http.StatusBadRequest},
+		{`{"clusterid": "test", "billing": "5678", "classification": "internal"}`, http.StatusBadRequest},
+		{`{"clusterid": "test", "project": "project", "billing": "5678", "classification": "internal", "operators": [` +
+			strings.Repeat(`"u123456", `, defaultMaxProjectOperators) + `"u123456"]}`, http.StatusBadRequest},
+		// well-formed requests with invalid values
+		{`{"clusterid": "test", "project": "project", "billing": "5678", "classification": "secret"}`, http.StatusUnprocessableEntity},
+		{`{"clusterid": "test", "project": "project", "billing": "5678", "classification": "internal", "nodeSelector": "zone"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, set := range testsets {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest("POST", "/ose/project", strings.NewReader(set.body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		newProjectHandler(c)
+		if w.Code != set.status {
+			t.Errorf("ERROR: %v should return %v, but returned %v: %v", set.body, set.status, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	common.RespondError(c, http.StatusBadRequest, common.NewApiError(common.ErrorCodeInvalidBilling, "Invalid accounting number"))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ERROR: invalid accounting numbers should return 422, but returned %v", w.Code)
+	}
+}
+
+func TestChangeProjectPermissionRetriesConflict(t *testing.T) {
+	gets := 0
+	puts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			gets++
+			// Someone else adds u222222 between the first and the second attempt
+			subjects := `[{"kind": "User", "name": "u111111"}]`
+			if gets > 1 {
+				subjects = `[{"kind": "User", "name": "u111111"}, {"kind": "User", "name": "u222222"}]`
+			}
+			fmt.Fprintf(w, `{"items": [{"metadata": {"name": "admin", "resourceVersion": "%v"}, "roleRef": {"name": "admin"}, "subjects": %v}]}`, gets, subjects)
+		case "PUT":
+			puts++
+			if puts == 1 {
+				w.WriteHeader(http.StatusConflict)
+				w.Write([]byte(`{"kind": "Status", "reason": "Conflict"}`))
+				return
+			}
+			json, err := gabs.ParseJSONBuffer(r.Body)
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			if json.Path("metadata.resourceVersion").Data() != "2" {
+				t.Errorf("ERROR: the retry should use the latest object, got resourceVersion %v", json.Path("metadata.resourceVersion").Data())
+			}
+			if !strings.Contains(json.S("subjects").String(), "u222222") {
+				t.Errorf("ERROR: the concurrent change should be kept, got: %v", json.S("subjects").String())
+			}
+			w.Write(json.Bytes())
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := changeProjectPermission("test", "project", "u333333"); err != nil {
+		t.Errorf("ERROR: unexpected error: %v", err)
+	}
+	if gets != 2 || puts != 2 {
+		t.Errorf("ERROR: expected 2 GETs and 2 PUTs, got %v and %v", gets, puts)
+	}
+}
+
+func TestRetryOnConflictExhausted(t *testing.T) {
+	config.Init("bla")
+	config.Config().Set("ose_conflict_retries", 2)
+	calls := 0
+	err := retryOnConflict(func() error {
+		calls++
+		return newUpstreamError("PUT", http.StatusConflict, nil)
+	})
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict || calls != 2 {
+		t.Errorf("ERROR: expected conflict after 2 attempts, got %v after %v", err, calls)
+	}
+}
+
+func TestEmptyListsAreSerializedAsArrays(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.JSON(http.StatusOK, getProjectNames(gabs.New()))
+	if w.Body.String() != "[]" {
+		t.Errorf("ERROR: no projects should return [], got: %v", w.Body.String())
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"items": []}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/ose/project/admins?clusterid=test&project=project", nil)
+	getProjectAdminsHandler(c)
+	if w.Body.String() != `{"admins":[]}` {
+		t.Errorf("ERROR: no admins should return an empty array, got: %v", w.Body.String())
+	}
+
+	admins, operators, err := getProjectAdminsAndOperators("test", "project")
+	if err != nil || admins == nil || operators == nil {
+		t.Errorf("ERROR: admins and operators should be empty slices, got: %v, %v (error: %v)", admins, operators, err)
+	}
+}
+
+func TestMergeProjectInformation(t *testing.T) {
+	current := &ProjectInformation{
+		Kontierungsnummer: "12345",
+		MegaID:            "m1",
+		OwnerGroup:        "DG_TEAM",
+		Classification:    "internal",
+	}
+	full := common.UpdateProjectInformationCommand{Billing: "99999", MegaID: "m2", OwnerGroup: "DG_OTHER", Classification: "public"}
+
+	var testsets = []struct {
+		name     string
+		update   func(data *common.UpdateProjectInformationCommand)
+		expected common.UpdateProjectInformationCommand
+	}{
+		{"all fields", func(data *common.UpdateProjectInformationCommand) {},
+			common.UpdateProjectInformationCommand{Billing: "99999", MegaID: "m2", OwnerGroup: "DG_OTHER", Classification: "public"}},
+		{"billing omitted", func(data *common.UpdateProjectInformationCommand) { data.Billing = "" },
+			common.UpdateProjectInformationCommand{Billing: "12345", MegaID: "m2", OwnerGroup: "DG_OTHER", Classification: "public"}},
+		{"megaid omitted", func(data *common.UpdateProjectInformationCommand) { data.MegaID = "" },
+			common.UpdateProjectInformationCommand{Billing: "99999", MegaID: "m1", OwnerGroup: "DG_OTHER", Classification: "public"}},
+		{"owner group omitted", func(data *common.UpdateProjectInformationCommand) { data.OwnerGroup = " " },
+			common.UpdateProjectInformationCommand{Billing: "99999", MegaID: "m2", OwnerGroup: "DG_TEAM", Classification: "public"}},
+		{"classification omitted", func(data *common.UpdateProjectInformationCommand) { data.Classification = "" },
+			common.UpdateProjectInformationCommand{Billing: "99999", MegaID: "m2", OwnerGroup: "DG_OTHER", Classification: "internal"}},
+		{"only megaid", func(data *common.UpdateProjectInformationCommand) {
+			*data = common.UpdateProjectInformationCommand{MegaID: "m3"}
+		}, common.UpdateProjectInformationCommand{Billing: "12345", MegaID: "m3", OwnerGroup: "DG_TEAM", Classification: "internal"}},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.name, func(t *testing.T) {
+			data := full
+			set.update(&data)
+			if merged := mergeProjectInformation(data, current); merged != set.expected {
+				t.Errorf("ERROR: expected %+v, got %+v", set.expected, merged)
+			}
+		})
+	}
+}
+
+func TestMatchesETag(t *testing.T) {
+	var testsets = []struct {
+		ifMatch string
+		matches bool
+	}{
+		{`"5"`, true},
+		{`W/"5"`, true},
+		{`"4", "5"`, true},
+		{`*`, true},
+		{`"4"`, false},
+		{`""`, false},
+	}
+	for _, set := range testsets {
+		if matches := matchesETag(set.ifMatch, "5"); matches != set.matches {
+			t.Errorf("ERROR: If-Match %v should match: %v", set.ifMatch, set.matches)
+		}
+	}
+}
+
+func TestUpdateProjectInformationIfMatch(t *testing.T) {
+	var namespaceReads int
+	var patch *gabs.Container
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/rolebindings"):
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u123456"}]}]}`))
+		case r.Method == "GET":
+			namespaceReads++
+			w.Write([]byte(`{"metadata": {"name": "project", "resourceVersion": "5", "annotations": {"openshift.io/kontierung-element": "12345"}}}`))
+		case r.Method == "PATCH":
+			patch, _ = gabs.ParseJSONBuffer(r.Body)
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("ERROR: unexpected request %v %v", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("required_project_fields", []string{"billing"})
+
+	gin.SetMode(gin.TestMode)
+	update := func(username, ifMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(keycloak.APITokenUserKey, username)
+		c.Request = httptest.NewRequest("POST", "/ose/project/info", strings.NewReader(`{"clusterid": "test", "project": "project", "billing": "99999"}`))
+		c.Request.Header.Set("If-Match", ifMatch)
+		updateProjectInformationHandler(c)
+		return w
+	}
+
+	// The permissions are checked before the project is read
+	if w := update("u999999", `"5"`); w.Code == http.StatusOK || namespaceReads != 0 {
+		t.Errorf("ERROR: other users should be refused before the project is read, got %v after %v reads", w.Code, namespaceReads)
+	}
+
+	if w := update("u123456", `"4"`); w.Code != http.StatusPreconditionFailed || !strings.Contains(w.Body.String(), common.ErrorCodePreconditionFailed) || patch != nil {
+		t.Errorf("ERROR: a changed project should return 412, got %v: %v", w.Code, w.Body.String())
+	}
+
+	// The resourceVersion is sent with the patch, so OpenShift refuses changes in the meantime
+	if w := update("u123456", `"5"`); w.Code != http.StatusOK {
+		t.Fatalf("ERROR: unexpected response %v: %v", w.Code, w.Body.String())
+	}
+	if patch == nil || patch.Path("metadata.resourceVersion").Data() != "5" {
+		t.Errorf("ERROR: the patch should contain the resourceVersion, got: %v", patch)
+	}
+}
+
+// Namespace of an OpenShift 3.11 cluster
+const v3Namespace = `{
+	"kind": "Namespace",
+	"apiVersion": "v1",
+	"metadata": {
+		"name": "shop",
+		"selfLink": "/api/v1/namespaces/shop",
+		"uid": "4c2c5d3e-1f0a-11ea-8d3a-005056b1a2c3",
+		"resourceVersion": "123456",
+		"creationTimestamp": "2019-12-14T10:00:00Z",
+		"annotations": {
+			"openshift.io/description": "",
+			"openshift.io/display-name": "",
+			"openshift.io/kontierung-element": "5678",
+			"openshift.io/MEGAID": "1234",
+			"openshift.io/requester": "u123456",
+			"openshift.io/sa.scc.mcs": "s0:c12,c4",
+			"openshift.io/sa.scc.supplemental-groups": "1000140000/10000",
+			"openshift.io/sa.scc.uid-range": "1000140000/10000"
+		}
+	},
+	"spec": {"finalizers": ["openshift.io/origin", "kubernetes"]},
+	"status": {"phase": "Active"}
+}`
+
+// Namespace of an OpenShift 4 cluster. The project was migrated with a lowercase MEGAID and without billing
+const v4Namespace = `{
+	"kind": "Namespace",
+	"apiVersion": "v1",
+	"metadata": {
+		"name": "shop",
+		"uid": "0f7c9a52-6a8e-4b1f-9d55-2b1f7a0c3e11",
+		"resourceVersion": "98765",
+		"creationTimestamp": "2021-03-01T10:00:00Z",
+		"labels": {"kubernetes.io/metadata.name": "shop"},
+		"annotations": {
+			"openshift.io/megaid": "1234",
+			"openshift.io/sa.scc.mcs": "s0:c26,c5",
+			"openshift.io/sa.scc.supplemental-groups": "1000660000/10000",
+			"openshift.io/sa.scc.uid-range": "1000660000/10000"
+		},
+		"managedFields": [{"manager": "openshift-controller-manager", "operation": "Update", "apiVersion": "v1"}]
+	},
+	"spec": {"finalizers": ["kubernetes"]},
+	"status": {"phase": "Active"}
+}`
+
+// Project of an OpenShift 4 cluster
+const v4Project = `{
+	"kind": "Project",
+	"apiVersion": "project.openshift.io/v1",
+	"metadata": {
+		"name": "shop",
+		"uid": "0f7c9a52-6a8e-4b1f-9d55-2b1f7a0c3e11",
+		"resourceVersion": "98765",
+		"creationTimestamp": "2021-03-01T10:00:00Z",
+		"annotations": {
+			"openshift.io/kontierung-element": "5678",
+			"openshift.io/MEGAID": "1234",
+			"openshift.io/requester": "u123456"
+		}
+	},
+	"spec": {"finalizers": ["kubernetes"]},
+	"status": {"phase": "Active"}
+}`
+
+func TestGetProjectInformationAcrossVersions(t *testing.T) {
+	var testsets = []struct {
+		name      string
+		namespace string
+		project   string
+		// expected requests to the project API
+		projectCalls int
+	}{
+		{"v3", v3Namespace, "", 0},
+		{"v4", v4Namespace, v4Project, 1},
+		{"v4 without namespace permission", "", v4Project, 1},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.name, func(t *testing.T) {
+			projectCalls := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body := set.namespace
+				if r.URL.Path == "/apis/project.openshift.io/v1/projects/shop" {
+					projectCalls++
+					body = set.project
+				} else if r.URL.Path != "/api/v1/namespaces/shop" {
+					t.Errorf("ERROR: unexpected request %v", r.URL.Path)
+				}
+				if body == "" {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+			setTestCluster(srv.URL)
+
+			pi, err := getProjectInformation("test", "shop")
+			if err != nil {
+				t.Fatalf("ERROR: unexpected error: %v", err)
+			}
+			if pi.Kontierungsnummer != "5678" || pi.MegaID != "1234" || pi.Requester != "u123456" || pi.ResourceVersion == "" {
+				t.Errorf("ERROR: unexpected project information: %+v", pi)
+			}
+			if projectCalls != set.projectCalls {
+				t.Errorf("ERROR: expected %v requests for the project, got %v", set.projectCalls, projectCalls)
+			}
+		})
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	if _, err := getProjectInformation("test", "shop"); err == nil {
+		t.Error("ERROR: missing projects should return an error")
+	}
+}
+
+func TestNewProjectHandlerWarnings(t *testing.T) {
+	created := false
+	projectListCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/apis/project.openshift.io/v1/projects":
+			projectListCalls++
+			w.Write([]byte(`{"items": [{"metadata": {"name": "my-projekt"}}]}`))
+		case r.Method == "POST" && r.URL.Path == "/apis/project.openshift.io/v1/projectrequests":
+			created = true
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/rolebindings"):
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": []}]}`))
+		case r.Method == "GET":
+			w.Write([]byte(`{"metadata": {"name": "my-project", "annotations": {}}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	gin.SetMode(gin.TestMode)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(keycloak.APITokenUserKey, "u123456")
+	c.Request, _ = http.NewRequest("POST", "/ose/project", strings.NewReader(
+		`{"clusterid": "test", "project": "my-project", "billing": "5678", "classification": "internal"}`))
+	c.Request.Header.Set("Content-Type", "application/json")
+	newProjectHandler(c)
+
+	if w.Code != http.StatusOK || !created {
+		t.Fatalf("ERROR: the project should be created despite the warning, got %v: %v", w.Code, w.Body.String())
+	}
+	var response common.ApiResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	if len(response.Warnings) != 1 || !strings.Contains(response.Warnings[0], "similar") {
+		t.Errorf("ERROR: expected a warning about a similar project, got: %v", response.Warnings)
+	} else if strings.Contains(response.Warnings[0], "my-projekt") {
+		t.Errorf("ERROR: the name of the similar project should not be returned, got: %v", response.Warnings[0])
+	}
+
+	// the project names are listed only once
+	listed := projectListCalls
+	if len(similarProjectNameWarnings("test", "my-projects")) != 0 || projectListCalls != listed {
+		t.Errorf("ERROR: the cached project names should be used, got %v list requests", projectListCalls-listed)
+	}
+}
+
+func TestSubmitProjectRequestWithFailedPullSecret(t *testing.T) {
+	secretCalls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/apis/project.openshift.io/v1/projectrequests":
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{}`))
+		case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/secrets"):
+			secretCalls++
+			w.WriteHeader(http.StatusInternalServerError)
+		case r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/rolebindings"):
+			w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": []}]}`))
+		case r.Method == "GET":
+			w.Write([]byte(`{"metadata": {"name": "my-project", "annotations": {}}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("project_pull_secret", map[string]interface{}{
+		"enabled":  true,
+		"registry": "registry.example.com",
+		"username": "robot",
+		"password": "secret",
+	})
+
+	// the project was already created, so the request must not fail
+	err := submitProjectRequest("test", "my-project", gabs.New(), "u123456", "5678", "", "", "internal", nil, "", false)
+	if err != nil || secretCalls != 1 {
+		t.Errorf("ERROR: a failed pull secret should only be logged, got %v after %v secret requests", err, secretCalls)
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	var testsets = []struct {
+		a, b     string
+		distance int
+	}{
+		{"project", "project", 0},
+		{"project", "projekt", 1},
+		{"project", "projects", 1},
+		{"project", "roject", 1},
+		{"project", "tcejorp", 6},
+		{"", "abc", 3},
+	}
+	for _, set := range testsets {
+		if d := editDistance(set.a, set.b); d != set.distance {
+			t.Errorf("ERROR: distance of %v and %v should be %v, got: %v", set.a, set.b, set.distance, d)
+		}
+	}
+}
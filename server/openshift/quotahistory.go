@@ -0,0 +1,95 @@
+package openshift
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	quotaHistoryAnnotation = "openshift.io/quota-history"
+	// Older entries are removed, because the size of the annotations is limited
+	maxQuotaHistoryEntries = 50
+)
+
+// QuotaChange is an entry in the quota history of a project
+type QuotaChange struct {
+	Timestamp string `json:"timestamp"`
+	OldQuota  Quota  `json:"oldQuota"`
+	NewQuota  Quota  `json:"newQuota"`
+	Username  string `json:"username"`
+	// e.g. the quota tier or the approved quota request
+	Reason string `json:"reason,omitempty"`
+}
+
+func getQuotaHistoryHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	params := c.Request.URL.Query()
+	clusterId := params.Get("clusterid")
+	project := params.Get("project")
+
+	if err := validateAdminAccess(clusterId, username, project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	resp, err := getOseHTTPClient("GET", clusterId, "api/v1/namespaces/"+project, nil)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	defer resp.Body.Close()
+
+	namespace, err := parseJSONResponse(resp)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getQuotaHistory(namespace))
+}
+
+// getQuotaHistory returns the quota history of the namespace, oldest entry first
+func getQuotaHistory(namespace *gabs.Container) []QuotaChange {
+	history := []QuotaChange{}
+	value, ok := namespace.Path("metadata.annotations").S(quotaHistoryAnnotation).Data().(string)
+	if !ok {
+		return history
+	}
+	if err := json.Unmarshal([]byte(value), &history); err != nil {
+		log.Printf("Invalid %v annotation: %v", quotaHistoryAnnotation, err)
+		return []QuotaChange{}
+	}
+	return history
+}
+
+// appendQuotaHistory adds an entry to the quota history if the quota changes
+func appendQuotaHistory(namespace *gabs.Container, oldQuota, newQuota Quota, username, reason string, now time.Time) {
+	if oldQuota == newQuota {
+		return
+	}
+
+	history := append(getQuotaHistory(namespace), QuotaChange{
+		Timestamp: now.UTC().Format(time.RFC3339),
+		OldQuota:  oldQuota,
+		NewQuota:  newQuota,
+		Username:  username,
+		Reason:    reason,
+	})
+	if len(history) > maxQuotaHistoryEntries {
+		history = history[len(history)-maxQuotaHistoryEntries:]
+	}
+
+	value, err := json.Marshal(history)
+	if err != nil {
+		log.Printf("Error encoding quota history: %v", err)
+		return
+	}
+	namespace.Path("metadata.annotations").Set(string(value), quotaHistoryAnnotation)
+}
@@ -0,0 +1,71 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestAppendQuotaHistory(t *testing.T) {
+	namespace, err := gabs.ParseJSON([]byte(`{"metadata": {"annotations": {}}}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	now := time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	appendQuotaHistory(namespace, newQuota(2, 4), newQuota(4, 8), "u1", "Quota request abc: load test", now)
+	// unchanged quotas are not recorded
+	appendQuotaHistory(namespace, newQuota(4, 8), newQuota(4, 8), "u2", "", now)
+
+	history := getQuotaHistory(namespace)
+	expected := QuotaChange{Timestamp: "2020-08-01T10:00:00Z", OldQuota: newQuota(2, 4), NewQuota: newQuota(4, 8), Username: "u1", Reason: "Quota request abc: load test"}
+	if len(history) != 1 || history[0] != expected {
+		t.Fatalf("ERROR: expected %+v, got %+v", expected, history)
+	}
+
+	for i := 0; i < maxQuotaHistoryEntries; i++ {
+		appendQuotaHistory(namespace, newQuota(i, 8), newQuota(i+1, 8), "u3", "", now)
+	}
+	if history := getQuotaHistory(namespace); len(history) != maxQuotaHistoryEntries {
+		t.Errorf("ERROR: history should be capped at %v, but has %v entries", maxQuotaHistoryEntries, len(history))
+	}
+}
+
+func TestUpdateQuotasRecordsHistory(t *testing.T) {
+	var annotations map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/v1/namespaces/project/resourcequotas":
+			w.Write([]byte(`{"items": [{"metadata": {"name": "quota"}, "spec": {"hard": {"cpu": "2", "memory": "4Gi"}}}]}`))
+		case "PUT /api/v1/namespaces/project/resourcequotas/quota":
+			w.Write([]byte("{}"))
+		case "GET /api/v1/namespaces/project":
+			w.Write([]byte(`{"metadata": {"name": "project", "annotations": {}}}`))
+		case "PATCH /api/v1/namespaces/project":
+			patch, err := gabs.ParseJSONBuffer(r.Body)
+			if err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			annotations = patch.Path("metadata.annotations").Data().(map[string]interface{})
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected request %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := updateQuotas("test", "u123456", "project", 4, 8, "Quota tier medium"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	namespace := gabs.New()
+	namespace.Set(annotations, "metadata", "annotations")
+	history := getQuotaHistory(namespace)
+	if len(history) != 1 || history[0].OldQuota != newQuota(2, 4) || history[0].NewQuota != newQuota(4, 8) || history[0].Reason != "Quota tier medium" {
+		t.Errorf("ERROR: unexpected history: %+v", history)
+	}
+}
@@ -0,0 +1,347 @@
+package openshift
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// QuotaRequest is a pending request to increase the quota of a project
+type QuotaRequest struct {
+	ID       string                      `json:"id"`
+	Command  common.QuotaIncreaseCommand `json:"command"`
+	Username string                      `json:"username"`
+	// Mail address of the user, who is notified about the decision
+	Mail        string    `json:"mail,omitempty"`
+	Current     Quota     `json:"current"`
+	Requested   Quota     `json:"requested"`
+	RequestedAt time.Time `json:"requestedAt"`
+}
+
+var (
+	// Guards the quota store file and processingQuotaRequests
+	quotaRequestsMu sync.Mutex
+	// IDs of the requests which are being approved or rejected
+	processingQuotaRequests = map[string]bool{}
+)
+
+// getQuotaApprovalConfig returns the project_approval config, which is also used for quota requests
+func getQuotaApprovalConfig() (*projectApprovalConfig, error) {
+	cfg := projectApprovalConfig{}
+	if err := config.UnmarshalKey("project_approval", &cfg); err != nil {
+		log.Printf("WARNING: invalid project_approval config: %v", err)
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	if cfg.ApproverGroup == "" || cfg.QuotaStoreFile == "" {
+		log.Printf("WARNING: project_approval.approver_group and project_approval.quota_store_file must be set for quota requests")
+		return nil, common.NewApiError(common.ErrorCodeInvalidRequest, common.ConfigNotSetError)
+	}
+	return &cfg, nil
+}
+
+func loadQuotaRequests(file string) ([]QuotaRequest, error) {
+	requests := []QuotaRequest{}
+	if err := readStoreFile(file, &requests); err != nil {
+		return nil, err
+	}
+	return requests, nil
+}
+
+// addQuotaRequest stores a new request. Only one request per project and cluster is allowed.
+func addQuotaRequest(file string, r QuotaRequest) error {
+	quotaRequestsMu.Lock()
+	defer quotaRequestsMu.Unlock()
+
+	requests, err := loadQuotaRequests(file)
+	if err != nil {
+		return err
+	}
+	for _, existing := range requests {
+		if existing.Command.ClusterId == r.Command.ClusterId && strings.EqualFold(existing.Command.Project, r.Command.Project) {
+			return common.NewApiError(common.ErrorCodeConflict,
+				fmt.Sprintf("A quota increase for project %v on cluster %v has already been requested", r.Command.Project, r.Command.ClusterId))
+		}
+	}
+	return writeStoreFile(file, append(requests, r))
+}
+
+// claimQuotaRequest returns the request and marks it as being processed, so that concurrent
+// approvals don't set the quota twice. The claim must be released with releaseQuotaRequest.
+func claimQuotaRequest(file, id string) (*QuotaRequest, error) {
+	quotaRequestsMu.Lock()
+	defer quotaRequestsMu.Unlock()
+
+	requests, err := loadQuotaRequests(file)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range requests {
+		if r.ID != id {
+			continue
+		}
+		if processingQuotaRequests[id] {
+			return nil, common.NewApiError(common.ErrorCodeConflict, fmt.Sprintf("The quota request %v is already being processed", id))
+		}
+		processingQuotaRequests[id] = true
+		return &r, nil
+	}
+	return nil, common.NewApiError(common.ErrorCodeProjectNotFound, fmt.Sprintf("The quota request %v doesn't exist", id))
+}
+
+func releaseQuotaRequest(id string) {
+	quotaRequestsMu.Lock()
+	defer quotaRequestsMu.Unlock()
+	delete(processingQuotaRequests, id)
+}
+
+func removeQuotaRequest(file, id string) error {
+	quotaRequestsMu.Lock()
+	defer quotaRequestsMu.Unlock()
+
+	requests, err := loadQuotaRequests(file)
+	if err != nil {
+		return err
+	}
+	remaining := []QuotaRequest{}
+	for _, r := range requests {
+		if r.ID != id {
+			remaining = append(remaining, r)
+		}
+	}
+	return writeStoreFile(file, remaining)
+}
+
+// validateQuotaIncrease checks that the requested quota is higher than the current one
+func validateQuotaIncrease(current, requested Quota) error {
+	if requested.CPUMillicores < current.CPUMillicores || requested.MemoryBytes < current.MemoryBytes {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "The requested quota must not be lower than the current quota")
+	}
+	if requested == current {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "The requested quota is the same as the current quota")
+	}
+	return nil
+}
+
+// getCurrentQuota returns the quota of the project, or an empty quota if it has none
+func getCurrentQuota(clusterId, project string) (Quota, error) {
+	quota, err := getQuotas(clusterId, project)
+	if err != nil {
+		return Quota{}, err
+	}
+	return quotaOf(quota)
+}
+
+// requestQuotaIncreaseHandler stores a request for a higher quota and notifies the approvers
+func requestQuotaIncreaseHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.QuotaIncreaseCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	if strings.TrimSpace(data.Justification) == "" || data.CPU <= 0 || data.Memory <= 0 {
+		common.RespondError(c, http.StatusBadRequest, common.NewApiError(common.ErrorCodeInvalidRequest,
+			"CPU, memory and a justification must be provided"))
+		return
+	}
+	// checks the limits of the cluster and the admin permissions
+	if err := validateEditQuotas(data.ClusterId, username, data.Project, data.CPU, data.Memory); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	cfg, err := getQuotaApprovalConfig()
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	current, err := getCurrentQuota(data.ClusterId, data.Project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	requested := newQuota(data.CPU, data.Memory)
+	if err := validateQuotaIncrease(current, requested); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	r := QuotaRequest{
+		ID:          common.RandomString(8),
+		Command:     data,
+		Username:    username,
+		Mail:        common.GetUserMail(c),
+		Current:     current,
+		Requested:   requested,
+		RequestedAt: time.Now().UTC(),
+	}
+	if err := addQuotaRequest(cfg.QuotaStoreFile, r); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  data.ClusterId,
+		"project":  data.Project,
+		"username": username,
+		"cpu":      data.CPU,
+		"memory":   data.Memory,
+		"id":       r.ID,
+	}).Info("AUDIT: Quota increase was requested and waits for approval")
+
+	body := fmt.Sprintf("%v has requested a higher quota for project %v on cluster %v.\n\n"+
+		"CPU: %v -> %v cores\nMemory: %v -> %v GiB\nJustification: %v\nRequest ID: %v\n",
+		username, data.Project, data.ClusterId, float64(current.CPUMillicores)/1000, data.CPU,
+		float64(current.MemoryBytes)/(1<<30), data.Memory, data.Justification, r.ID)
+	if err := sendApprovalMail(parseMailAddresses(cfg.Mail), fmt.Sprintf("Quota of project '%v' on OpenShift waits for approval", data.Project), body); err != nil {
+		log.Printf("Can't send e-mail about the quota request %v: %v", r.ID, err)
+	}
+	c.JSON(http.StatusAccepted, common.ApiResponse{
+		Message: common.T(c, common.MsgQuotaRequested, data.Project, data.ClusterId),
+	})
+}
+
+func getQuotaRequestsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	cfg, err := getQuotaApprovalConfig()
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := checkApprover(cfg.ApproverGroup, username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	quotaRequestsMu.Lock()
+	requests, err := loadQuotaRequests(cfg.QuotaStoreFile)
+	quotaRequestsMu.Unlock()
+	if err != nil {
+		log.Printf("Error reading the quota requests: %v", err)
+		common.RespondError(c, http.StatusInternalServerError, common.NewApiError(common.ErrorCodeBackendError, genericAPIError))
+		return
+	}
+	c.JSON(http.StatusOK, requests)
+}
+
+// approveQuotaRequestHandler sets the requested quota. The request is only removed if the quota was set.
+func approveQuotaRequestHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectRequestCommand
+	if c.BindJSON(&data) != nil || data.ID == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	r, cfg, ok := claimQuotaRequestForApprover(c, username, data.ID)
+	if !ok {
+		return
+	}
+	defer releaseQuotaRequest(r.ID)
+	if strings.EqualFold(r.Username, username) {
+		common.RespondError(c, http.StatusForbidden, common.NewApiError(common.ErrorCodeForbidden, "Approvers can't approve their own requests"))
+		return
+	}
+	// the limits of the cluster could have been changed since the request
+	quotaConfig, err := getQuotaConfig(r.Command.ClusterId)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if r.Command.CPU > quotaConfig.MaxCPU || r.Command.Memory > quotaConfig.MaxMemory {
+		common.RespondError(c, http.StatusBadRequest, common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The requested quota exceeds the limits of cluster %v (CPU: %v, memory: %v)", r.Command.ClusterId, quotaConfig.MaxCPU, quotaConfig.MaxMemory)))
+		return
+	}
+	// the quota could have been changed directly since the request, e.g. by a portal admin
+	current, err := getCurrentQuota(r.Command.ClusterId, r.Command.Project)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if err := validateQuotaIncrease(current, newQuota(r.Command.CPU, r.Command.Memory)); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	reason := fmt.Sprintf("Quota request %v: %v", r.ID, r.Command.Justification)
+	if err := updateQuotas(r.Command.ClusterId, username, r.Command.Project, r.Command.CPU, r.Command.Memory, reason); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  r.Command.ClusterId,
+		"project":  r.Command.Project,
+		"approver": username,
+		"id":       r.ID,
+	}).Info("AUDIT: Quota request was approved")
+	if err := removeQuotaRequest(cfg.QuotaStoreFile, r.ID); err != nil {
+		log.Printf("Error removing the approved quota request %v: %v", r.ID, err)
+	}
+	if err := sendApprovalMail(parseMailAddresses(r.Mail), fmt.Sprintf("Quota of project '%v' on OpenShift has been approved", r.Command.Project),
+		fmt.Sprintf("Your quota request for project %v on cluster %v has been approved by %v.\n\nCPU: %v cores\nMemory: %v GiB\n",
+			r.Command.Project, r.Command.ClusterId, username, r.Command.CPU, r.Command.Memory)); err != nil {
+		log.Printf("Can't send e-mail about the approval of quota request %v: %v", r.ID, err)
+	}
+	c.JSON(http.StatusOK, common.ApiResponse{Message: common.T(c, common.MsgQuotaApproved, r.Command.Project, r.Command.ClusterId)})
+}
+
+func rejectQuotaRequestHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.ProjectRequestCommand
+	if c.BindJSON(&data) != nil || data.ID == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	r, cfg, ok := claimQuotaRequestForApprover(c, username, data.ID)
+	if !ok {
+		return
+	}
+	defer releaseQuotaRequest(r.ID)
+	if err := removeQuotaRequest(cfg.QuotaStoreFile, r.ID); err != nil {
+		log.Printf("Error removing the rejected quota request %v: %v", r.ID, err)
+		common.RespondError(c, http.StatusInternalServerError, common.NewApiError(common.ErrorCodeBackendError, genericAPIError))
+		return
+	}
+	log.WithFields(log.Fields{
+		"cluster":  r.Command.ClusterId,
+		"project":  r.Command.Project,
+		"approver": username,
+		"id":       r.ID,
+		"reason":   data.Reason,
+	}).Info("AUDIT: Quota request was rejected")
+	if err := sendApprovalMail(parseMailAddresses(r.Mail), fmt.Sprintf("Quota of project '%v' on OpenShift has been rejected", r.Command.Project),
+		fmt.Sprintf("Your quota request for project %v on cluster %v has been rejected by %v.\n\nReason: %v\n",
+			r.Command.Project, r.Command.ClusterId, username, data.Reason)); err != nil {
+		log.Printf("Can't send e-mail about the rejection of quota request %v: %v", r.ID, err)
+	}
+	c.JSON(http.StatusOK, common.ApiResponse{Message: common.T(c, common.MsgQuotaRejected, r.Command.Project, r.Command.ClusterId)})
+}
+
+// claimQuotaRequestForApprover checks that the user is an approver and claims the request.
+// If ok is false, the error response has been written. Otherwise the claim must be released.
+func claimQuotaRequestForApprover(c *gin.Context, username, id string) (*QuotaRequest, *projectApprovalConfig, bool) {
+	cfg, err := getQuotaApprovalConfig()
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return nil, nil, false
+	}
+	if err := checkApprover(cfg.ApproverGroup, username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return nil, nil, false
+	}
+	r, err := claimQuotaRequest(cfg.QuotaStoreFile, id)
+	if err != nil {
+		common.RespondError(c, claimErrorStatus(err), err)
+		return nil, nil, false
+	}
+	return r, cfg, true
+}
@@ -0,0 +1,135 @@
+package openshift
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/gin-gonic/gin"
+)
+
+func TestQuotaRequestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quotarequests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storeFile := filepath.Join(dir, "quotas.json")
+
+	command := common.QuotaIncreaseCommand{CPU: 8, Memory: 16, Justification: "Black Friday"}
+	command.ClusterId = "prod"
+	command.Project = "shop"
+	r := QuotaRequest{ID: "abc", Command: command, Username: "u111111", RequestedAt: time.Now().UTC()}
+	if err := addQuotaRequest(storeFile, r); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	r.ID = "def"
+	r.Command.Project = "SHOP"
+	if apiErr, ok := addQuotaRequest(storeFile, r).(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict {
+		t.Errorf("ERROR: only one request per project should be allowed, got: %v", apiErr)
+	}
+
+	stored, err := claimQuotaRequest(storeFile, "abc")
+	if err != nil || stored.Command.CPU != 8 || stored.Command.Justification != "Black Friday" {
+		t.Fatalf("ERROR: unexpected request %+v (error: %v)", stored, err)
+	}
+	// A request can only be processed by one approver at a time
+	if _, err := claimQuotaRequest(storeFile, "abc"); claimErrorStatus(err) != http.StatusConflict {
+		t.Errorf("ERROR: a claimed request should return a conflict, got: %v", err)
+	}
+	releaseQuotaRequest("abc")
+	if err := removeQuotaRequest(storeFile, "abc"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if _, err := claimQuotaRequest(storeFile, "abc"); claimErrorStatus(err) != http.StatusNotFound {
+		t.Errorf("ERROR: removed request should not exist, got: %v", err)
+	}
+}
+
+func TestApproveQuotaRequestHandler(t *testing.T) {
+	dir, err := ioutil.TempDir("", "quotarequests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	storeFile := filepath.Join(dir, "quotas.json")
+
+	updates := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			updates++
+		}
+		// the quota was raised directly after the request
+		w.Write([]byte(`{"items": [{"metadata": {"name": "quota"}, "spec": {"hard": {"cpu": "8", "memory": "16Gi"}}}]}`))
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("openshift", []map[string]interface{}{{"id": "test", "url": srv.URL, "token": "token", "quota": map[string]interface{}{"max_cpu": 10, "max_memory": 20}}})
+	config.Config().Set("project_approval", map[string]interface{}{"approver_group": "DG_APPROVERS", "quota_store_file": storeFile})
+	ldapGroupsOfUser = func(username string) ([]string, error) {
+		return []string{"DG_APPROVERS"}, nil
+	}
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+
+	command := common.QuotaIncreaseCommand{CPU: 8, Memory: 16, Justification: "Black Friday"}
+	command.ClusterId = "test"
+	command.Project = "shop"
+	r := QuotaRequest{ID: "abc", Command: command, Username: "u111111", Current: newQuota(2, 4), Requested: newQuota(8, 16)}
+	if err := addQuotaRequest(storeFile, r); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	approve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(keycloak.APITokenUserKey, "u123456")
+		c.Request = httptest.NewRequest("POST", "/ose/quotas/requests/approve", strings.NewReader(`{"id": "abc"}`))
+		approveQuotaRequestHandler(c)
+		return w
+	}
+
+	if _, err := claimQuotaRequest(storeFile, "abc"); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if w := approve(); w.Code != http.StatusConflict || updates != 0 {
+		t.Errorf("ERROR: a request which is being processed should return 409, got %v: %v", w.Code, w.Body.String())
+	}
+	releaseQuotaRequest("abc")
+
+	if w := approve(); w.Code != http.StatusUnprocessableEntity || updates != 0 {
+		t.Errorf("ERROR: a request which is no longer an increase should be refused, got %v: %v", w.Code, w.Body.String())
+	}
+	requests, err := loadQuotaRequests(storeFile)
+	if err != nil || len(requests) != 1 {
+		t.Errorf("ERROR: a refused approval should keep the request, got %v (error: %v)", requests, err)
+	}
+}
+
+func TestValidateQuotaIncrease(t *testing.T) {
+	var testsets = []struct {
+		current   Quota
+		requested Quota
+		valid     bool
+	}{
+		{newQuota(2, 4), newQuota(4, 8), true},
+		{newQuota(2, 4), newQuota(2, 8), true},
+		{newQuota(2, 4), newQuota(2, 4), false},
+		{newQuota(2, 4), newQuota(1, 8), false},
+		{Quota{}, newQuota(1, 1), true},
+	}
+	for _, set := range testsets {
+		err := validateQuotaIncrease(set.current, set.requested)
+		if set.valid != (err == nil) {
+			t.Errorf("ERROR: %+v -> %+v should be valid: %v, got: %v", set.current, set.requested, set.valid, err)
+		}
+	}
+}
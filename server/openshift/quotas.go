@@ -5,7 +5,11 @@ import (
 	"errors"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
+	"sort"
+	"strings"
+	"time"
 
 	"fmt"
 
@@ -17,7 +21,6 @@ import (
 
 const (
 	getQuotasApiError = "Error getting quotas from ose-api: %v"
-	jsonDecodingError = "Error decoding json from ose api: %v"
 )
 
 func getQuotasHandler(c *gin.Context) {
@@ -47,26 +50,31 @@ func getQuotas(clusterId, project string) (*gabs.Container, error) {
 	}
 	defer resp.Body.Close()
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Printf(jsonDecodingError, err)
-		return nil, errors.New(genericAPIError)
+		return nil, err
 	}
 
 	return json.S("items").Index(0), nil
 }
 
+// editQuotasHandler sets the quota of a project directly. Only approvers and portal admins can use it,
+// project admins request a higher quota with POST /ose/quotas/requests.
 func editQuotasHandler(c *gin.Context) {
 	username := common.GetUserName(c)
 
 	var data common.EditQuotasCommand
 	if c.BindJSON(&data) == nil {
-		if err := validateEditQuotas(data.ClusterId, username, data.Project, data.CPU, data.Memory); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		if err := validateQuotaEditor(username); err != nil {
+			common.RespondError(c, http.StatusForbidden, err)
+			return
+		}
+		if err := validateQuotaValues(data.ClusterId, data.Project, data.CPU, data.Memory); err != nil {
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
-		if err := updateQuotas(data.ClusterId, username, data.Project, data.CPU, data.Memory); err != nil {
+		if err := updateQuotas(data.ClusterId, username, data.Project, data.CPU, data.Memory, ""); err != nil {
 			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
 		} else {
 			c.JSON(http.StatusOK, common.ApiResponse{
@@ -79,43 +87,143 @@ func editQuotasHandler(c *gin.Context) {
 	}
 }
 
-func validateEditQuotas(clusterId, username, project string, cpu int, memory int) error {
+func getQuotaLimitsHandler(c *gin.Context) {
+	clusterId := c.Request.URL.Query().Get("clusterid")
+	if clusterId == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		return
+	}
+
+	quotaConfig, err := getQuotaConfig(clusterId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, QuotaLimits{
+		Default: newQuota(quotaConfig.DefaultCPU, quotaConfig.DefaultMemory),
+		Max:     newQuota(quotaConfig.MaxCPU, quotaConfig.MaxMemory),
+	})
+}
+
+type QuotaLimits struct {
+	Default Quota `json:"default"`
+	Max     Quota `json:"max"`
+}
+
+type Quota struct {
+	CPUMillicores int64 `json:"cpuMillicores"`
+	MemoryBytes   int64 `json:"memoryBytes"`
+}
+
+func newQuota(cpuCores, memoryGiB int) Quota {
+	return Quota{
+		CPUMillicores: int64(cpuCores) * 1000,
+		MemoryBytes:   int64(memoryGiB) * 1024 * 1024 * 1024,
+	}
+}
+
+// getQuotaConfig returns the quota config of the cluster.
+// Values which are not set for the cluster are taken from the global config.
+func getQuotaConfig(clusterId string) (*QuotaConfig, error) {
+	cluster, err := getOpenshiftCluster(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	quotaConfig := QuotaConfig{}
+	if cluster.Quota != nil {
+		quotaConfig = *cluster.Quota
+	}
+
 	cfg := config.Config()
-	maxCPU := cfg.GetInt("max_quota_cpu")
-	maxMemory := cfg.GetInt("max_quota_memory")
+	if quotaConfig.DefaultCPU == 0 {
+		quotaConfig.DefaultCPU = cfg.GetInt("default_quota_cpu")
+	}
+	if quotaConfig.DefaultMemory == 0 {
+		quotaConfig.DefaultMemory = cfg.GetInt("default_quota_memory")
+	}
+	if quotaConfig.MaxCPU == 0 {
+		quotaConfig.MaxCPU = cfg.GetInt("max_quota_cpu")
+	}
+	if quotaConfig.MaxMemory == 0 {
+		quotaConfig.MaxMemory = cfg.GetInt("max_quota_memory")
+	}
 
-	if maxCPU == 0 || maxMemory == 0 {
+	if quotaConfig.MaxCPU == 0 || quotaConfig.MaxMemory == 0 {
 		log.Println("WARNING: Env variables 'MAX_QUOTA_MEMORY' and 'MAX_QUOTA_CPU' must be specified and valid integers")
-		return errors.New(common.ConfigNotSetError)
+		return nil, errors.New(common.ConfigNotSetError)
+	}
+	return &quotaConfig, nil
+}
+
+func validateEditQuotas(clusterId, username, project string, cpu int, memory int) error {
+	if err := validateQuotaValues(clusterId, project, cpu, memory); err != nil {
+		return err
+	}
+
+	// Validate permissions
+	return checkAdminPermissions(clusterId, username, project)
+}
+
+// validateQuotaEditor checks if the user is in the approver_group of project_approval or the admin_group
+func validateQuotaEditor(username string) error {
+	var groups []string
+	for _, key := range []string{"project_approval.approver_group", "admin_group"} {
+		if group := config.Config().GetString(key); group != "" {
+			groups = append(groups, group)
+		}
 	}
+	if len(groups) > 0 {
+		isEditor, err := isInAnyLdapGroup(username, groups)
+		if err != nil {
+			return err
+		}
+		if isEditor {
+			return nil
+		}
+	}
+	return common.NewApiError(common.ErrorCodeForbidden, "Only approvers can change quotas directly. Please request a higher quota")
+}
 
+// validateQuotaValues checks the quota against the limits of the cluster
+func validateQuotaValues(clusterId, project string, cpu int, memory int) error {
 	// Validate user input
 	if clusterId == "" {
 		return errors.New("Cluster must be provided")
 	}
 
+	quotaConfig, err := getQuotaConfig(clusterId)
+	if err != nil {
+		return err
+	}
+	maxCPU := quotaConfig.MaxCPU
+	maxMemory := quotaConfig.MaxMemory
+
 	if project == "" {
 		return errors.New("Project must be provided")
 	}
 
 	if cpu > maxCPU {
-		return fmt.Errorf("The maximal value for CPU cores: %v", maxCPU)
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The maximal value for CPU cores: %v", maxCPU))
 	}
 
 	if memory > maxMemory {
-		return fmt.Errorf("The maximal value for memory: %v", maxMemory)
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The maximal value for memory: %v", maxMemory))
 	}
-
-	// Validate permissions
-	resp := checkAdminPermissions(clusterId, username, project)
-	return resp
+	return nil
 }
 
-func updateQuotas(clusterId, username, project string, cpu int, memory int) error {
+// updateQuotas sets the CPU cores and memory in GiB of the ResourceQuota
+// and adds the change with the reason to the quota history
+func updateQuotas(clusterId, username, project string, cpu int, memory int, reason string) error {
 	quotas, err := getQuotas(clusterId, project)
 	if err != nil {
 		return err
 	}
+	oldQuota, err := quotaOf(quotas)
+	if err != nil {
+		return err
+	}
 	quotas.SetP(cpu, "spec.hard.cpu")
 	quotas.SetP(fmt.Sprintf("%vGi", memory), "spec.hard.memory")
 
@@ -131,8 +239,92 @@ func updateQuotas(clusterId, username, project string, cpu int, memory int) erro
 	if resp.StatusCode != http.StatusOK {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Println("Error updating resourceQuota:", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("PUT", resp.StatusCode, errMsg)
 	}
 	log.Printf("User %v changed quotas for the project %v on cluster %v. CPU: %v Mem: %v", username, clusterId, project, cpu, memory)
+
+	// The quota is already changed, so a missing history entry is only logged
+	updated := newQuota(cpu, memory)
+	if err := patchNamespaceAnnotations(clusterId, project, func(namespace *gabs.Container) {
+		appendQuotaHistory(namespace, oldQuota, updated, username, reason, time.Now())
+	}); err != nil {
+		log.Printf("Error writing the quota history of project %v on cluster %v: %v", project, clusterId, err)
+	}
 	return nil
 }
+
+// quotaOf returns the CPU and memory of the hard limits of the ResourceQuota
+func quotaOf(quota *gabs.Container) (Quota, error) {
+	if quota == nil {
+		return Quota{}, nil
+	}
+	hard := quota.Path("spec.hard")
+	cpu, err := quotaValue(hard, "cpu", "limits.cpu", "requests.cpu")
+	if err != nil {
+		return Quota{}, err
+	}
+	memory, err := quotaValue(hard, "memory", "limits.memory", "requests.memory")
+	if err != nil {
+		return Quota{}, err
+	}
+	return Quota{CPUMillicores: int64(math.Round(cpu * 1000)), MemoryBytes: int64(memory)}, nil
+}
+
+// QuotaTier is a named quota for new projects (quota_tiers in the config)
+type QuotaTier struct {
+	// CPU in cores, memory in GiB
+	CPU    int `mapstructure:"cpu"`
+	Memory int `mapstructure:"memory"`
+	// LDAP groups which can use the tier. Empty means all users
+	Groups []string `mapstructure:"groups"`
+}
+
+func getQuotaTiers() map[string]QuotaTier {
+	tiers := map[string]QuotaTier{}
+	if err := config.UnmarshalKey("quota_tiers", &tiers); err != nil {
+		log.Printf("WARNING: invalid quota_tiers config: %v", err)
+	}
+	return tiers
+}
+
+// getQuotaTier returns the tier if it exists and the user is allowed to use it
+func getQuotaTier(username, name string) (*QuotaTier, error) {
+	tiers := getQuotaTiers()
+	tier, ok := tiers[strings.ToLower(name)]
+	if !ok {
+		var names []string
+		for n := range tiers {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, common.NewValidationError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The quota tier %v does not exist. Valid tiers: %v", name, strings.Join(names, ", ")))
+	}
+
+	if len(tier.Groups) > 0 {
+		allowed, err := isInAnyLdapGroup(username, tier.Groups)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, common.NewApiError(common.ErrorCodeForbidden, fmt.Sprintf("You are not allowed to use the quota tier %v", name))
+		}
+	}
+	return &tier, nil
+}
+
+func validateQuotaTier(username, name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := getQuotaTier(username, name)
+	return err
+}
+
+func applyQuotaTier(clusterId, username, project, name string) error {
+	tier, err := getQuotaTier(username, name)
+	if err != nil {
+		return err
+	}
+	return updateQuotas(clusterId, username, project, tier.CPU, tier.Memory, "Quota tier "+strings.ToLower(name))
+}
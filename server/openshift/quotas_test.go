@@ -0,0 +1,70 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetQuotaTier(t *testing.T) {
+	config.Init("bla")
+	err := config.Config().ReadConfig(strings.NewReader(`
+quota_tiers:
+  small:
+    cpu: 2
+    memory: 4
+  Large:
+    cpu: 8
+    memory: 16
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tier, err := getQuotaTier("u123456", "Small")
+	if err != nil || tier.CPU != 2 || tier.Memory != 4 {
+		t.Errorf("ERROR: unexpected tier %+v, error: %v", tier, err)
+	}
+
+	_, err = getQuotaTier("u123456", "huge")
+	if err == nil || !strings.HasSuffix(err.Error(), "Valid tiers: large, small") {
+		t.Errorf("ERROR: unknown tier should list the valid tiers, got: %v", err)
+	}
+}
+
+func TestEditQuotasHandlerOnlyForApprovers(t *testing.T) {
+	config.Init("bla")
+	config.Config().Set("admin_group", "DG_PORTAL_ADMINS")
+	config.Config().Set("project_approval.approver_group", "DG_APPROVERS")
+	config.Config().Set("openshift", []map[string]interface{}{{"id": "test", "quota": map[string]interface{}{"max_cpu": 10, "max_memory": 20}}})
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+
+	gin.SetMode(gin.TestMode)
+	edit := func(groups []string, body string) *httptest.ResponseRecorder {
+		ldapGroupsOfUser = func(username string) ([]string, error) {
+			return groups, nil
+		}
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(keycloak.APITokenUserKey, "u123456")
+		c.Request = httptest.NewRequest("POST", "/ose/quotas", strings.NewReader(body))
+		editQuotasHandler(c)
+		return w
+	}
+
+	// project admins have to request a higher quota
+	w := edit([]string{"DG_PROJECT_ADMINS"}, `{"clusterid": "test", "project": "project", "cpu": 4, "memory": 8}`)
+	if w.Code != http.StatusForbidden || !strings.Contains(w.Body.String(), common.ErrorCodeForbidden) {
+		t.Errorf("ERROR: other users should get 403, got %v: %v", w.Code, w.Body.String())
+	}
+	w = edit([]string{"dg_approvers"}, `{"clusterid": "test", "project": "project", "cpu": 11, "memory": 8}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("ERROR: approvers should be limited by the cluster, got %v: %v", w.Code, w.Body.String())
+	}
+}
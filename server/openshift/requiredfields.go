@@ -0,0 +1,87 @@
+package openshift
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+// Names of the fields in required_project_fields and required_test_project_fields (as in the JSON of the commands)
+const (
+	fieldBilling        = "billing"
+	fieldMegaId         = "megaId"
+	fieldOwnerGroup     = "ownerGroup"
+	fieldClassification = "classification"
+)
+
+var projectFieldNames = []string{fieldBilling, fieldMegaId, fieldOwnerGroup, fieldClassification}
+
+var (
+	defaultRequiredProjectFields     = []string{fieldBilling, fieldClassification}
+	defaultRequiredTestProjectFields = []string{fieldBilling}
+)
+
+// projectFields is the metadata of a project which can be required in the config
+type projectFields struct {
+	Billing        string
+	MegaId         string
+	OwnerGroup     string
+	Classification string
+}
+
+func (f projectFields) get(name string) string {
+	switch name {
+	case fieldBilling:
+		return f.Billing
+	case fieldMegaId:
+		return f.MegaId
+	case fieldOwnerGroup:
+		return f.OwnerGroup
+	case fieldClassification:
+		return f.Classification
+	}
+	return ""
+}
+
+// getRequiredProjectFields returns required_project_fields or, for test projects, required_test_project_fields
+func getRequiredProjectFields(testProject bool) []string {
+	key, fields := "required_project_fields", defaultRequiredProjectFields
+	if testProject {
+		key, fields = "required_test_project_fields", defaultRequiredTestProjectFields
+	}
+	if config.Config().IsSet(key) {
+		fields = config.Config().GetStringSlice(key)
+	}
+	return fields
+}
+
+// ValidateRequiredProjectFields checks required_project_fields and required_test_project_fields at startup
+func ValidateRequiredProjectFields() error {
+	for _, testProject := range []bool{false, true} {
+		for _, field := range getRequiredProjectFields(testProject) {
+			if !common.ContainsStringI(projectFieldNames, field) {
+				return fmt.Errorf("Unknown required project field %v. Allowed values: %v", field, strings.Join(projectFieldNames, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// validateRequiredProjectFields returns an error with all required fields which are empty
+func validateRequiredProjectFields(fields projectFields, testProject bool) error {
+	var missing []string
+	for _, required := range getRequiredProjectFields(testProject) {
+		for _, name := range projectFieldNames {
+			if strings.EqualFold(required, name) && strings.TrimSpace(fields.get(name)) == "" {
+				missing = append(missing, name)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return common.NewApiError(common.ErrorCodeInvalidRequest,
+			fmt.Sprintf("The following fields must be provided: %v", strings.Join(missing, ", ")))
+	}
+	return nil
+}
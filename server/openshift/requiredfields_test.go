@@ -0,0 +1,34 @@
+package openshift
+
+import (
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateRequiredProjectFields(t *testing.T) {
+	config.Init("bla")
+	if err := validateRequiredProjectFields(projectFields{Billing: "5678", Classification: "internal"}, false); err != nil {
+		t.Errorf("ERROR: billing and classification should be enough by default, got: %v", err)
+	}
+
+	config.Config().Set("required_project_fields", []string{"billing", "megaId", "ownerGroup", "classification"})
+	config.Config().Set("required_test_project_fields", []string{})
+	err := validateRequiredProjectFields(projectFields{Billing: "5678", OwnerGroup: " "}, false)
+	apiErr, ok := err.(*common.ApiError)
+	if !ok || apiErr.Code != common.ErrorCodeInvalidRequest || apiErr.Message != "The following fields must be provided: megaId, ownerGroup, classification" {
+		t.Errorf("ERROR: all missing fields should be returned, got: %v", err)
+	}
+	if err := validateRequiredProjectFields(projectFields{}, true); err != nil {
+		t.Errorf("ERROR: test projects should use required_test_project_fields, got: %v", err)
+	}
+	if err := ValidateRequiredProjectFields(); err != nil {
+		t.Errorf("ERROR: unexpected error: %v", err)
+	}
+
+	config.Config().Set("required_project_fields", []string{"billing", "costcenter"})
+	if err := ValidateRequiredProjectFields(); err == nil {
+		t.Error("ERROR: unknown fields should be invalid")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
 	"fmt"
 
@@ -39,64 +40,174 @@ func newPullSecretHandler(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
 		return
 	}
-	secret := newObjectRequest("Secret", "external-registry", "v1")
+	secret := newPullSecret(defaultPullSecretName, dockerRepository, data.Username, data.Password)
+	if err := createSecret(data.ClusterId, data.Project, secret); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := addPullSecretToServiceaccount(data.ClusterId, data.Project, "default", defaultPullSecretName); err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+	log.Printf("%v created a new pull secret to default serviceaccount on project %v on cluster %v", username, data.Project, data.ClusterId)
+	c.JSON(http.StatusOK, common.ApiResponse{Message: "Das Pull-Secret wurde angelegt"})
+}
+
+const defaultPullSecretName = "external-registry"
+
+// newPullSecret returns a dockerconfigjson secret with the credentials for the registry
+func newPullSecret(name, registry, username, password string) *gabs.Container {
+	secret := newObjectRequest("Secret", name, "v1")
 	dockerConfig := DockerConfig{
 		Auths: make(map[string]*Auth),
 	}
 	auth := Auth{
-		Auth: []byte(fmt.Sprintf("%v:%v", data.Username, data.Password)),
+		Auth: []byte(fmt.Sprintf("%v:%v", username, password)),
 	}
-	dockerConfig.Auths[dockerRepository] = &auth
+	dockerConfig.Auths[registry] = &auth
 	secretData, _ := json.Marshal(dockerConfig)
 
 	secret.Set(secretData, "data", ".dockerconfigjson")
 	secret.Set("kubernetes.io/dockerconfigjson", "type")
-	if err := createSecret(data.ClusterId, data.Project, secret); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
-		return
+	return secret
+}
+
+type projectPullSecretConfig struct {
+	Enabled  bool
+	Name     string
+	Registry string
+	Username string
+	Password string
+}
+
+func getProjectPullSecretConfig() (projectPullSecretConfig, error) {
+	cfg := projectPullSecretConfig{}
+	if err := config.UnmarshalKey("project_pull_secret", &cfg); err != nil {
+		return cfg, fmt.Errorf("Invalid project_pull_secret: %v", err)
 	}
-	if err := addPullSecretToServiceaccount(data.ClusterId, data.Project, "default"); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
-		return
+	if !cfg.Enabled {
+		return cfg, nil
 	}
-	log.Printf("%v created a new pull secret to default serviceaccount on project %v on cluster %v", username, data.Project, data.ClusterId)
-	c.JSON(http.StatusOK, common.ApiResponse{Message: "Das Pull-Secret wurde angelegt"})
+	if cfg.Registry == "" || cfg.Username == "" || cfg.Password == "" {
+		return cfg, fmt.Errorf("project_pull_secret.registry, username and password must be specified")
+	}
+	if cfg.Name == "" {
+		cfg.Name = defaultPullSecretName
+	}
+	return cfg, nil
 }
 
-func addPullSecretToServiceaccount(clusterId, namespace string, serviceaccount string) error {
-	url := fmt.Sprintf("api/v1/namespaces/%v/serviceaccounts/%v", namespace, serviceaccount)
-	patch := []common.JsonPatch{
-		{
-			Operation: "add",
-			Path:      "/imagePullSecrets/-",
-			Value: struct {
-				Name string `json:"name"`
-			}{
-				Name: "external-registry",
-			},
-		},
-	}
-
-	patchBytes, err := json.Marshal(patch)
+// ValidateProjectPullSecret checks project_pull_secret at startup, because the pull secret
+// is only created after the project
+func ValidateProjectPullSecret() error {
+	_, err := getProjectPullSecretConfig()
+	return err
+}
+
+// createProjectPullSecret creates the pull secret of project_pull_secret in a new project
+// and links it to the default service account. An existing secret or link is kept.
+// A failed link is only logged, because the project was already created.
+func createProjectPullSecret(clusterId, project string) error {
+	cfg, err := getProjectPullSecretConfig()
 	if err != nil {
-		log.Printf("Error marshalling patch: %v", err)
-		return errors.New(genericAPIError)
+		log.Printf("WARNING: %v", err)
+		return errors.New(common.ConfigNotSetError)
+	}
+	if !cfg.Enabled {
+		return nil
 	}
 
-	resp, err := getOseHTTPClient("PATCH", clusterId, url, bytes.NewBuffer(patchBytes))
-	if err != nil {
+	err = createSecret(clusterId, project, newPullSecret(cfg.Name, cfg.Registry, cfg.Username, cfg.Password))
+	if apiErr, ok := err.(*common.ApiError); ok && apiErr.Code == common.ErrorCodeConflict {
+		log.Printf("Pull secret %v already exists in project %v on cluster %v", cfg.Name, project, clusterId)
+	} else if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := ioutil.ReadAll(resp.Body)
-		log.Printf("Error adding pull secret to service account on cluster %v: StatusCode: %v, Nachricht: %v", clusterId, resp.StatusCode, string(bodyBytes))
-		return errors.New(genericAPIError)
+	// The project is already created, so a missing link only means that images of the registry can't be pulled yet
+	added, err := addPullSecretToServiceaccount(clusterId, project, "default", cfg.Name)
+	if err != nil {
+		log.Printf("WARNING: Pull secret %v couldn't be linked to the default service account in project %v on cluster %v: %v", cfg.Name, project, clusterId, err)
+		return nil
+	}
+	if added {
+		log.Printf("Pull secret %v was created in project %v on cluster %v", cfg.Name, project, clusterId)
 	}
-
 	return nil
+}
+
+// Number of attempts and interval to wait for a service account, which is created by OpenShift after the project
+const serviceAccountPollAttempts = 10
+
+var serviceAccountPollInterval = 500 * time.Millisecond
 
+// waitForServiceAccount returns the service account. A missing service account is read again,
+// because OpenShift creates the default service accounts shortly after the project.
+func waitForServiceAccount(clusterId, namespace, serviceaccount string) (*gabs.Container, error) {
+	url := fmt.Sprintf("api/v1/namespaces/%v/serviceaccounts/%v", namespace, serviceaccount)
+	for attempt := 1; ; attempt++ {
+		resp, err := getOseHTTPClient("GET", clusterId, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusNotFound && attempt < serviceAccountPollAttempts {
+			resp.Body.Close()
+			time.Sleep(serviceAccountPollInterval)
+			continue
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := ioutil.ReadAll(resp.Body)
+			log.Printf("Error getting service account %v on cluster %v: StatusCode: %v", serviceaccount, clusterId, resp.StatusCode)
+			return nil, newUpstreamError("GET", resp.StatusCode, bodyBytes)
+		}
+		return parseJSONResponse(resp)
+	}
+}
+
+// addPullSecretToServiceaccount adds the secret to the imagePullSecrets of the service account.
+// It returns false if the secret was already linked. The merge patch contains the whole list,
+// so it works if the service account has no imagePullSecrets yet. The resourceVersion makes
+// concurrent changes fail with a conflict, which is retried.
+func addPullSecretToServiceaccount(clusterId, namespace, serviceaccount, secret string) (bool, error) {
+	url := fmt.Sprintf("api/v1/namespaces/%v/serviceaccounts/%v", namespace, serviceaccount)
+	added := false
+	err := retryOnConflict(func() error {
+		sa, err := waitForServiceAccount(clusterId, namespace, serviceaccount)
+		if err != nil {
+			return err
+		}
+		pullSecrets := []interface{}{}
+		for _, pullSecret := range sa.S("imagePullSecrets").Children() {
+			if pullSecret.S("name").Data() == secret {
+				return nil
+			}
+			pullSecrets = append(pullSecrets, pullSecret.Data())
+		}
+		pullSecrets = append(pullSecrets, map[string]interface{}{"name": secret})
+
+		patch := gabs.New()
+		if resourceVersion, ok := sa.Path("metadata.resourceVersion").Data().(string); ok {
+			patch.Set(resourceVersion, "metadata", "resourceVersion")
+		}
+		patch.Set(pullSecrets, "imagePullSecrets")
+
+		resp, err := doOseRequest("PATCH", "application/merge-patch+json", clusterId, url, bytes.NewReader(patch.Bytes()))
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			bodyBytes, _ := ioutil.ReadAll(resp.Body)
+			log.Printf("Error adding pull secret to service account on cluster %v: StatusCode: %v, Nachricht: %v", clusterId, resp.StatusCode, string(bodyBytes))
+			return newUpstreamError("PATCH", resp.StatusCode, bodyBytes)
+		}
+		added = true
+		return nil
+	})
+	return added, err
 }
 
 func createSecret(clusterId, namespace string, secret *gabs.Container) error {
@@ -111,11 +222,17 @@ func createSecret(clusterId, namespace string, secret *gabs.Container) error {
 	if resp.StatusCode == http.StatusForbidden {
 		bodyBytes, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating secret on cluster %v: StatusCode: %v, Nachricht: %v", clusterId, resp.StatusCode, string(bodyBytes))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, bodyBytes)
 	}
 
 	if resp.StatusCode == http.StatusConflict {
-		return errors.New("The secret already exists")
+		return common.NewApiError(common.ErrorCodeConflict, "The secret already exists")
+	}
+
+	if resp.StatusCode != http.StatusCreated {
+		bodyBytes, _ := ioutil.ReadAll(resp.Body)
+		log.Printf("Error creating secret on cluster %v: StatusCode: %v", clusterId, resp.StatusCode)
+		return newUpstreamError("POST", resp.StatusCode, bodyBytes)
 	}
 
 	return nil
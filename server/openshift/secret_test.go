@@ -0,0 +1,144 @@
+package openshift
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestCreateProjectPullSecret(t *testing.T) {
+	var secretExists, linked bool
+	var patched int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/namespaces/project/secrets":
+			if secretExists {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			secret, _ := gabs.ParseJSONBuffer(r.Body)
+			if secret.Path("metadata.name").Data() != "registry" || secret.S("type").Data() != "kubernetes.io/dockerconfigjson" {
+				t.Errorf("ERROR: unexpected secret: %v", secret)
+			}
+			secretExists = true
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v1/namespaces/project/serviceaccounts/default":
+			if linked {
+				w.Write([]byte(`{"imagePullSecrets": [{"name": "default-dockercfg-abc"}, {"name": "registry"}]}`))
+			} else {
+				w.Write([]byte(`{"imagePullSecrets": [{"name": "default-dockercfg-abc"}]}`))
+			}
+		case "PATCH /api/v1/namespaces/project/serviceaccounts/default":
+			patch, _ := gabs.ParseJSONBuffer(r.Body)
+			if r.Header.Get("Content-Type") != "application/merge-patch+json" || len(patch.S("imagePullSecrets").Children()) != 2 {
+				t.Errorf("ERROR: the merge patch should contain all pull secrets: %v", patch)
+			}
+			patched++
+			linked = true
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected request %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	if err := createProjectPullSecret("test", "project"); err != nil || secretExists {
+		t.Fatalf("ERROR: pull secret should only be created if enabled, error: %v", err)
+	}
+
+	config.Config().Set("project_pull_secret", map[string]interface{}{"enabled": true, "registry": "registry.example.com"})
+	if err := ValidateProjectPullSecret(); err == nil {
+		t.Error("ERROR: a pull secret without credentials should be invalid")
+	}
+	config.Config().Set("project_pull_secret", "enabled")
+	if err := ValidateProjectPullSecret(); err == nil {
+		t.Error("ERROR: an invalid project_pull_secret should fail")
+	}
+
+	config.Config().Set("project_pull_secret", map[string]interface{}{
+		"enabled":  true,
+		"name":     "registry",
+		"registry": "registry.example.com",
+		"username": "robot",
+		"password": "verysecretpassword",
+	})
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+
+	// the second call must not fail or link the secret twice
+	for i := 0; i < 2; i++ {
+		if err := createProjectPullSecret("test", "project"); err != nil {
+			t.Fatalf("ERROR: unexpected error: %v", err)
+		}
+	}
+	if !secretExists || patched != 1 {
+		t.Errorf("ERROR: secret should be created and linked once, patched: %v", patched)
+	}
+	if strings.Contains(logs.String(), "verysecretpassword") {
+		t.Error("ERROR: credentials must not be logged")
+	}
+}
+
+func TestCreateProjectPullSecretWaitsForServiceAccount(t *testing.T) {
+	serviceAccountPollInterval = time.Millisecond
+	defer func() { serviceAccountPollInterval = 500 * time.Millisecond }()
+
+	var reads int
+	patchStatus := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/namespaces/project/secrets":
+			w.WriteHeader(http.StatusCreated)
+		case "GET /api/v1/namespaces/project/serviceaccounts/default":
+			reads++
+			// OpenShift creates the default service account after the project
+			if reads < 3 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"metadata": {"name": "default", "resourceVersion": "42"}}`))
+		case "PATCH /api/v1/namespaces/project/serviceaccounts/default":
+			patch, _ := gabs.ParseJSONBuffer(r.Body)
+			if patch.Path("metadata.resourceVersion").Data() != "42" || patch.Path("imagePullSecrets.0.name").Data() != "registry" {
+				t.Errorf("ERROR: the merge patch should create the imagePullSecrets: %v", patch)
+			}
+			w.WriteHeader(patchStatus)
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected request %v %v", r.Method, r.URL.Path)
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	config.Config().Set("project_pull_secret", map[string]interface{}{
+		"enabled":  true,
+		"name":     "registry",
+		"registry": "registry.example.com",
+		"username": "robot",
+		"password": "verysecretpassword",
+	})
+
+	added, err := addPullSecretToServiceaccount("test", "project", "default", "registry")
+	if err != nil || !added || reads != 3 {
+		t.Errorf("ERROR: the pull secret should be linked once the service account exists, added: %v, reads: %v, error: %v", added, reads, err)
+	}
+
+	// the project exists already, so a failed link must not fail the creation
+	reads = 0
+	patchStatus = http.StatusInternalServerError
+	if err := createProjectPullSecret("test", "project"); err != nil {
+		t.Errorf("ERROR: a failed link should only be logged, but got: %v", err)
+	}
+}
@@ -40,17 +40,17 @@ func newServiceAccountHandler(c *gin.Context) {
 	}
 
 	if err := validateNewServiceAccount(data.ClusterId, username, data.Project, data.ServiceAccount); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := createNewServiceAccount(data.ClusterId, username, data.Project, data.ServiceAccount); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	if err := authorizeServiceAccount(data.ClusterId, data.Project, data.ServiceAccount); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -72,6 +72,63 @@ func newServiceAccountHandler(c *gin.Context) {
 	}
 }
 
+func newServiceAccountTokenHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.NewServiceAccountTokenCommand
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if len(data.ServiceAccount) == 0 {
+		common.RespondError(c, http.StatusBadRequest, common.NewApiError(common.ErrorCodeInvalidRequest, "You have to create a service account"))
+		return
+	}
+
+	// Tokens are sensitive, so they are only issued to portal admins which are admins of the project
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	if err := validateAdminAccess(data.ClusterId, username, data.Project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The service account, the rolebinding and the token secret are reused, so the token can be requested again
+	if err := createNewServiceAccount(data.ClusterId, username, data.Project, data.ServiceAccount); err != nil && !isConflict(err) {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// The edit rolebinding is namespaced, so the token is only valid in this project
+	if err := authorizeServiceAccount(data.ClusterId, data.Project, data.ServiceAccount); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	token, err := createServiceAccountToken(data.ClusterId, data.Project, data.ServiceAccount)
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	// Never log the token itself
+	log.WithFields(log.Fields{
+		"cluster":        data.ClusterId,
+		"username":       username,
+		"project":        data.Project,
+		"serviceaccount": data.ServiceAccount,
+	}).Info("AUDIT: Serviceaccount token was issued")
+
+	c.JSON(http.StatusOK, common.ServiceAccountTokenResponse{
+		ServiceAccount: data.ServiceAccount,
+		Token:          token,
+	})
+}
+
 func validateNewServiceAccount(clusterId, username string, project string, serviceAccountName string) error {
 	if len(serviceAccountName) == 0 {
 		return errors.New("You have to create a service account")
@@ -95,7 +152,7 @@ func createNewServiceAccount(clusterId, username, project, serviceaccount string
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusConflict {
-		return errors.New("Der Service-Account existiert bereits.")
+		return common.NewApiError(common.ErrorCodeConflict, "Der Service-Account existiert bereits.")
 	}
 
 	if resp.StatusCode != http.StatusCreated {
@@ -137,6 +194,12 @@ func authorizeServiceAccount(clusterId, namespace, serviceaccount string) error
 }
 
 func addEditServiceAccountToRoleBinding(clusterId, namespace, serviceaccount string, rolebinding *gabs.Container) error {
+	subjects, _ := rolebinding.S("subjects").Children()
+	for _, subject := range subjects {
+		if subject.S("kind").Data() == "ServiceAccount" && subject.S("name").Data() == serviceaccount {
+			return nil
+		}
+	}
 
 	service_account := OpenshiftSubject{
 		Kind:      "ServiceAccount",
@@ -230,7 +293,7 @@ func createEditRoleBinding(clusterId, namespace, serviceaccount string) error {
 	}
 
 	if resp.StatusCode == http.StatusConflict {
-		return errors.New("The role binding already exists")
+		return common.NewApiError(common.ErrorCodeConflict, "The role binding already exists")
 	}
 
 	log.WithFields(log.Fields{
@@ -281,7 +344,7 @@ func getSecret(clusterId, namespace, secret string) (*gabs.Container, error) {
 	if resp.StatusCode == http.StatusForbidden {
 		bodyBytes, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error getting secret: StatusCode: %v, Nachricht: %v", resp.StatusCode, string(bodyBytes))
-		return nil, errors.New(genericAPIError)
+		return nil, newUpstreamError("GET", resp.StatusCode, bodyBytes)
 	}
 
 	json, err := gabs.ParseJSONBuffer(resp.Body)
@@ -355,3 +418,62 @@ func createJenkinsCredential(clusterId, project, serviceaccount, organizationKey
 
 	return nil
 }
+
+// Interval in which the token secret is read until the token controller has populated it
+var serviceAccountTokenPollInterval = 400 * time.Millisecond
+
+// createServiceAccountToken creates a long-lived token secret for the service account
+// and waits until the token controller has filled in the token.
+// An existing token secret of the same service account is reused.
+func createServiceAccountToken(clusterId, namespace, serviceaccount string) (string, error) {
+	secretName := serviceaccount + "-ssp-token"
+	secret := newObjectRequest("Secret", secretName, "v1")
+	secret.Set(serviceaccount, "metadata", "annotations", "kubernetes.io/service-account.name")
+	secret.Set("kubernetes.io/service-account-token", "type")
+	if err := createSecret(clusterId, namespace, secret); err != nil {
+		if !isConflict(err) {
+			return "", err
+		}
+		existing, err := getSecret(clusterId, namespace, secretName)
+		if err != nil {
+			return "", err
+		}
+		if existing.Path("type").Data() != "kubernetes.io/service-account-token" ||
+			existing.S("metadata", "annotations", "kubernetes.io/service-account.name").Data() != serviceaccount {
+			return "", common.NewApiError(common.ErrorCodeConflict, fmt.Sprintf("The secret %v already exists", secretName))
+		}
+	}
+
+	for i := 0; i < 10; i++ {
+		//Sleep which ensures that the token controller had time to populate the secret
+		time.Sleep(serviceAccountTokenPollInterval)
+
+		secretJson, err := getSecret(clusterId, namespace, secretName)
+		if err != nil {
+			return "", err
+		}
+		tokenEncoded, ok := secretJson.Path("data.token").Data().(string)
+		if !ok || tokenEncoded == "" {
+			continue
+		}
+		token, err := base64.StdEncoding.DecodeString(tokenEncoded)
+		if err != nil {
+			log.Println(err.Error())
+			return "", errors.New(genericAPIError)
+		}
+		return string(token), nil
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":        clusterId,
+		"namespace":      namespace,
+		"serviceaccount": serviceaccount,
+	}).Error("Token of serviceaccount secret was not populated")
+	return "", errors.New(genericAPIError)
+}
+
+// isConflict checks if the object already exists
+func isConflict(err error) bool {
+	apiErr, ok := err.(*common.ApiError)
+	return ok && apiErr.Code == common.ErrorCodeConflict
+}
@@ -0,0 +1,139 @@
+package openshift
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/keycloak"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeServiceAccountCluster keeps the service account, the edit rolebinding and the token secret between the requests
+type fakeServiceAccountCluster struct {
+	mu             sync.Mutex
+	serviceAccount bool
+	editSubjects   int
+	secret         string
+}
+
+func (f *fakeServiceAccountCluster) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.URL.Path == "/apis/rbac.authorization.k8s.io/v1/namespaces/project/rolebindings":
+		w.Write([]byte(`{"items": [{"metadata": {"name": "admin"}, "roleRef": {"name": "admin"}, "subjects": [{"kind": "User", "name": "u123456"}]}]}`))
+	case r.URL.Path == "/api/v1/namespaces/project/serviceaccounts":
+		if f.serviceAccount {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.serviceAccount = true
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/apis/rbac.authorization.k8s.io/v1/namespaces/project/rolebindings/edit":
+		if r.Method == "PUT" {
+			var rolebinding struct{ Subjects []interface{} }
+			json.NewDecoder(r.Body).Decode(&rolebinding)
+			f.editSubjects = len(rolebinding.Subjects)
+			return
+		}
+		if f.editSubjects == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"metadata": {"name": "edit"}, "subjects": [{"kind": "ServiceAccount", "name": "deployer", "namespace": "project"}]}`))
+	case r.URL.Path == "/apis/authorization.openshift.io/v1/namespaces/project/rolebindings":
+		f.editSubjects = 1
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/api/v1/namespaces/project/secrets":
+		if f.secret != "" {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		f.secret = "deployer"
+		w.WriteHeader(http.StatusCreated)
+	case r.URL.Path == "/api/v1/namespaces/project/secrets/deployer-ssp-token":
+		w.Write([]byte(`{"type": "kubernetes.io/service-account-token", "metadata": {"annotations": {"kubernetes.io/service-account.name": "` +
+			f.secret + `"}}, "data": {"token": "dG9rZW4="}}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestNewServiceAccountTokenHandler(t *testing.T) {
+	serviceAccountTokenPollInterval = time.Millisecond
+	fake := &fakeServiceAccountCluster{}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	gin.SetMode(gin.TestMode)
+	config.Config().Set("admin_group", "ssp-admins")
+	ldapGroupsOfUser = func(username string) ([]string, error) {
+		if username == "u999999" {
+			return []string{"developers"}, nil
+		}
+		return []string{"ssp-admins"}, nil
+	}
+	defer func() { ldapGroupsOfUser = getLdapGroupsOfUser }()
+
+	body := `{"clusterid": "test", "project": "project", "serviceAccount": "deployer"}`
+	// The second request reuses the service account, the rolebinding and the secret
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set(keycloak.APITokenUserKey, "u123456")
+		c.Request, _ = http.NewRequest("POST", "/ose/serviceaccount/token", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		newServiceAccountTokenHandler(c)
+
+		var response common.ServiceAccountTokenResponse
+		if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &response) != nil || response.Token != "token" {
+			t.Fatalf("ERROR: request %v should return the token, got %v: %v", i+1, w.Code, w.Body.String())
+		}
+	}
+	if fake.editSubjects != 1 {
+		t.Errorf("ERROR: the service account should be added to the edit rolebinding once, got %v subjects", fake.editSubjects)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set(keycloak.APITokenUserKey, "u654321")
+	c.Request, _ = http.NewRequest("POST", "/ose/serviceaccount/token", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	newServiceAccountTokenHandler(c)
+	var response common.ApiResponse
+	if json.Unmarshal(w.Body.Bytes(), &response) != nil || response.Code != common.ErrorCodeForbidden {
+		t.Errorf("ERROR: users without admin access should get FORBIDDEN, got %v: %v", w.Code, w.Body.String())
+	}
+
+	// project admins which are not in the admin_group
+	w = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Set(keycloak.APITokenUserKey, "u999999")
+	c.Request, _ = http.NewRequest("POST", "/ose/serviceaccount/token", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	newServiceAccountTokenHandler(c)
+	if w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), "token") {
+		t.Errorf("ERROR: users outside of the admin_group should get 403, got %v: %v", w.Code, w.Body.String())
+	}
+}
+
+func TestCreateServiceAccountTokenConflict(t *testing.T) {
+	serviceAccountTokenPollInterval = time.Millisecond
+	// The secret exists, but belongs to another service account
+	fake := &fakeServiceAccountCluster{secret: "other"}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	_, err := createServiceAccountToken("test", "project", "deployer")
+	if !isConflict(err) {
+		t.Errorf("ERROR: the secret of another service account must not be reused, got: %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package openshift
 
 import (
+	"bytes"
+	"context"
 	"crypto/tls"
 	"errors"
 	"fmt"
 	log "github.com/sirupsen/logrus"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Jeffail/gabs/v2"
@@ -21,21 +27,58 @@ const (
 	genericAPIError         = "Error when calling the OpenShift API. Please open a Jira issue"
 	wrongAPIUsageError      = "Invalid api call - parameters did not match to method definition"
 	testProjectDeletionDays = "30"
+	// Parallel requests of the batch admin check and the project information batch
+	maxParallelAdminChecks = 5
+	// Upper bound for waiting on a Retry-After header of OpenShift
+	maxRetryAfter = 5 * time.Second
 )
 
 // RegisterRoutes registers the routes for OpenShift
 func RegisterRoutes(r *gin.RouterGroup) {
 	// OpenShift
 	r.POST("/ose/project", newProjectHandler)
+	r.POST("/ose/project/manifest", newProjectFromManifestHandler)
+	r.POST("/ose/project/preview", previewProjectHandler)
 	r.GET("/ose/projects", getProjectsHandler)
+	r.GET("/ose/projects/billing", getProjectsByBillingHandler)
+	r.GET("/ose/projects/exists", getProjectExistenceHandler)
+	r.GET("/ose/projects/terminating", getTerminatingProjectsHandler)
+	r.POST("/ose/projects/cleanup", runArchiveCleanupHandler)
+	r.GET("/ose/projects/idle", getIdleProjectsHandler)
+	r.POST("/ose/projects/idle/archive", archiveIdleProjectsHandler)
+	r.GET("/ose/project/requests", getPendingProjectsHandler)
+	r.POST("/ose/project/requests/approve", approveProjectHandler)
+	r.POST("/ose/project/requests/reject", rejectProjectHandler)
 	r.GET("/ose/project/admins", getProjectAdminsHandler)
 	r.POST("/ose/project/admins", addProjectAdminHandler)
+	r.GET("/ose/project/rolebindings", getProjectRoleBindingsHandler)
+	r.GET("/ose/project/role", getProjectRoleHandler)
+	r.POST("/ose/project/groups", addProjectGroupHandler)
+	r.DELETE("/ose/project/groups", removeProjectGroupHandler)
+	r.POST("/ose/project/permissions/copy", copyProjectPermissionsHandler)
 	r.POST("/ose/testproject", newTestProjectHandler)
+	r.POST("/ose/testproject/extend", extendTestProjectHandler)
 	r.POST("/ose/serviceaccount", newServiceAccountHandler)
+	r.POST("/ose/serviceaccount/token", newServiceAccountTokenHandler)
 	r.GET("/ose/project/info", getProjectInformationHandler)
 	r.POST("/ose/project/info", updateProjectInformationHandler)
+	r.POST("/ose/projects/info", getProjectInformationBatchHandler)
+	r.POST("/ose/project/displayname", updateProjectDisplayNameHandler)
+	r.GET("/ose/project/billinghistory", getBillingHistoryHandler)
+	r.GET("/ose/project/events", getProjectEventsHandler)
+	r.POST("/ose/project/archive", archiveProjectHandler)
+	r.POST("/ose/project/unarchive", unarchiveProjectHandler)
 	r.GET("/ose/quotas", getQuotasHandler)
 	r.POST("/ose/quotas", editQuotasHandler)
+	r.GET("/ose/quotas/limits", getQuotaLimitsHandler)
+	r.POST("/ose/quotas/requests", requestQuotaIncreaseHandler)
+	r.GET("/ose/quotas/requests", getQuotaRequestsHandler)
+	r.POST("/ose/quotas/requests/approve", approveQuotaRequestHandler)
+	r.POST("/ose/quotas/requests/reject", rejectQuotaRequestHandler)
+	r.GET("/ose/quotas/history", getQuotaHistoryHandler)
+	r.GET("/ose/project/cost", getProjectCostHandler)
+	r.GET("/ose/project/export", exportProjectHandler)
+	r.GET("/ose/project/operators", getProjectOperatorsHandler)
 	r.POST("/ose/secret/pull", newPullSecretHandler)
 
 	// Volumes (Gluster and NFS)
@@ -48,15 +91,23 @@ func RegisterRoutes(r *gin.RouterGroup) {
 }
 
 func getProjectAdminsAndOperators(clusterId, project string) ([]string, []string, error) {
-	adminRoleBinding, err := getAdminRoleBinding(clusterId, project)
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		return nil, nil, err
+	}
+	return client.getProjectAdminsAndOperators(project)
+}
+
+func (o *oseClient) getProjectAdminsAndOperators(project string) ([]string, []string, error) {
+	adminRoleBinding, err := o.getAdminRoleBinding(project)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var admins []string
+	admins := []string{}
 	hasOperatorGroup := false
 	for _, g := range adminRoleBinding.Path("groupNames").Children() {
-		if strings.ToLower(g.Data().(string)) == "operator" {
+		if strings.EqualFold(g.Data().(string), operatorGroup) {
 			hasOperatorGroup = true
 		}
 	}
@@ -64,10 +115,10 @@ func getProjectAdminsAndOperators(clusterId, project string) ([]string, []string
 		admins = append(admins, strings.ToLower(u.Data().(string)))
 	}
 
-	var operators []string
+	operators := []string{}
 	if hasOperatorGroup {
 		// Going to add the operator group to the admins
-		json, err := getOperatorGroup(clusterId)
+		json, err := o.getOperatorGroup()
 		if err != nil {
 			return nil, nil, err
 		}
@@ -81,55 +132,93 @@ func getProjectAdminsAndOperators(clusterId, project string) ([]string, []string
 	return common.RemoveDuplicates(admins), operators, nil
 }
 
+// checkAdminPermissions checks if the user is admin of the project like getProjectRole:
+// admins, operators and members of the LDAP groups of the admin rolebindings have access.
 func checkAdminPermissions(clusterId, username, project string) error {
-	// Check if user has admin-access
-	hasAccess := false
-	admins, operators, err := getProjectAdminsAndOperators(clusterId, project)
+	client, err := newOseClient(clusterId)
 	if err != nil {
 		return err
 	}
+	return client.checkAdminPermissions(username, project)
+}
 
-	username = strings.ToLower(username)
-
-	// Access for admins
-	for _, a := range admins {
-		if username == a {
-			hasAccess = true
-		}
+func (o *oseClient) checkAdminPermissions(username, project string) error {
+	admins, operators, err := o.getProjectAdminsAndOperators(project)
+	if err != nil {
+		return err
 	}
-
-	// Access for operators
-	for _, o := range operators {
-		if username == o {
-			hasAccess = true
-		}
+	if common.ContainsStringI(admins, username) || common.ContainsStringI(operators, username) {
+		return nil
 	}
 
-	if hasAccess {
+	// The rolebindings are only read again for users who are not admin themselves
+	roleBindings, err := o.getRoleBindings(project)
+	if err != nil {
+		return err
+	}
+	role, err := effectiveRole(roleBindings, username, func(groups []string) (bool, error) {
+		return isInAnyLdapGroup(username, groups)
+	})
+	if err != nil {
+		return err
+	}
+	if role == "admin" {
 		return nil
 	}
 
-	return fmt.Errorf("You don't have admin permissions on the project: %v. The following users have admin permissions: %v", project, strings.Join(admins, ", "))
+	return common.NewApiError(common.ErrorCodeForbidden, fmt.Sprintf("You don't have admin permissions on the project: %v. The following users have admin permissions: %v", project, strings.Join(admins, ", ")))
 }
 
-func getOperatorGroup(clusterId string) (*gabs.Container, error) {
-	resp, err := getOseHTTPClient("GET", clusterId, "apis/user.openshift.io/v1/groups/operator", nil)
+// checkAdminPermissionsForProjects checks the admin permissions on multiple projects in parallel,
+// with at most maxParallelAdminChecks requests at the same time.
+// The returned map contains the result of checkAdminPermissions for each project.
+func checkAdminPermissionsForProjects(clusterId, username string, projects []string) map[string]error {
+	projects = common.RemoveDuplicates(projects)
+	results := make(map[string]error, len(projects))
+	// an unknown cluster fails all projects without a request
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		for _, project := range projects {
+			results[project] = err
+		}
+		return results
+	}
+
+	var mu sync.Mutex
+	common.Parallel(len(projects), maxParallelAdminChecks, nil, func(i int) {
+		err := client.checkAdminPermissions(username, projects[i])
+		mu.Lock()
+		results[projects[i]] = err
+		mu.Unlock()
+	})
+	return results
+}
+
+func (o *oseClient) getOperatorGroup() (*gabs.Container, error) {
+	resp, err := o.request(context.Background(), "GET", "apis/user.openshift.io/v1/groups/"+operatorGroup, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Println("error parsing body of response:", err)
-		return nil, errors.New(genericAPIError)
+		return nil, err
 	}
 
 	return json, nil
 }
 
-func getAdminRoleBinding(clusterId, project string) (*gabs.Container, error) {
-	resp, err := getOseHTTPClient("GET", clusterId, "apis/rbac.authorization.k8s.io/v1/namespaces/"+project+"/rolebindings", nil)
+func getRoleBindings(clusterId, project string) (*gabs.Container, error) {
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return client.getRoleBindings(project)
+}
+
+func (o *oseClient) getRoleBindings(project string) (*gabs.Container, error) {
+	resp, err := o.request(context.Background(), "GET", "apis/rbac.authorization.k8s.io/v1/namespaces/"+project+"/rolebindings", nil)
 	if err != nil {
 		return nil, err
 	}
@@ -137,34 +226,68 @@ func getAdminRoleBinding(clusterId, project string) (*gabs.Container, error) {
 
 	if resp.StatusCode == 404 {
 		log.Println("Project was not found", project)
-		return nil, errors.New("Das Projekt existiert nicht")
+		return nil, common.NewApiError(common.ErrorCodeProjectNotFound, "Das Projekt existiert nicht")
 	}
 	if resp.StatusCode == 403 {
 		log.Println("Cannot list RoleBindings: Forbidden")
 		return nil, errors.New(genericAPIError)
 	}
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		log.Println("Error listing RoleBindings:", resp.StatusCode, string(errMsg))
+		return nil, newUpstreamError("GET", resp.StatusCode, errMsg)
+	}
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Println("error parsing body of response:", err)
-		return nil, errors.New(genericAPIError)
+		return nil, err
 	}
+	return json, nil
+}
+
+func getAdminRoleBinding(clusterId, project string) (*gabs.Container, error) {
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return client.getAdminRoleBinding(project)
+}
+
+func (o *oseClient) getAdminRoleBinding(project string) (*gabs.Container, error) {
+	json, err := o.getRoleBindings(project)
+	if err != nil {
+		return nil, err
+	}
+	return parseAdminRoleBinding(json), nil
+}
+
+// parseAdminRoleBinding returns the first admin rolebinding with the subjects of all admin
+// rolebindings in userNames and groupNames. If the project has no admin rolebinding
+// (e.g. imported namespaces), a new rolebinding without subjects is returned.
+func parseAdminRoleBinding(json *gabs.Container) *gabs.Container {
 	var adminRoleBinding *gabs.Container
 	var userNames []string
 	var groupNames []string
 	for _, role := range json.S("items").Children() {
-		if role.Path("roleRef.name").Data().(string) == "admin" {
+		if role.Path("roleRef.name").Data() == "admin" {
 			if adminRoleBinding == nil {
 				adminRoleBinding = role
 			}
-			for _, name := range role.Path("subjects").Children() {
-				userNames = append(userNames, strings.ToLower(name.Path("name").Data().(string)))
-			}
-			for _, name := range role.Path("groupNames").Children() {
-				groupNames = append(groupNames, strings.ToLower(name.Data().(string)))
+			for _, subject := range role.Path("subjects").Children() {
+				subjectName, _ := subject.Path("name").Data().(string)
+				name := strings.ToLower(subjectName)
+				if subject.Path("kind").Data() == "Group" {
+					groupNames = append(groupNames, name)
+				} else {
+					userNames = append(userNames, name)
+				}
 			}
 		}
 	}
 
+	if adminRoleBinding == nil {
+		adminRoleBinding = newRoleBindingRequest("admin")
+	}
+
 	userNames = common.RemoveDuplicates(userNames)
 	adminRoleBinding.Array("userNames")
 	for _, name := range userNames {
@@ -176,47 +299,213 @@ func getAdminRoleBinding(clusterId, project string) (*gabs.Container, error) {
 		adminRoleBinding.ArrayAppend(name, "groupNames")
 	}
 
-	return adminRoleBinding, nil
+	return adminRoleBinding
+}
+
+// getRoleBinding returns the rolebinding with the given name or nil if it doesn't exist
+func getRoleBinding(clusterId, project, name string) (*gabs.Container, error) {
+	resp, err := getOseHTTPClient("GET", clusterId, "apis/rbac.authorization.k8s.io/v1/namespaces/"+project+"/rolebindings/"+name, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("Error getting rolebinding %v in project %v: StatusCode: %v", name, project, resp.StatusCode)
+		return nil, errors.New(genericAPIError)
+	}
+	json, err := parseJSONResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+	return json, nil
+}
+
+const defaultOseRequestTimeout = 60 * time.Second
+
+// getOseRequestTimeout returns ose_request_timeout (default 60s). It includes reading the response body
+func getOseRequestTimeout() time.Duration {
+	timeout := config.Config().GetDuration("ose_request_timeout")
+	if timeout <= 0 {
+		return defaultOseRequestTimeout
+	}
+	return timeout
 }
 
 func getOseHTTPClient(method string, clusterId string, endURL string, body io.Reader) (*http.Response, error) {
+	contentType := ""
+	if method == "PATCH" {
+		contentType = "application/json-patch+json"
+	}
+	return doOseRequest(method, contentType, clusterId, endURL, body)
+}
+
+// doOseRequest calls the OpenShift API. The Content-Type header is only set if contentType is not empty
+func doOseRequest(method, contentType, clusterId, endURL string, body io.Reader) (*http.Response, error) {
+	client, err := newOseClient(clusterId)
+	if err != nil {
+		return nil, err
+	}
+	return client.do(context.Background(), method, contentType, endURL, body)
+}
+
+// oseClient calls the OpenShift API of one cluster. The configuration of the cluster is resolved
+// once, so the workers of a batch request share the client instead of reading the config concurrently.
+type oseClient struct {
+	clusterId  string
+	cluster    OpenshiftCluster
+	token      string
+	client     *http.Client
+	breakerCfg breakerConfig
+}
+
+func newOseClient(clusterId string) (*oseClient, error) {
 	cluster, err := getOpenshiftCluster(clusterId)
 	if err != nil {
 		return nil, err
 	}
 
-	token := cluster.Token
+	token, err := getClusterToken(cluster)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		return nil, errors.New(common.ConfigNotSetError)
+	}
 	if token == "" {
 		log.Printf("WARNING: Cluster token not found. Please see README for more details. ClusterId: %v", clusterId)
 		return nil, errors.New(common.ConfigNotSetError)
 	}
-	base := cluster.URL
-	if base == "" {
+	if cluster.URL == "" {
 		log.Printf("WARNING: Cluster URL not found. Please see README for more details. ClusterId: %v", clusterId)
 		return nil, errors.New(common.ConfigNotSetError)
 	}
 
+	tlsConfig, err := getClusterTLSConfig(cluster)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		return nil, errors.New(common.ConfigNotSetError)
+	}
+	proxy, err := common.GetProxyFunc(cluster.Proxy)
+	if err != nil {
+		log.Printf("WARNING: %v", err)
+		return nil, errors.New(common.ConfigNotSetError)
+	}
 	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		TLSClientConfig: tlsConfig,
+		Proxy:           proxy,
+	}
+	return &oseClient{
+		clusterId: clusterId,
+		cluster:   cluster,
+		token:     token,
+		// A stalled cluster fails like an unreachable one and counts as failure of the circuit breaker
+		client:     &http.Client{Transport: tr, Timeout: getOseRequestTimeout()},
+		breakerCfg: getBreakerConfig(),
+	}, nil
+}
+
+// request calls the OpenShift API like getOseHTTPClient. The request is canceled with ctx
+func (o *oseClient) request(ctx context.Context, method, endURL string, body io.Reader) (*http.Response, error) {
+	contentType := ""
+	if method == "PATCH" {
+		contentType = "application/json-patch+json"
 	}
-	client := &http.Client{Transport: tr}
+	return o.do(ctx, method, contentType, endURL, body)
+}
 
-	req, _ := http.NewRequest(method, base+"/"+endURL, body)
+// do calls the OpenShift API like doOseRequest. The request is canceled with ctx
+func (o *oseClient) do(ctx context.Context, method, contentType, endURL string, body io.Reader) (*http.Response, error) {
+	clusterId := o.clusterId
 
-	log.Debugf("Calling %v", req.URL.String())
+	// The body is buffered, because it is sent again if OpenShift rate limits the request
+	var bodyBytes []byte
+	if body != nil {
+		var err error
+		if bodyBytes, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
 
-	req.Header.Add("Authorization", "Bearer "+token)
+	var resp *http.Response
+	for attempt := 0; attempt < 2; attempt++ {
+		var reqBody io.Reader
+		if body != nil {
+			reqBody = bytes.NewReader(bodyBytes)
+		}
+		req, _ := http.NewRequestWithContext(ctx, method, o.cluster.URL+"/"+endURL, reqBody)
 
-	if method == "PATCH" {
-		req.Header.Set("Content-Type", "application/json-patch+json")
+		log.Debugf("Calling %v", req.URL.String())
+
+		for name, value := range o.cluster.Headers {
+			req.Header.Set(name, value)
+		}
+		req.Header.Set("Authorization", "Bearer "+o.token)
+
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+
+		// checked before waiting for a slot, so requests to an unavailable cluster fail fast
+		breaker := getClusterBreaker(clusterId)
+		if err := breaker.allow(clusterId, o.breakerCfg, time.Now()); err != nil {
+			return nil, err
+		}
+		release, err := acquireClusterSlot(clusterId, oseSlotTimeout)
+		if err != nil {
+			breaker.cancel()
+			return nil, err
+		}
+		resp, err = o.client.Do(req)
+		if err != nil {
+			release()
+			breaker.record(clusterId, o.breakerCfg, false, time.Now())
+			log.Println("Error from server: ", err.Error())
+			return nil, errors.New(genericAPIError)
+		}
+		// The slot is held until the body is read and closed, so large responses count as in flight
+		resp.Body = &releasingBody{ReadCloser: resp.Body, release: release}
+		breaker.record(clusterId, o.breakerCfg, resp.StatusCode < http.StatusInternalServerError, time.Now())
+		if resp.StatusCode != http.StatusTooManyRequests {
+			return resp, nil
+		}
+		resp.Body.Close()
+		if attempt == 0 {
+			wait := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+			log.Printf("OpenShift rate limited %v %v, retrying in %v", method, req.URL.Path, wait)
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+				return nil, errors.New(genericAPIError)
+			}
+		}
 	}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Println("Error from server: ", err.Error())
-		return nil, errors.New(genericAPIError)
+	log.Printf("OpenShift rate limited %v %v, giving up", method, endURL)
+	return nil, &common.ApiError{
+		Code:       common.ErrorCodeRateLimited,
+		Message:    "Die OpenShift API ist überlastet. Bitte versuche es später nochmals",
+		RetryAfter: resp.Header.Get("Retry-After"),
 	}
-	return resp, nil
+}
+
+// parseRetryAfter parses the Retry-After header (seconds or HTTP date).
+// The result is bounded by maxRetryAfter. Without a valid header, one second is returned.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	wait := time.Second
+	if seconds, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = date.Sub(now)
+	}
+	if wait < 0 {
+		return 0
+	}
+	if wait > maxRetryAfter {
+		return maxRetryAfter
+	}
+	return wait
 }
 
 func getWZUBackendClient(method string, endUrl string, body io.Reader) (*http.Response, error) {
@@ -362,3 +651,108 @@ func generateID() string {
 	}
 	return result
 }
+
+// Max length of the upstream body in verbose errors
+const maxVerboseErrorBody = 300
+
+var (
+	bearerPattern      = regexp.MustCompile(`(?i)(bearer\s+)\S+`)
+	secretFieldPattern = regexp.MustCompile(`(?i)("(?:token|password|secret)"\s*:\s*)"[^"]*"`)
+)
+
+// Returned if the OpenShift API doesn't answer with JSON, e.g. with the HTML login page
+// because the token of the service account is invalid or expired
+var ErrUpstreamAuth = common.NewApiError(common.ErrorCodeBackendError,
+	"Die Anmeldung an der OpenShift API ist fehlgeschlagen. Bitte erstelle ein Ticket")
+
+// Returned if the response of the OpenShift API is not valid JSON
+var ErrUpstreamMalformed = common.NewApiError(common.ErrorCodeBackendError, genericAPIError)
+
+// Number of bytes of an unexpected response body that are logged
+const maxLoggedBody = 512
+
+// parseJSONResponse reads the body of the OpenShift response.
+// Non-JSON responses are logged and returned as ErrUpstreamAuth (HTML, 401) or ErrUpstreamMalformed.
+func parseJSONResponse(resp *http.Response) (*gabs.Container, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		log.Println("error reading body of response:", err)
+		return nil, ErrUpstreamMalformed
+	}
+	json, err := gabs.ParseJSON(body)
+	if err == nil {
+		return json, nil
+	}
+
+	logged := body
+	if len(logged) > maxLoggedBody {
+		logged = logged[:maxLoggedBody]
+	}
+	url := ""
+	if resp.Request != nil {
+		url = resp.Request.URL.String()
+	}
+	trimmed := strings.TrimSpace(string(body))
+	if resp.StatusCode == http.StatusUnauthorized ||
+		strings.Contains(resp.Header.Get("Content-Type"), "text/html") ||
+		strings.HasPrefix(trimmed, "<") {
+		log.WithFields(log.Fields{
+			"url":    url,
+			"status": resp.StatusCode,
+			"body":   string(logged),
+		}).Error("OpenShift API returned no JSON. The token of the service account is probably invalid or expired")
+		return nil, ErrUpstreamAuth
+	}
+	log.WithFields(log.Fields{
+		"url":    url,
+		"status": resp.StatusCode,
+		"body":   string(logged),
+		"err":    err.Error(),
+	}).Error("OpenShift API returned invalid JSON")
+	return nil, ErrUpstreamMalformed
+}
+
+const defaultConflictRetries = 3
+
+// retryOnConflict runs a GET-modify-PUT until OpenShift doesn't reject it with a 409 conflict
+// (the object was changed since the GET), but at most ose_conflict_retries times.
+// readModifyWrite must fetch the latest object on every call.
+func retryOnConflict(readModifyWrite func() error) error {
+	attempts := config.Config().GetInt("ose_conflict_retries")
+	if attempts <= 0 {
+		attempts = defaultConflictRetries
+	}
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		err = readModifyWrite()
+		if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeConflict {
+			return err
+		}
+		log.Printf("Conflict while updating an object (attempt %v of %v)", attempt, attempts)
+	}
+	return err
+}
+
+// newUpstreamError returns the error for a failed request to the OpenShift API.
+// If verbose_errors is set, the upstream status and the truncated body are included
+// in the message. Tokens and passwords are removed from the body.
+// A conflict (409) of OpenShift is returned as conflict to the client. It means that the
+// object already exists for a POST, and that it was changed concurrently for other methods.
+func newUpstreamError(method string, statusCode int, body []byte) error {
+	if statusCode == http.StatusConflict {
+		if method == http.MethodPost {
+			return common.NewApiError(common.ErrorCodeConflict, "The object already exists")
+		}
+		return common.NewApiError(common.ErrorCodeConflict, "The object was changed concurrently, please retry")
+	}
+	if !config.Config().GetBool("verbose_errors") {
+		return common.NewApiError(common.ErrorCodeBackendError, genericAPIError)
+	}
+	detail := bearerPattern.ReplaceAllString(string(body), "${1}[REDACTED]")
+	detail = secretFieldPattern.ReplaceAllString(detail, `${1}"[REDACTED]"`)
+	if len(detail) > maxVerboseErrorBody {
+		detail = detail[:maxVerboseErrorBody] + "..."
+	}
+	return common.NewApiError(common.ErrorCodeBackendError,
+		fmt.Sprintf("%v (OpenShift status %v: %v)", genericAPIError, statusCode, strings.TrimSpace(detail)))
+}
@@ -0,0 +1,229 @@
+package openshift
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func setTestCluster(url string) {
+	config.Init("bla")
+	config.Config().Set("openshift", []map[string]interface{}{
+		{"id": "test", "url": url, "token": "token"},
+	})
+	// failures of previous tests must not open the circuit breaker
+	breakersMu.Lock()
+	allBreakers = map[string]*clusterBreaker{}
+	breakersMu.Unlock()
+	clusterSlotsMu.Lock()
+	allClusterSlots = map[string]*clusterSlots{}
+	clusterSlotsMu.Unlock()
+	projectNamesCache.Clear()
+}
+
+func TestGetOseHTTPClientRetriesRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		if string(body) != "body" {
+			t.Errorf("ERROR: body should be sent on every attempt, got: '%v'", string(body))
+		}
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	resp, err := getOseHTTPClient("POST", "test", "api/v1/namespaces", strings.NewReader("body"))
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Errorf("ERROR: expected 200 after 2 calls, got %v after %v calls", resp.StatusCode, calls)
+	}
+}
+
+func TestGetOseHTTPClientRateLimitExhausted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	_, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	apiErr, ok := err.(*common.ApiError)
+	if !ok || apiErr.Code != common.ErrorCodeRateLimited || apiErr.RetryAfter != "0" {
+		t.Errorf("ERROR: expected rate limited error, got: %v", err)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2020, 8, 1, 10, 0, 0, 0, time.UTC)
+	testsets := []struct {
+		header string
+		wait   time.Duration
+	}{
+		{"2", 2 * time.Second},
+		{"3600", maxRetryAfter},
+		{"", time.Second},
+		{"-1", 0},
+		{now.Add(3 * time.Second).Format(http.TimeFormat), 3 * time.Second},
+	}
+	for _, set := range testsets {
+		if wait := parseRetryAfter(set.header, now); wait != set.wait {
+			t.Errorf("ERROR: Retry-After '%v' should wait %v, but waits %v", set.header, set.wait, wait)
+		}
+	}
+}
+
+func TestNewUpstreamError(t *testing.T) {
+	config.Init("bla")
+	body := []byte(`{"message": "forbidden", "token": "s3cr3t", "header": "Bearer abc.def"}`)
+
+	if err := newUpstreamError("GET", http.StatusForbidden, body); err.Error() != genericAPIError {
+		t.Errorf("ERROR: error should be generic, but is: %v", err)
+	}
+
+	config.Config().Set("verbose_errors", true)
+	err := newUpstreamError("GET", http.StatusForbidden, body)
+	if !strings.Contains(err.Error(), "status 403") || !strings.Contains(err.Error(), "forbidden") {
+		t.Errorf("ERROR: error should contain the status and body, but is: %v", err)
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || strings.Contains(err.Error(), "abc.def") {
+		t.Errorf("ERROR: error must not contain secrets: %v", err)
+	}
+
+	err = newUpstreamError("GET", http.StatusInternalServerError, []byte(strings.Repeat("x", 1000)))
+	if len(err.Error()) > len(genericAPIError)+maxVerboseErrorBody+50 {
+		t.Errorf("ERROR: body should be truncated, but error has %v characters", len(err.Error()))
+	}
+}
+
+func TestParseAdminRoleBindingWithoutAdminBinding(t *testing.T) {
+	json, err := gabs.ParseJSON([]byte(`{
+		"items": [
+			{"metadata": {"name": "view"}, "roleRef": {"name": "view"}, "subjects": [{"kind": "User", "name": "u123456"}]}
+		]
+	}`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	adminRoleBinding := parseAdminRoleBinding(json)
+	if users := adminRoleBinding.Path("userNames").Children(); len(users) != 0 {
+		t.Errorf("ERROR: expected no admins, got %v", users)
+	}
+	if name := adminRoleBinding.Path("metadata.name").Data(); name != "admin" {
+		t.Errorf("ERROR: expected a new admin rolebinding, got %v", name)
+	}
+}
+
+func TestGetRoleBindingsServerError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	_, err := getRoleBindings("test", "project")
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeBackendError {
+		t.Errorf("ERROR: expected backend error, got: %v", err)
+	}
+}
+
+func TestParseJSONResponse(t *testing.T) {
+	var testsets = []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		err         error
+	}{
+		{"json", http.StatusOK, "application/json", `{"kind": "Namespace"}`, nil},
+		{"login page", http.StatusOK, "text/html", "<!DOCTYPE html><html>Login</html>", ErrUpstreamAuth},
+		{"unauthorized", http.StatusUnauthorized, "text/plain", "Unauthorized", ErrUpstreamAuth},
+		{"malformed", http.StatusOK, "application/json", `{"kind": `, ErrUpstreamMalformed},
+	}
+
+	for _, set := range testsets {
+		t.Run(set.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: set.status,
+				Header:     http.Header{"Content-Type": []string{set.contentType}},
+				Body:       ioutil.NopCloser(strings.NewReader(set.body)),
+			}
+			json, err := parseJSONResponse(resp)
+			if err != set.err {
+				t.Errorf("ERROR: error should be '%v', but is: '%v'", set.err, err)
+			}
+			if set.err == nil && json.S("kind").Data() != "Namespace" {
+				t.Errorf("ERROR: unexpected json: %v", json)
+			}
+		})
+	}
+}
+
+func TestGetOseHTTPClientProxyAndHeaders(t *testing.T) {
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Host != "cluster.example.com" {
+			t.Errorf("ERROR: request should be sent to the cluster via the proxy, got host: %v", r.Host)
+		}
+		if r.Header.Get("X-Proxy-Authorization") != "secret" {
+			t.Errorf("ERROR: header of the cluster config should be set, got: %v", r.Header)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("ERROR: cluster token should not be overwritten, got: %v", r.Header.Get("Authorization"))
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+
+	config.Init("bla")
+	config.Config().Set("openshift", []map[string]interface{}{
+		{
+			"id":      "test",
+			"url":     "http://cluster.example.com",
+			"token":   "token",
+			"proxy":   proxy.URL,
+			"headers": map[string]string{"x-proxy-authorization": "secret", "authorization": "other"},
+		},
+	})
+
+	resp, err := getOseHTTPClient("GET", "test", "api/v1/namespaces", nil)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestValidateClustersProxy(t *testing.T) {
+	config.Init("bla")
+	for proxy, valid := range map[string]bool{
+		"":                               true,
+		"http://proxy.example.com":       true,
+		"https://proxy.example.com:3128": true,
+		"proxy.example.com:3128":         false,
+		"ftp://proxy.example.com":        false,
+		"http://":                        false,
+	} {
+		config.Config().Set("openshift", []map[string]interface{}{
+			{"id": "test", "url": "https://cluster.example.com", "token": "token", "proxy": proxy},
+		})
+		if err := ValidateClusters(); (err == nil) != valid {
+			t.Errorf("ERROR: proxy '%v' should be valid: %v, got error: %v", proxy, valid, err)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package openshift
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// templateProjectVariable is replaced with the project name in all template objects
+const templateProjectVariable = "${PROJECT}"
+
+// templateResources maps the kinds which can be used in project templates to their API path
+var templateResources = map[string]string{
+	"ConfigMap":      "api/v1/namespaces/%v/configmaps",
+	"Secret":         "api/v1/namespaces/%v/secrets",
+	"LimitRange":     "api/v1/namespaces/%v/limitranges",
+	"ResourceQuota":  "api/v1/namespaces/%v/resourcequotas",
+	"ServiceAccount": "api/v1/namespaces/%v/serviceaccounts",
+	"RoleBinding":    "apis/rbac.authorization.k8s.io/v1/namespaces/%v/rolebindings",
+	"NetworkPolicy":  "apis/networking.k8s.io/v1/namespaces/%v/networkpolicies",
+}
+
+// getProjectTemplate returns the objects of the template from project_templates in the config.
+// Template names are case insensitive, because viper lowercases the keys.
+func getProjectTemplate(name string) ([]map[string]interface{}, error) {
+	templates, _ := config.Config().Get("project_templates").(map[string]interface{})
+	template, ok := templates[strings.ToLower(name)]
+	if !ok {
+		return nil, common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The project template %v does not exist", name))
+	}
+	list, _ := template.([]interface{})
+
+	var objects []map[string]interface{}
+	for _, o := range list {
+		object, ok := stringKeys(o).(map[string]interface{})
+		if !ok {
+			log.Printf("WARNING: project template %v contains an invalid object", name)
+			return nil, errors.New(common.ConfigNotSetError)
+		}
+		kind, _ := object["kind"].(string)
+		if _, ok := templateResources[kind]; !ok {
+			log.Printf("WARNING: project template %v contains the unsupported kind '%v'", name, kind)
+			return nil, errors.New(common.ConfigNotSetError)
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+func validateProjectTemplate(name string) error {
+	if name == "" {
+		return nil
+	}
+	_, err := getProjectTemplate(name)
+	return err
+}
+
+// applyProjectTemplate creates the objects of the template in the project.
+// It returns the objects (kind/name) which could not be created.
+func applyProjectTemplate(clusterId, project, name string) ([]string, error) {
+	objects, err := getProjectTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var failed []string
+	for _, object := range objects {
+		kind := object["kind"].(string)
+		objectName := kind
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			objectName = fmt.Sprintf("%v/%v", kind, metadata["name"])
+		}
+
+		body, err := json.Marshal(object)
+		if err != nil {
+			log.Printf("Error encoding template object %v: %v", objectName, err)
+			failed = append(failed, objectName)
+			continue
+		}
+		body = bytes.Replace(body, []byte(templateProjectVariable), []byte(project), -1)
+
+		url := fmt.Sprintf(templateResources[kind], project)
+		resp, err := getOseHTTPClient("POST", clusterId, url, bytes.NewReader(body))
+		if err != nil {
+			failed = append(failed, objectName)
+			continue
+		}
+		if resp.StatusCode != http.StatusCreated {
+			errMsg, _ := ioutil.ReadAll(resp.Body)
+			log.Printf("Error creating template object %v in project %v: %v %v", objectName, project, resp.StatusCode, string(errMsg))
+			failed = append(failed, objectName)
+		}
+		resp.Body.Close()
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  clusterId,
+		"project":  project,
+		"template": name,
+		"failed":   failed,
+	}).Info("Project template was applied")
+	return failed, nil
+}
+
+// stringKeys converts the map[interface{}]interface{} from the yaml config
+// into map[string]interface{}, so the objects can be encoded as JSON
+func stringKeys(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[fmt.Sprintf("%v", key)] = stringKeys(val)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			m[key] = stringKeys(val)
+		}
+		return m
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, val := range v {
+			list[i] = stringKeys(val)
+		}
+		return list
+	}
+	return value
+}
@@ -0,0 +1,59 @@
+package openshift
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+const testTemplateConfig = `
+project_templates:
+  Default:
+    - apiVersion: v1
+      kind: ConfigMap
+      metadata:
+        name: project-info
+      data:
+        project: ${PROJECT}
+    - apiVersion: v1
+      kind: LimitRange
+      metadata:
+        name: limits
+`
+
+func TestApplyProjectTemplate(t *testing.T) {
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if strings.HasSuffix(r.URL.Path, "/limitranges") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+	if err := config.Config().ReadConfig(strings.NewReader(testTemplateConfig)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := validateProjectTemplate("missing"); err == nil {
+		t.Error("ERROR: missing template should be invalid")
+	}
+
+	failed, err := applyProjectTemplate("test", "myproject", "default")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(failed) != 1 || failed[0] != "LimitRange/limits" {
+		t.Errorf("ERROR: expected LimitRange/limits to fail, got: %v", failed)
+	}
+	if len(bodies) != 2 || !strings.Contains(bodies[0], `"project":"myproject"`) || !strings.Contains(bodies[0], `"apiVersion":"v1"`) {
+		t.Errorf("ERROR: unexpected requests: %v", bodies)
+	}
+}
@@ -0,0 +1,86 @@
+package openshift
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+// TerminatingProject is a project whose deletion has started but is not finished
+type TerminatingProject struct {
+	Cluster           string `json:"cluster"`
+	Project           string `json:"project"`
+	DeletionTimestamp string `json:"deletionTimestamp"`
+	// Seconds since the deletion has started
+	TerminatingSeconds int64 `json:"terminatingSeconds"`
+	// Only set with finalizers=true
+	Finalizers []string `json:"finalizers,omitempty"`
+	// Messages of the conditions which block the deletion. Only set with finalizers=true
+	Conditions []string `json:"conditions,omitempty"`
+}
+
+// getTerminatingProjectsHandler lists the projects of all clusters in the phase Terminating.
+// With finalizers=true, the finalizers and conditions which block the deletion are returned.
+func getTerminatingProjectsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	withFinalizers, _ := strconv.ParseBool(c.Query("finalizers"))
+
+	if err := validatePortalAdmin(username); err != nil {
+		common.RespondError(c, http.StatusForbidden, err)
+		return
+	}
+
+	log.Printf("%v lists the terminating projects", username)
+	terminatingProjects := []TerminatingProject{}
+	now := time.Now()
+	for _, cluster := range getOpenshiftClusters("") {
+		projects, err := getProjects(cluster.ID, username)
+		if err != nil {
+			common.RespondError(c, http.StatusBadGateway, err)
+			return
+		}
+		terminatingProjects = append(terminatingProjects, filterTerminatingProjects(cluster.ID, projects, withFinalizers, now)...)
+	}
+	c.JSON(http.StatusOK, terminatingProjects)
+}
+
+func filterTerminatingProjects(clusterId string, projects *gabs.Container, withFinalizers bool, now time.Time) []TerminatingProject {
+	terminatingProjects := []TerminatingProject{}
+	for _, project := range projects.Children() {
+		if project.Path("status.phase").Data() != "Terminating" {
+			continue
+		}
+		name, _ := project.Path("metadata.name").Data().(string)
+		deletionTimestamp, _ := project.Path("metadata.deletionTimestamp").Data().(string)
+		terminatingProject := TerminatingProject{
+			Cluster:           clusterId,
+			Project:           name,
+			DeletionTimestamp: deletionTimestamp,
+		}
+		if deletedAt, err := time.Parse(time.RFC3339, deletionTimestamp); err == nil {
+			terminatingProject.TerminatingSeconds = int64(now.Sub(deletedAt).Seconds())
+		}
+		if withFinalizers {
+			for _, finalizer := range project.Path("spec.finalizers").Children() {
+				if s, ok := finalizer.Data().(string); ok {
+					terminatingProject.Finalizers = append(terminatingProject.Finalizers, s)
+				}
+			}
+			for _, condition := range project.Path("status.conditions").Children() {
+				if condition.S("status").Data() != "True" {
+					continue
+				}
+				if message, ok := condition.S("message").Data().(string); ok {
+					terminatingProject.Conditions = append(terminatingProject.Conditions, message)
+				}
+			}
+		}
+		terminatingProjects = append(terminatingProjects, terminatingProject)
+	}
+	return terminatingProjects
+}
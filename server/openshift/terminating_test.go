@@ -0,0 +1,39 @@
+package openshift
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+)
+
+func TestFilterTerminatingProjects(t *testing.T) {
+	projects, err := gabs.ParseJSON([]byte(`[
+		{"metadata": {"name": "active"}, "status": {"phase": "Active"}},
+		{"metadata": {"name": "stuck", "deletionTimestamp": "2020-01-01T10:00:00Z"},
+		 "spec": {"finalizers": ["kubernetes"]},
+		 "status": {"phase": "Terminating", "conditions": [
+			{"type": "NamespaceDeletionDiscoveryFailure", "status": "False", "message": "All resources discovered"},
+			{"type": "NamespaceFinalizersRemaining", "status": "True", "message": "Some content has finalizers remaining"}]}}
+	]`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+	now, _ := time.Parse(time.RFC3339, "2020-01-01T11:00:00Z")
+
+	result := filterTerminatingProjects("test", projects, false, now)
+	if len(result) != 1 {
+		t.Fatalf("ERROR: expected 1 project, got %v", len(result))
+	}
+	if result[0].Project != "stuck" || result[0].TerminatingSeconds != 3600 || result[0].Finalizers != nil {
+		t.Errorf("ERROR: unexpected project: %+v", result[0])
+	}
+
+	result = filterTerminatingProjects("test", projects, true, now)
+	if len(result[0].Finalizers) != 1 || result[0].Finalizers[0] != "kubernetes" {
+		t.Errorf("ERROR: finalizers should be returned, got: %v", result[0].Finalizers)
+	}
+	if len(result[0].Conditions) != 1 || result[0].Conditions[0] != "Some content has finalizers remaining" {
+		t.Errorf("ERROR: only the blocking conditions should be returned, got: %v", result[0].Conditions)
+	}
+}
@@ -0,0 +1,255 @@
+package openshift
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"text/template"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/ldap"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	testProjectDaysAnnotation = "openshift.io/testproject-daystodeletion"
+	// Deletion date (e.g. 2020-03-31) for which the requester has been warned, so the warning is sent only once
+	testProjectWarningAnnotation  = "openshift.io/testproject-deletion-warning"
+	defaultTestProjectWarningDays = 7
+	deletionDateFormat            = "2006-01-02"
+)
+
+// testProjectWarning is a test project whose requester is warned about the deletion
+type testProjectWarning struct {
+	Project      string
+	Requester    string
+	OwnerGroup   string
+	DeletionDate time.Time
+}
+
+// extendTestProjectHandler postpones the deletion of a test project to testProjectDeletionDays from today
+func extendTestProjectHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data common.OpenshiftBase
+	if c.BindJSON(&data) != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError, Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	if err := validateAdminAccess(data.ClusterId, username, data.Project); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	deletion, err := extendTestProject(data.ClusterId, data.Project, time.Now())
+	if err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"cluster":  data.ClusterId,
+		"project":  data.Project,
+		"username": username,
+		"deletion": deletion.Format(deletionDateFormat),
+	}).Info("AUDIT: Test project was extended")
+
+	c.JSON(http.StatusOK, common.ApiResponse{
+		Message: common.T(c, common.MsgTestProjectExtended, data.Project, deletion.Format(deletionDateFormat)),
+	})
+}
+
+// extendTestProject sets the days to deletion, so that the project is deleted testProjectDeletionDays after now.
+// The warning annotation is removed, so the requester is warned again before the new deletion date.
+func extendTestProject(clusterId, project string, now time.Time) (time.Time, error) {
+	pi, err := getProjectInformation(clusterId, project)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !pi.IsTestProject || pi.DeletionDate == "" {
+		return time.Time{}, common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The project %v is not a test project", project))
+	}
+
+	lifetime, _ := strconv.Atoi(testProjectDeletionDays)
+	var deletion time.Time
+	err = patchNamespaceAnnotations(clusterId, project, func(json *gabs.Container) {
+		creationTimestamp, _ := json.Path("metadata.creationTimestamp").Data().(string)
+		created, err := time.Parse(time.RFC3339, creationTimestamp)
+		if err != nil {
+			created = now
+		}
+		// The days since the creation plus the lifetime of a new test project
+		days := int(math.Ceil(now.Sub(created).Hours()/24)) + lifetime
+		deletion = created.AddDate(0, 0, days)
+		annotations := json.Path("metadata.annotations")
+		annotations.Set(strconv.Itoa(days), testProjectDaysAnnotation)
+		annotations.Delete(testProjectWarningAnnotation)
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	return deletion, nil
+}
+
+// getTestProjectWarningDays returns test_project_warning_days (default 7).
+// The requesters are warned this many days before the deletion. 0 or a negative value disables the warnings.
+func getTestProjectWarningDays() int {
+	if !config.Config().IsSet("test_project_warning_days") {
+		return defaultTestProjectWarningDays
+	}
+	return config.Config().GetInt("test_project_warning_days")
+}
+
+// warnExpiringTestProjects sends the deletion warnings of the test projects of the cluster and returns the number of sent warnings.
+// The annotation is only set after the mail has been sent, so failed mails are sent again in the next run.
+func warnExpiringTestProjects(clusterId string, projects *gabs.Container, warningDays int, now time.Time) int {
+	sent := 0
+	for _, warning := range selectTestProjectWarnings(projects, warningDays, now) {
+		if err := sendTestProjectWarningMail(clusterId, warning); err != nil {
+			log.Printf("Can't send the deletion warning of test project %v on cluster %v: %v", warning.Project, clusterId, err)
+			continue
+		}
+		sent++
+		deletionDate := warning.DeletionDate.Format(deletionDateFormat)
+		err := updateNamespaceAnnotations(clusterId, warning.Project, func(annotations *gabs.Container) {
+			annotations.Set(deletionDate, testProjectWarningAnnotation)
+		})
+		if err != nil {
+			log.Printf("Error setting the deletion warning annotation of test project %v on cluster %v: %v", warning.Project, clusterId, err)
+		}
+	}
+	return sent
+}
+
+// selectTestProjectWarnings returns the test projects which are deleted within warningDays
+// and whose requester hasn't been warned about this deletion date yet
+func selectTestProjectWarnings(projects *gabs.Container, warningDays int, now time.Time) []testProjectWarning {
+	keys := getAnnotationKeys()
+	var warnings []testProjectWarning
+	for _, project := range projects.Children() {
+		annotations := project.Path("metadata.annotations")
+		if _, ok := annotations.S(testProjectDaysAnnotation).Data().(string); !ok {
+			continue
+		}
+		if project.Path("status.phase").Data() == "Terminating" {
+			continue
+		}
+		deletion, err := testProjectDeletionDate(project)
+		if err != nil {
+			log.Printf("%v", err)
+			continue
+		}
+		if now.After(deletion) || now.AddDate(0, 0, warningDays).Before(deletion) {
+			continue
+		}
+		if annotations.S(testProjectWarningAnnotation).Data() == deletion.Format(deletionDateFormat) {
+			continue
+		}
+		name, _ := project.Path("metadata.name").Data().(string)
+		objects := []*gabs.Container{project}
+		warnings = append(warnings, testProjectWarning{
+			Project:      name,
+			Requester:    projectAnnotation(objects, keys.Requester, "openshift.io/requester"),
+			OwnerGroup:   projectAnnotation(objects, "openshift.io/owner-group"),
+			DeletionDate: deletion,
+		})
+	}
+	return warnings
+}
+
+func sendTestProjectWarningMail(clusterId string, warning testProjectWarning) error {
+	to, err := getTestProjectWarningRecipients(warning)
+	if err != nil {
+		return err
+	}
+	if len(to) == 0 {
+		return errors.New("The requester and the owner group have no e-mail address")
+	}
+
+	mailer, err := getMailer()
+	if err != nil {
+		return err
+	}
+	from := parseMailAddresses(os.Getenv("MAIL_ADMIN_SENDER"))
+	if len(from) != 1 {
+		return errors.New("MAIL_ADMIN_SENDER must be a valid e-mail address.")
+	}
+
+	m := gomail.NewMessage()
+	m.SetHeader("From", from[0])
+	m.SetHeader("To", to...)
+	m.SetHeader("Subject", fmt.Sprintf("Test project '%v' on OpenShift will be deleted on %v", warning.Project, warning.DeletionDate.Format(deletionDateFormat)))
+	m.SetBody("text/plain", testProjectWarningBody(clusterId, warning))
+	return mailer.Send(m)
+}
+
+func testProjectWarningBody(clusterId string, warning testProjectWarning) string {
+	body := fmt.Sprintf("The test project %v on cluster %v will be deleted automatically on %v.\n\n",
+		warning.Project, clusterId, warning.DeletionDate.Format(deletionDateFormat))
+	if link := getTestProjectExtendLink(clusterId, warning.Project); link != "" {
+		body += fmt.Sprintf("If you still need the project, you can extend it by %v days: %v\n", testProjectDeletionDays, link)
+	} else {
+		body += fmt.Sprintf("If you still need the project, you can extend it by %v days in the self service portal.\n", testProjectDeletionDays)
+	}
+	return body
+}
+
+// getTestProjectExtendLink returns the link of test_project_extend_url, e.g.
+// https://ssp.example.com/openshift/testproject?clusterid={{.Cluster}}&project={{.Project}}
+func getTestProjectExtendLink(clusterId, project string) string {
+	linkTemplate := config.Config().GetString("test_project_extend_url")
+	if linkTemplate == "" {
+		return ""
+	}
+	t, err := template.New("extendlink").Parse(linkTemplate)
+	if err != nil {
+		log.Printf("WARNING: invalid test_project_extend_url: %v", err)
+		return ""
+	}
+	var link bytes.Buffer
+	data := struct{ Cluster, Project string }{clusterId, project}
+	if err := t.Execute(&link, data); err != nil {
+		log.Printf("WARNING: invalid test_project_extend_url: %v", err)
+		return ""
+	}
+	return link.String()
+}
+
+// getTestProjectWarningRecipients returns the e-mail addresses of the requester and the owner group
+func getTestProjectWarningRecipients(warning testProjectWarning) ([]string, error) {
+	var recipients []string
+	if warning.Requester != "" {
+		users, err := ldap.LookupUsers([]string{warning.Requester})
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 1 && users[0].Email != "" {
+			recipients = append(recipients, users[0].Email)
+		}
+	}
+	if warning.OwnerGroup != "" {
+		l, err := ldap.New()
+		if err != nil {
+			return nil, err
+		}
+		defer l.Close()
+		mail, err := l.GetGroupMail(warning.OwnerGroup)
+		if err != nil {
+			return nil, err
+		}
+		if mail != "" {
+			recipients = append(recipients, mail)
+		}
+	}
+	return recipients, nil
+}
@@ -0,0 +1,114 @@
+package openshift
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jeffail/gabs/v2"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestSelectTestProjectWarnings(t *testing.T) {
+	config.Init("bla")
+	now := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	projects, err := gabs.ParseJSON([]byte(`[
+		{"metadata": {"name": "not-a-testproject", "creationTimestamp": "2020-08-01T10:00:00Z", "annotations": {}}},
+		{"metadata": {"name": "young", "creationTimestamp": "2020-08-20T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "30"}}},
+		{"metadata": {"name": "expiring", "creationTimestamp": "2020-08-05T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "30", "openshift.io/requester": "u123456", "openshift.io/owner-group": "team"}}},
+		{"metadata": {"name": "warned", "creationTimestamp": "2020-08-05T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "30", "openshift.io/testproject-deletion-warning": "2020-09-04"}}},
+		{"metadata": {"name": "extended", "creationTimestamp": "2020-07-05T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "61", "openshift.io/testproject-deletion-warning": "2020-08-04"}}},
+		{"metadata": {"name": "overdue", "creationTimestamp": "2020-07-01T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "30"}}},
+		{"metadata": {"name": "terminating", "creationTimestamp": "2020-08-05T10:00:00Z",
+			"annotations": {"openshift.io/testproject-daystodeletion": "30"}}, "status": {"phase": "Terminating"}}
+	]`))
+	if err != nil {
+		t.Fatal("Invalid JSON!")
+	}
+
+	warnings := selectTestProjectWarnings(projects, 7, now)
+	if len(warnings) != 2 || warnings[0].Project != "expiring" || warnings[1].Project != "extended" {
+		t.Fatalf("ERROR: expected warnings for expiring and extended, got: %+v", warnings)
+	}
+	if warnings[0].Requester != "u123456" || warnings[0].OwnerGroup != "team" || warnings[0].DeletionDate.Format(deletionDateFormat) != "2020-09-04" {
+		t.Errorf("ERROR: unexpected warning: %+v", warnings[0])
+	}
+}
+
+func TestTestProjectWarningBody(t *testing.T) {
+	config.Init("bla")
+	warning := testProjectWarning{Project: "u123456-test", DeletionDate: time.Date(2020, 9, 4, 10, 0, 0, 0, time.UTC)}
+
+	if body := testProjectWarningBody("awsdev", warning); !strings.Contains(body, "deleted automatically on 2020-09-04") || !strings.Contains(body, "self service portal") {
+		t.Errorf("ERROR: unexpected body without link: %v", body)
+	}
+
+	config.Config().Set("test_project_extend_url", "https://ssp.example.com/testproject?clusterid={{.Cluster}}&project={{.Project}}")
+	if body := testProjectWarningBody("awsdev", warning); !strings.Contains(body, "https://ssp.example.com/testproject?clusterid=awsdev&project=u123456-test") {
+		t.Errorf("ERROR: body should contain the extend link: %v", body)
+	}
+}
+
+func TestGetTestProjectWarningDays(t *testing.T) {
+	config.Init("bla")
+	if days := getTestProjectWarningDays(); days != defaultTestProjectWarningDays {
+		t.Errorf("ERROR: expected the default of %v days, got: %v", defaultTestProjectWarningDays, days)
+	}
+	config.Config().Set("test_project_warning_days", 0)
+	if days := getTestProjectWarningDays(); days != 0 {
+		t.Errorf("ERROR: 0 should disable the warnings, got: %v", days)
+	}
+}
+
+func TestExtendTestProject(t *testing.T) {
+	var patch *gabs.Container
+	annotations := `"openshift.io/kontierung-element": "keine-verrechnung", "openshift.io/testproject-daystodeletion": "30"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			w.Write([]byte(`{"metadata": {"name": "project", "creationTimestamp": "2020-08-05T10:00:00Z", "annotations": {` +
+				annotations + `, "openshift.io/testproject-deletion-warning": "2020-09-04"}}}`))
+		case "PATCH":
+			var err error
+			if patch, err = gabs.ParseJSONBuffer(r.Body); err != nil {
+				t.Fatal("Invalid JSON!")
+			}
+			w.Write([]byte("{}"))
+		default:
+			t.Errorf("ERROR: unexpected %v request", r.Method)
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	defer srv.Close()
+	setTestCluster(srv.URL)
+
+	now := time.Date(2020, 9, 1, 9, 0, 0, 0, time.UTC)
+	deletion, err := extendTestProject("test", "project", now)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if deletion.Format(deletionDateFormat) != "2020-10-01" {
+		t.Errorf("ERROR: the project should be deleted 30 days from now, got: %v", deletion)
+	}
+	if patch.Path("metadata.annotations").S(testProjectDaysAnnotation).Data() != "57" {
+		t.Errorf("ERROR: days to deletion should be 57, got: %v", patch)
+	}
+	if !patch.Exists("metadata", "annotations", testProjectWarningAnnotation) || patch.S("metadata", "annotations", testProjectWarningAnnotation).Data() != nil {
+		t.Errorf("ERROR: the warning annotation should be removed, got: %v", patch)
+	}
+
+	annotations = `"openshift.io/kontierung-element": "1234"`
+	if _, err := extendTestProject("test", "project", now); err == nil {
+		t.Error("ERROR: only test projects can be extended")
+	} else if apiErr, ok := err.(*common.ApiError); !ok || !apiErr.Validation {
+		t.Errorf("ERROR: expected a validation error, got: %v", err)
+	}
+}
@@ -36,7 +36,7 @@ func newVolumeHandler(c *gin.Context) {
 	var data common.NewVolumeCommand
 	if c.BindJSON(&data) == nil {
 		if err := validateNewVolume(data.ClusterId, data.Project, data.Size, data.PvcName, data.Mode, data.Technology, username); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -95,7 +95,7 @@ func fixVolumeHandler(c *gin.Context) {
 	var data common.FixVolumeCommand
 	if c.BindJSON(&data) == nil {
 		if err := validateFixVolume(data.ClusterId, data.Project, username); err != nil {
-			c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+			common.RespondError(c, http.StatusBadRequest, err)
 			return
 		}
 
@@ -126,7 +126,7 @@ func growVolumeHandler(c *gin.Context) {
 		return
 	}
 	if err := validateGrowVolume(data.ClusterId, pv, data.NewSize, username); err != nil {
-		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		common.RespondError(c, http.StatusBadRequest, err)
 		return
 	}
 	if err := growExistingVolume(data.ClusterId, pv, data.NewSize, username); err != nil {
@@ -216,12 +216,12 @@ func validateSizeFormat(size string, technology string) error {
 		if strings.HasSuffix(size, "G") {
 			return nil
 		}
-		return errors.New(wrongSizeNFSFormatError)
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, wrongSizeNFSFormatError)
 	}
 	if strings.HasSuffix(size, "M") || strings.HasSuffix(size, "G") {
 		return nil
 	}
-	return errors.New(wrongSizeFormatError)
+	return common.NewValidationError(common.ErrorCodeInvalidRequest, wrongSizeFormatError)
 }
 
 func validateSize(size string) error {
@@ -236,24 +236,24 @@ func validateSize(size string) error {
 	if strings.HasSuffix(size, "M") {
 		sizeInt, err := strconv.Atoi(strings.Replace(size, "M", "", 1))
 		if err != nil {
-			return errors.New(wrongSizeFormatError)
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, wrongSizeFormatError)
 		}
 
 		if sizeInt < minMB {
-			return fmt.Errorf(wrongSizeLimitError, maxMB, maxGB)
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf(wrongSizeLimitError, maxMB, maxGB))
 		}
 		if sizeInt > maxMB {
-			return errors.New("Your value in Megabytes is too big. Please provide the size in Gigabytes")
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, "Your value in Megabytes is too big. Please provide the size in Gigabytes")
 		}
 	}
 	if strings.HasSuffix(size, "G") {
 		sizeInt, err := strconv.Atoi(strings.Replace(size, "G", "", 1))
 		if err != nil {
-			return errors.New(wrongSizeFormatError)
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, wrongSizeFormatError)
 		}
 
 		if sizeInt > maxGB {
-			return fmt.Errorf(wrongSizeLimitError, maxMB, maxGB)
+			return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf(wrongSizeLimitError, maxMB, maxGB))
 		}
 	}
 
@@ -267,10 +267,9 @@ func checkPvcName(clusterId, project, pvcName string) error {
 	}
 	defer resp.Body.Close()
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Println("error parsing body of response:", err)
-		return errors.New(genericAPIError)
+		return err
 	}
 
 	for _, v := range json.S("items").Children() {
@@ -289,7 +288,7 @@ func checkTechnology(technology string) error {
 		"gluster":
 		return nil
 	}
-	return errors.New("Invalid technology. Must be either nfs or gluster")
+	return common.NewValidationError(common.ErrorCodeInvalidRequest, "Invalid technology. Must be either nfs or gluster")
 }
 
 func createNewVolume(clusterId, project, size, pvcName, mode, technology, username, storageclass string) (*common.NewVolumeResponse, error) {
@@ -401,7 +400,7 @@ func createNfsVolume(clusterId, project, pvcName, size, username string) (*commo
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating nfs volume: %v %v", resp.StatusCode, string(errMsg))
-		return nil, errors.New(genericAPIError)
+		return nil, newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	log.Printf("%v is creating an nfs volume. CLuster: %v, Project: %v, size: %v", username, clusterId, project, size)
@@ -464,13 +463,12 @@ func getOpenshiftPV(clusterId, pvName string) (*gabs.Container, error) {
 	if resp.StatusCode != http.StatusOK {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error getting openshift pv: %v %v", resp.StatusCode, string(errMsg))
-		return nil, errors.New(genericAPIError)
+		return nil, newUpstreamError("GET", resp.StatusCode, errMsg)
 	}
 
-	json, err := gabs.ParseJSONBuffer(resp.Body)
+	json, err := parseJSONResponse(resp)
 	if err != nil {
-		log.Printf("Error parsing body of response in getOpenshiftPV(): %v", err.Error())
-		return nil, errors.New(genericAPIError)
+		return nil, err
 	}
 	return json, nil
 }
@@ -485,7 +483,7 @@ func getJob(clusterId string, jobId int) (*common.WorkflowJob, error) {
 	if resp.StatusCode != http.StatusOK {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error getting job: %v %v", resp.StatusCode, string(errMsg))
-		return nil, errors.New(genericAPIError)
+		return nil, newUpstreamError("GET", resp.StatusCode, errMsg)
 	}
 
 	var body common.WorkflowJob
@@ -565,7 +563,7 @@ func growNfsVolume(clusterId string, pv *gabs.Container, newSize string, usernam
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error getting job: %v %v", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	job := &common.WorkflowJob{}
@@ -663,7 +661,7 @@ func createOpenShiftPV(clusterId, size, pvName, server, path, mode, technology,
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating new PV: %v %v", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	log.Printf("Created the pv %v based on the request of %v on cluster %v", pvName, username, clusterId)
@@ -692,7 +690,7 @@ func createOpenShiftPVC(clusterId, project, size, pvcName, mode, username, stora
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating new PVC: %v %v", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	log.Printf("Created the pvc %v based on the request of %v on cluster %v", pvcName, username, clusterId)
@@ -737,7 +735,7 @@ func createOpenShiftGlusterService(clusterId, project string, username string) e
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating gluster service: %v %v", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	log.Printf("Created the gluster service based on the request of %v on cluster %v", username, clusterId)
@@ -767,7 +765,7 @@ func createOpenShiftGlusterEndpoint(clusterId, project, username string) error {
 	if resp.StatusCode != http.StatusCreated {
 		errMsg, _ := ioutil.ReadAll(resp.Body)
 		log.Printf("Error creating gluster endpoints: %v %v", resp.StatusCode, string(errMsg))
-		return errors.New(genericAPIError)
+		return newUpstreamError("POST", resp.StatusCode, errMsg)
 	}
 
 	log.Printf("Created the gluster endpoints based on the request of %v on cluster %v", username, clusterId)
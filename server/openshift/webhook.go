@@ -0,0 +1,100 @@
+package openshift
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Max length of the webhook message which is returned to the user
+const maxWebhookMessage = 500
+
+type projectWebhookConfig struct {
+	URL     string
+	Timeout time.Duration
+	// Create the project if the webhook is not reachable
+	FailOpen bool `mapstructure:"fail_open"`
+}
+
+// validateNewProjectWebhook sends the NewProjectCommand to project_validation_webhook, so that
+// other teams can add their approval rules. The project is refused if the webhook doesn't return 2xx.
+// The user and the requester (differs with onBehalfOf) are sent in the headers X-SSP-User and X-SSP-Requester.
+func validateNewProjectWebhook(data common.NewProjectCommand, username, requester string) error {
+	cfg := projectWebhookConfig{}
+	if err := config.UnmarshalKey("project_validation_webhook", &cfg); err != nil {
+		log.Printf("WARNING: invalid project_validation_webhook config: %v", err)
+		return common.NewApiError(common.ErrorCodeBackendError, common.ConfigNotSetError)
+	}
+	if cfg.URL == "" {
+		return nil
+	}
+	status, body, err := callProjectWebhook(cfg, data, username, requester)
+	if err != nil {
+		if cfg.FailOpen {
+			log.Printf("WARNING: Project validation webhook failed, creating project %v anyway: %v", data.Project, err)
+			return nil
+		}
+		log.Printf("Project validation webhook failed for project %v: %v", data.Project, err)
+		return common.NewApiError(common.ErrorCodeBackendError, "The project could not be validated. Please try again later")
+	}
+	if status < 200 || status > 299 {
+		log.Printf("Project validation webhook refused project %v of %v: %v", data.Project, requester, status)
+		return common.NewApiError(common.ErrorCodeForbidden, "The project was refused: "+webhookMessage(body))
+	}
+	return nil
+}
+
+func callProjectWebhook(cfg projectWebhookConfig, data common.NewProjectCommand, username, requester string) (int, []byte, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return 0, nil, err
+	}
+	client, err := common.GetOutboundClient(cfg.Timeout)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	req, err := http.NewRequest("POST", cfg.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-SSP-User", username)
+	req.Header.Set("X-SSP-Requester", requester)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
+
+// webhookMessage returns the field message of a JSON response or the text of the response
+func webhookMessage(body []byte) string {
+	var response struct {
+		Message string `json:"message"`
+	}
+	message := strings.TrimSpace(string(body))
+	if json.Unmarshal(body, &response) == nil && response.Message != "" {
+		message = response.Message
+	}
+	if message == "" {
+		return "no reason given"
+	}
+	if len(message) > maxWebhookMessage {
+		message = message[:maxWebhookMessage] + "..."
+	}
+	return message
+}
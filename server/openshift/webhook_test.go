@@ -0,0 +1,60 @@
+package openshift
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateNewProjectWebhook(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var data common.NewProjectCommand
+		if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+			t.Fatal("Invalid JSON!")
+		}
+		if r.Header.Get("X-SSP-User") != "u111111" || r.Header.Get("X-SSP-Requester") != "u222222" {
+			t.Errorf("ERROR: user and requester should be sent, got: %v", r.Header)
+		}
+		switch data.Project {
+		case "refused":
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "Billing needs approval by the unit"}`))
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer srv.Close()
+
+	config.Init("bla")
+	data := common.NewProjectCommand{Billing: "12345"}
+	data.Project = "allowed"
+	if err := validateNewProjectWebhook(data, "u111111", "u222222"); err != nil {
+		t.Errorf("ERROR: without webhook all projects should be allowed, got: %v", err)
+	}
+
+	config.Config().Set("project_validation_webhook", map[string]interface{}{"url": srv.URL, "timeout": "50ms"})
+	if err := validateNewProjectWebhook(data, "u111111", "u222222"); err != nil {
+		t.Errorf("ERROR: unexpected error: %v", err)
+	}
+
+	data.Project = "refused"
+	err := validateNewProjectWebhook(data, "u111111", "u222222")
+	if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeForbidden || apiErr.Message != "The project was refused: Billing needs approval by the unit" {
+		t.Errorf("ERROR: expected refusal with the message of the webhook, got: %v", err)
+	}
+
+	data.Project = "slow"
+	if apiErr, ok := validateNewProjectWebhook(data, "u111111", "u222222").(*common.ApiError); !ok || apiErr.Code != common.ErrorCodeBackendError {
+		t.Errorf("ERROR: fail-closed should refuse on timeout, got: %v", apiErr)
+	}
+
+	config.Config().Set("project_validation_webhook", map[string]interface{}{"url": srv.URL, "timeout": "50ms", "fail_open": true})
+	if err := validateNewProjectWebhook(data, "u111111", "u222222"); err != nil {
+		t.Errorf("ERROR: fail-open should allow the project on timeout, got: %v", err)
+	}
+}
@@ -63,3 +63,62 @@ type VolumeType struct {
 	Name string `json:"name"`
 	Id   string `json:"id"`
 }
+
+type NewEVSVolumeCommand struct {
+	Name             string `json:"name"`
+	Size             int    `json:"size"`
+	VolumeType       string `json:"volumeType"`
+	AvailabilityZone string `json:"availabilityZone"`
+}
+
+type EVSVolume struct {
+	Id               string `json:"id"`
+	Name             string `json:"name"`
+	Status           string `json:"status"`
+	Size             int    `json:"size"`
+	VolumeType       string `json:"volumeType"`
+	AvailabilityZone string `json:"availabilityZone"`
+	Requester        string `json:"requester"`
+}
+
+type EVSVolumeListResponse struct {
+	Volumes []EVSVolume `json:"volumes"`
+}
+
+type DNSZone struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TTL         int    `json:"ttl"`
+	Status      string `json:"status"`
+	RecordNum   int    `json:"recordNum"`
+}
+
+type DNSZoneListResponse struct {
+	Zones []DNSZone `json:"zones"`
+}
+
+type NewDNSRecordSetCommand struct {
+	ZoneId  string   `json:"zoneId"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Records []string `json:"records"`
+	// Seconds, default 300
+	TTL int `json:"ttl"`
+}
+
+type DNSRecordSet struct {
+	Id      string   `json:"id"`
+	ZoneId  string   `json:"zoneId"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Records []string `json:"records"`
+	TTL     int      `json:"ttl"`
+	Status  string   `json:"status"`
+	// Only set for record sets which were created in the SSP
+	Requester string `json:"requester"`
+}
+
+type DNSRecordSetListResponse struct {
+	RecordSets []DNSRecordSet `json:"recordSets"`
+}
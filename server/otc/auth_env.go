@@ -77,10 +77,8 @@ Now use the provider, you can initialize the serviceClient.
 */
 func TokenOptionsFromEnv(customTokenOptions *token.TokenOptions) (token.TokenOptions, error) {
 
-	cfg := config.Config()
-
 	var tmp tokenOptions
-	err := cfg.UnmarshalKey("openstack", &tmp)
+	err := config.UnmarshalKey("openstack", &tmp)
 	if err != nil {
 		return nilTokenOptions, err
 	}
@@ -0,0 +1,376 @@
+package otc
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/auth/token"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/dns/v2/recordsets"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultDNSTTL = 300
+	// The description of record sets created in the SSP is "requester=<username>"
+	dnsRequesterPrefix = "requester="
+	maxTXTLength       = 255
+)
+
+var dnsRecordTypes = []string{"A", "CNAME", "TXT"}
+
+// dnsZone is a zone of the OTC DNS API. The zones package of the SDK doesn't compile, so the API is called directly.
+type dnsZone struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	TTL         int    `json:"ttl"`
+	Status      string `json:"status"`
+	RecordNum   int    `json:"record_num"`
+}
+
+// Hostnames with a trailing dot, e.g. www.example.com.
+var dnsNameRegex = regexp.MustCompile(`^([a-z0-9_]([-a-z0-9_]{0,61}[a-z0-9])?\.)+$`)
+
+func listDNSZonesHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	log.Printf("%v lists DNS zones @ OTC.", username)
+
+	client, err := getDNSClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	dnsZones, err := listDNSZones(client)
+	if err != nil {
+		log.Println("Error while listing DNS zones.", err.Error())
+		respondDNSError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, DNSZoneListResponse{Zones: dnsZones})
+}
+
+func listDNSRecordSetsHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	zoneId := c.Query("zoneid")
+	if zoneId == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "Wrong API usage. Missing parameter zoneid", Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+	log.Printf("%v lists the DNS record sets of zone %v @ OTC.", username, zoneId)
+
+	client, err := getDNSClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	recordSets, err := listDNSRecordSets(client, zoneId)
+	if err != nil {
+		log.Println("Error while listing DNS record sets.", err.Error())
+		respondDNSError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, DNSRecordSetListResponse{RecordSets: recordSets})
+}
+
+func createDNSRecordSetHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data NewDNSRecordSetCommand
+	if err := c.BindJSON(&data); err != nil {
+		log.Println("Binding request to Go struct failed.", err.Error())
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		return
+	}
+	if data.ZoneId == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "The zone must be provided", Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	client, err := getDNSClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	zone, err := getDNSZone(client, data.ZoneId)
+	if err != nil {
+		log.Println("Error while getting DNS zone.", err.Error())
+		respondDNSError(c, err)
+		return
+	}
+
+	opts, err := newDNSRecordSetOpts(data, zone.Name, username)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, common.ApiResponse{Message: err.Error(), Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	recordSet, err := recordsets.Create(client, data.ZoneId, opts).Extract()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"username": username,
+			"zone":     zone.Name,
+			"name":     opts.Name,
+			"err":      err.Error(),
+		}).Error("Error while creating DNS record set")
+		respondDNSError(c, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"username": username,
+		"zone":     zone.Name,
+		"name":     recordSet.Name,
+		"type":     recordSet.Type,
+		"records":  strings.Join(recordSet.Records, ", "),
+	}).Info("AUDIT: DNS record set was created")
+
+	c.JSON(http.StatusOK, newDNSRecordSet(*recordSet))
+}
+
+// deleteDNSRecordSetHandler deletes a record set which was created by the user in the SSP
+func deleteDNSRecordSetHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	zoneId := c.Query("zoneid")
+	id := c.Query("id")
+	if zoneId == "" || id == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: "Wrong API usage. Missing parameter zoneid or id", Code: common.ErrorCodeInvalidRequest})
+		return
+	}
+
+	client, err := getDNSClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	recordSet, err := recordsets.Get(client, zoneId, id).Extract()
+	if err != nil {
+		log.Println("Error while getting DNS record set.", err.Error())
+		respondDNSError(c, err)
+		return
+	}
+	if !strings.EqualFold(dnsRequester(recordSet.Description), username) {
+		c.JSON(http.StatusForbidden, common.ApiResponse{
+			Message: "Only the record sets which you have created in the SSP can be deleted",
+			Code:    common.ErrorCodeForbidden,
+		})
+		return
+	}
+
+	if err := recordsets.Delete(client, zoneId, id).Err; err != nil {
+		log.WithFields(log.Fields{
+			"username": username,
+			"name":     recordSet.Name,
+			"err":      err.Error(),
+		}).Error("Error while deleting DNS record set")
+		respondDNSError(c, err)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"username": username,
+		"zone":     recordSet.ZoneName,
+		"name":     recordSet.Name,
+		"type":     recordSet.Type,
+		"records":  strings.Join(recordSet.Records, ", "),
+	}).Info("AUDIT: DNS record set was deleted")
+
+	c.JSON(http.StatusOK, common.ApiResponse{Message: fmt.Sprintf("The record set %v was deleted", recordSet.Name)})
+}
+
+// getDNSClient returns a client for the OTC project dns.project
+func getDNSClient() (*gophercloud.ServiceClient, error) {
+	project := config.Config().GetString("dns.project")
+	if project == "" {
+		log.Println("WARNING: dns.project must be specified")
+		return nil, errors.New(common.ConfigNotSetError)
+	}
+	provider, err := getProvider(&token.TokenOptions{TenantName: project})
+	if err != nil {
+		log.Println("Error while authenticating.", err.Error())
+		return nil, errors.New(genericOTCAPIError)
+	}
+
+	client, err := openstack.NewDNSV2(provider, gophercloud.EndpointOpts{
+		Region: "eu-ch",
+	})
+	if err != nil {
+		log.Println("Error getting client.", err.Error())
+		return nil, errors.New(genericOTCAPIError)
+	}
+	return client, nil
+}
+
+func listDNSZones(client *gophercloud.ServiceClient) ([]DNSZone, error) {
+	var response struct {
+		Zones []dnsZone `json:"zones"`
+	}
+	// 500 is the maximum page size of the API
+	url := client.ServiceURL("zones") + "?type=public&limit=500"
+	if _, err := client.Get(url, &response, nil); err != nil {
+		return nil, err
+	}
+	dnsZones := []DNSZone{}
+	for _, z := range response.Zones {
+		dnsZones = append(dnsZones, DNSZone{
+			Id:          z.ID,
+			Name:        z.Name,
+			Description: z.Description,
+			TTL:         z.TTL,
+			Status:      z.Status,
+			RecordNum:   z.RecordNum,
+		})
+	}
+	return dnsZones, nil
+}
+
+func getDNSZone(client *gophercloud.ServiceClient, zoneId string) (*dnsZone, error) {
+	var zone dnsZone
+	if _, err := client.Get(client.ServiceURL("zones", zoneId), &zone, nil); err != nil {
+		return nil, err
+	}
+	return &zone, nil
+}
+
+func listDNSRecordSets(client *gophercloud.ServiceClient, zoneId string) ([]DNSRecordSet, error) {
+	allPages, err := recordsets.ListByZone(client, zoneId, recordsets.ListByZoneOpts{}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	response, err := recordsets.ExtractRecordSets(allPages)
+	if err != nil {
+		return nil, err
+	}
+	recordSets := []DNSRecordSet{}
+	for _, r := range response.Recordsets {
+		recordSets = append(recordSets, newDNSRecordSet(r))
+	}
+	return recordSets, nil
+}
+
+func newDNSRecordSet(r recordsets.RecordSet) DNSRecordSet {
+	return DNSRecordSet{
+		Id:        r.ID,
+		ZoneId:    r.ZoneID,
+		Name:      r.Name,
+		Type:      r.Type,
+		Records:   r.Records,
+		TTL:       r.TTL,
+		Status:    r.Status,
+		Requester: dnsRequester(r.Description),
+	}
+}
+
+func dnsRequester(description string) string {
+	if !strings.HasPrefix(description, dnsRequesterPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(description, dnsRequesterPrefix)
+}
+
+// newDNSRecordSetOpts validates the command and returns the record set to create in the zone.
+// Names without a trailing dot are relative to the zone.
+func newDNSRecordSetOpts(data NewDNSRecordSetCommand, zoneName, username string) (recordsets.CreateOpts, error) {
+	recordType := strings.ToUpper(data.Type)
+	if !common.ContainsStringI(dnsRecordTypes, recordType) {
+		return recordsets.CreateOpts{}, fmt.Errorf("The type must be one of %v", strings.Join(dnsRecordTypes, ", "))
+	}
+
+	name := strings.ToLower(strings.TrimSpace(data.Name))
+	zoneName = strings.ToLower(zoneName)
+	if !strings.HasSuffix(name, ".") {
+		name += "." + zoneName
+	}
+	if !dnsNameRegex.MatchString(name) || len(name) > 254 || !strings.HasSuffix(name, "."+zoneName) {
+		return recordsets.CreateOpts{}, fmt.Errorf("The name %v is invalid or not in the zone %v", data.Name, zoneName)
+	}
+
+	if data.TTL < 0 {
+		return recordsets.CreateOpts{}, errors.New("The TTL must be positive")
+	}
+	ttl := data.TTL
+	if ttl == 0 {
+		ttl = defaultDNSTTL
+	}
+
+	records, err := validateDNSRecords(recordType, data.Records)
+	if err != nil {
+		return recordsets.CreateOpts{}, err
+	}
+
+	return recordsets.CreateOpts{
+		Name:        name,
+		Type:        recordType,
+		Records:     records,
+		TTL:         ttl,
+		Description: dnsRequesterPrefix + username,
+	}, nil
+}
+
+// validateDNSRecords checks the records of the type and returns them in the format of the OTC API
+func validateDNSRecords(recordType string, records []string) ([]string, error) {
+	if len(records) == 0 {
+		return nil, errors.New("At least one record must be provided")
+	}
+	var normalized []string
+	for _, record := range records {
+		record = strings.TrimSpace(record)
+		switch recordType {
+		case "A":
+			if ip := net.ParseIP(record); ip == nil || ip.To4() == nil {
+				return nil, fmt.Errorf("The record %v is not an IPv4 address", record)
+			}
+		case "CNAME":
+			if len(records) > 1 {
+				return nil, errors.New("A CNAME record set can only have one record")
+			}
+			record = strings.ToLower(record)
+			if !strings.HasSuffix(record, ".") {
+				record += "."
+			}
+			if !dnsNameRegex.MatchString(record) || len(record) > 254 {
+				return nil, fmt.Errorf("The record %v is not a hostname", record)
+			}
+		case "TXT":
+			// The OTC API expects the text in quotes
+			text := strings.TrimSuffix(strings.TrimPrefix(record, `"`), `"`)
+			if text == "" || len(text) > maxTXTLength || strings.Contains(text, `"`) {
+				return nil, fmt.Errorf("TXT records must have between 1 and %v characters without quotes", maxTXTLength)
+			}
+			record = `"` + text + `"`
+		}
+		normalized = append(normalized, record)
+	}
+	return normalized, nil
+}
+
+// respondDNSError maps the errors of the OTC DNS API to a status code.
+// The OTC API doesn't return specific error types, so the message is checked.
+func respondDNSError(c *gin.Context, err error) {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case isQuotaExceededError(err):
+		c.JSON(http.StatusConflict, common.ApiResponse{Message: "The quota for DNS record sets has been exceeded", Code: common.ErrorCodeQuotaExceeded})
+	case strings.Contains(msg, "forbidden") || strings.Contains(msg, "not allowed") ||
+		strings.Contains(msg, "permission") || strings.Contains(msg, "not authorized"):
+		c.JSON(http.StatusForbidden, common.ApiResponse{Message: "The SSP has no permission for this DNS operation", Code: common.ErrorCodeForbidden})
+	case strings.Contains(msg, "not found") || strings.Contains(msg, "not exist"):
+		c.JSON(http.StatusNotFound, common.ApiResponse{Message: "The DNS zone or record set doesn't exist", Code: common.ErrorCodeNotFound})
+	case strings.Contains(msg, "already exist") || strings.Contains(msg, "duplicate"):
+		c.JSON(http.StatusConflict, common.ApiResponse{Message: "The record set already exists", Code: common.ErrorCodeConflict})
+	default:
+		c.JSON(http.StatusBadGateway, common.ApiResponse{Message: genericOTCAPIError, Code: common.ErrorCodeBackendError})
+	}
+}
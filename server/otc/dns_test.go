@@ -0,0 +1,123 @@
+package otc
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gophercloud/gophercloud"
+)
+
+func TestValidateDNSRecords(t *testing.T) {
+	tests := []struct {
+		recordType string
+		records    []string
+		expected   []string
+		valid      bool
+	}{
+		{"A", []string{"10.0.0.1", " 10.0.0.2 "}, []string{"10.0.0.1", "10.0.0.2"}, true},
+		{"A", []string{"::1"}, nil, false},
+		{"A", []string{"host.example.com"}, nil, false},
+		{"A", nil, nil, false},
+		{"CNAME", []string{"Host.Example.com"}, []string{"host.example.com."}, true},
+		{"CNAME", []string{"a.example.com", "b.example.com"}, nil, false},
+		{"CNAME", []string{"10.0.0.1 x"}, nil, false},
+		{"TXT", []string{"v=spf1 -all"}, []string{`"v=spf1 -all"`}, true},
+		{"TXT", []string{`"quoted"`}, []string{`"quoted"`}, true},
+		{"TXT", []string{`""`}, nil, false},
+	}
+	for _, test := range tests {
+		records, err := validateDNSRecords(test.recordType, test.records)
+		if (err == nil) != test.valid {
+			t.Errorf("ERROR: %v records %v: expected valid=%v, got: %v", test.recordType, test.records, test.valid, err)
+			continue
+		}
+		if test.valid && !equalStrings(records, test.expected) {
+			t.Errorf("ERROR: %v records %v: expected %v, got: %v", test.recordType, test.records, test.expected, records)
+		}
+	}
+}
+
+func TestNewDNSRecordSetOpts(t *testing.T) {
+	opts, err := newDNSRecordSetOpts(NewDNSRecordSetCommand{Name: "www", Type: "a", Records: []string{"10.0.0.1"}}, "example.com.", "u123456")
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if opts.Name != "www.example.com." || opts.Type != "A" || opts.TTL != defaultDNSTTL || opts.Description != "requester=u123456" {
+		t.Errorf("ERROR: unexpected opts: %+v", opts)
+	}
+	if dnsRequester(opts.Description) != "u123456" {
+		t.Errorf("ERROR: requester should be parsed from the description, got: %v", dnsRequester(opts.Description))
+	}
+
+	invalid := []NewDNSRecordSetCommand{
+		{Name: "www", Type: "MX", Records: []string{"mail.example.com"}},
+		{Name: "www.other.com.", Type: "A", Records: []string{"10.0.0.1"}},
+		{Name: "in valid", Type: "A", Records: []string{"10.0.0.1"}},
+		{Name: "www", Type: "A", Records: []string{"10.0.0.1"}, TTL: -1},
+	}
+	for _, data := range invalid {
+		if _, err := newDNSRecordSetOpts(data, "example.com.", "u123456"); err == nil {
+			t.Errorf("ERROR: %+v should be invalid", data)
+		}
+	}
+}
+
+func TestRespondDNSError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{errors.New("Quota exceeded for resources: ['recordset']"), http.StatusConflict},
+		{errors.New("The zone does not exist"), http.StatusNotFound},
+		{errors.New("Permission denied"), http.StatusForbidden},
+		{errors.New("connection refused"), http.StatusBadGateway},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		respondDNSError(c, test.err)
+		if w.Code != test.status {
+			t.Errorf("ERROR: %v should return %v, but returned %v", test.err, test.status, w.Code)
+		}
+	}
+}
+
+func TestListDNSZones(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/v2/zones" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"zones": [{"id": "z1", "name": "example.com.", "ttl": 300, "status": "ACTIVE", "record_num": 3}]}`))
+	}))
+	defer server.Close()
+
+	client := &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{TokenID: "token"},
+		Endpoint:       server.URL + "/v2/",
+	}
+	dnsZones, err := listDNSZones(client)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(dnsZones) != 1 || dnsZones[0].Id != "z1" || dnsZones[0].Name != "example.com." || dnsZones[0].RecordNum != 3 {
+		t.Errorf("ERROR: unexpected zones: %+v", dnsZones)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
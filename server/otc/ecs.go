@@ -92,7 +92,7 @@ func listImagesHandler(c *gin.Context) {
 		Value string `json:"value"`
 	}
 	images := []labelValue{}
-	err := config.Config().UnmarshalKey("uos.images", &images)
+	err := config.UnmarshalKey("uos.images", &images)
 	if err != nil {
 		log.Printf("Error getting images: %v", err)
 		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: common.ConfigNotSetError})
@@ -0,0 +1,143 @@
+package otc
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	log "github.com/sirupsen/logrus"
+)
+
+const evsRequesterMetadata = "requester"
+
+func listEVSVolumesHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+	log.Printf("%v lists EVS volumes @ OTC.", username)
+
+	client, err := getBlockStorageClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	allPages, err := volumes.List(client, volumes.ListOpts{}).AllPages()
+	if err != nil {
+		log.Println("Error while listing volumes.", err.Error())
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: genericOTCAPIError})
+		return
+	}
+
+	allVolumes, err := volumes.ExtractVolumes(allPages)
+	if err != nil {
+		log.Println("Error while extracting volumes.", err.Error())
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: genericOTCAPIError})
+		return
+	}
+
+	result := EVSVolumeListResponse{
+		Volumes: []EVSVolume{},
+	}
+	for _, v := range allVolumes {
+		result.Volumes = append(result.Volumes, EVSVolume{
+			Id:               v.ID,
+			Name:             v.Name,
+			Status:           v.Status,
+			Size:             v.Size,
+			VolumeType:       v.VolumeType,
+			AvailabilityZone: v.AvailabilityZone,
+			Requester:        v.Metadata[evsRequesterMetadata],
+		})
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func createEVSVolumeHandler(c *gin.Context) {
+	username := common.GetUserName(c)
+
+	var data NewEVSVolumeCommand
+	if err := c.BindJSON(&data); err != nil {
+		log.Println("Binding request to Go struct failed.", err.Error())
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: wrongAPIUsageError})
+		return
+	}
+
+	if err := validateNewEVSVolume(data); err != nil {
+		common.RespondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	client, err := getBlockStorageClient()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: err.Error()})
+		return
+	}
+
+	opts := volumes.CreateOpts{
+		Name:             data.Name,
+		Size:             data.Size,
+		VolumeType:       data.VolumeType,
+		AvailabilityZone: data.AvailabilityZone,
+		Metadata: map[string]string{
+			evsRequesterMetadata: username,
+		},
+	}
+	volume, err := volumes.Create(client, opts).Extract()
+	if err != nil {
+		log.WithFields(log.Fields{
+			"username": username,
+			"name":     data.Name,
+			"err":      err.Error(),
+		}).Error("Error while creating volume")
+		if isQuotaExceededError(err) {
+			c.JSON(http.StatusConflict, common.ApiResponse{Message: "The quota for volumes has been exceeded"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, common.ApiResponse{Message: genericOTCAPIError})
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"username": username,
+		"volume":   volume.ID,
+		"size":     volume.Size,
+	}).Info("EVS volume was created")
+
+	c.JSON(http.StatusOK, EVSVolume{
+		Id:               volume.ID,
+		Name:             volume.Name,
+		Status:           volume.Status,
+		Size:             volume.Size,
+		VolumeType:       volume.VolumeType,
+		AvailabilityZone: volume.AvailabilityZone,
+		Requester:        username,
+	})
+}
+
+func validateNewEVSVolume(data NewEVSVolumeCommand) error {
+	maxSize := config.Config().GetInt("evs.max_volume_gb")
+	if maxSize == 0 {
+		log.Println("WARNING: Env variable 'EVS_MAX_VOLUME_GB' must be specified and a valid integer")
+		return common.NewApiError(common.ErrorCodeBackendError, common.ConfigNotSetError)
+	}
+
+	if common.ContainsEmptyString(data.Name, data.VolumeType, data.AvailabilityZone) {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, "Name, volume type and availability zone must be provided")
+	}
+
+	if data.Size <= 0 || data.Size > maxSize {
+		return common.NewValidationError(common.ErrorCodeInvalidRequest, fmt.Sprintf("The size must be between 1 and %v GB", maxSize))
+	}
+	return nil
+}
+
+// The OTC API doesn't return a specific error type for quota errors.
+// The message contains "quota" or "exceeded" (e.g. VolumeLimitExceeded)
+func isQuotaExceededError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "quota") || strings.Contains(msg, "exceeded")
+}
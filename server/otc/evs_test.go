@@ -0,0 +1,62 @@
+package otc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestValidateNewEVSVolume(t *testing.T) {
+	valid := NewEVSVolumeCommand{Name: "data", Size: 10, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}
+	tests := []struct {
+		maxSize int
+		data    NewEVSVolumeCommand
+		code    string
+	}{
+		{100, valid, ""},
+		{100, NewEVSVolumeCommand{Name: "data", Size: 100, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}, ""},
+		{100, NewEVSVolumeCommand{Name: "data", Size: 101, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}, common.ErrorCodeInvalidRequest},
+		{100, NewEVSVolumeCommand{Name: "data", Size: 0, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}, common.ErrorCodeInvalidRequest},
+		{100, NewEVSVolumeCommand{Name: "data", Size: -1, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}, common.ErrorCodeInvalidRequest},
+		{100, NewEVSVolumeCommand{Size: 10, VolumeType: "SATA", AvailabilityZone: "eu-ch-01"}, common.ErrorCodeInvalidRequest},
+		{100, NewEVSVolumeCommand{Name: "data", Size: 10, AvailabilityZone: "eu-ch-01"}, common.ErrorCodeInvalidRequest},
+		{100, NewEVSVolumeCommand{Name: "data", Size: 10, VolumeType: "SATA"}, common.ErrorCodeInvalidRequest},
+		// evs.max_volume_gb is missing
+		{0, valid, common.ErrorCodeBackendError},
+	}
+	for _, test := range tests {
+		config.Init("bla")
+		if test.maxSize > 0 {
+			config.Config().Set("evs.max_volume_gb", test.maxSize)
+		}
+		err := validateNewEVSVolume(test.data)
+		if test.code == "" {
+			if err != nil {
+				t.Errorf("ERROR: %+v should be valid, got: %v", test.data, err)
+			}
+			continue
+		}
+		if apiErr, ok := err.(*common.ApiError); !ok || apiErr.Code != test.code {
+			t.Errorf("ERROR: %+v should return %v, got: %v", test.data, test.code, err)
+		}
+	}
+}
+
+func TestIsQuotaExceededError(t *testing.T) {
+	tests := []struct {
+		err      error
+		exceeded bool
+	}{
+		{errors.New("VolumeLimitExceeded: Maximum number of volumes allowed (10) exceeded"), true},
+		{errors.New("Requested volume or snapshot exceeds allowed gigabytes quota"), true},
+		{errors.New("Invalid volume type"), false},
+		{errors.New("Bad request with: [POST https://evs.example.com/v2/volumes]"), false},
+	}
+	for _, test := range tests {
+		if isQuotaExceededError(test.err) != test.exceeded {
+			t.Errorf("ERROR: %v should be a quota error: %v", test.err, test.exceeded)
+		}
+	}
+}
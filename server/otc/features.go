@@ -7,6 +7,8 @@ import (
 type Features struct {
 	UOS bool `json:"uos"`
 	RDS bool `json:"rds"`
+	// DNS zones and record sets, enabled if dns.project is set
+	DNS bool `json:"dns"`
 }
 
 func GetFeatures() Features {
@@ -17,5 +19,6 @@ func GetFeatures() Features {
 	return Features{
 		UOS: uosEnabled == "true",
 		RDS: rdsEnabled == "true",
+		DNS: cfg.GetString("dns.project") != "",
 	}
 }
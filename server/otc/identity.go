@@ -0,0 +1,101 @@
+package otc
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/auth/token"
+	"github.com/gophercloud/gophercloud/openstack"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/projects"
+	"github.com/gophercloud/gophercloud/openstack/identity/v3/tokens"
+	log "github.com/sirupsen/logrus"
+)
+
+// OTCProject is a project (tenant) in an OTC domain
+type OTCProject struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// listProjectsHandler lists the projects of the domain given by the parameter domain
+// or openstack.domain_name
+func listProjectsHandler(c *gin.Context) {
+	domain := c.Request.URL.Query().Get("domain")
+	if domain == "" {
+		domain = config.Config().GetString("openstack.domain_name")
+	}
+	if domain == "" {
+		c.JSON(http.StatusBadRequest, common.ApiResponse{
+			Message: "Wrong API usage. Missing parameter domain and openstack.domain_name is not configured",
+			Code:    common.ErrorCodeInvalidRequest,
+		})
+		return
+	}
+
+	client, err := getIdentityClient(domain)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, common.ApiResponse{Message: err.Error(), Code: common.ErrorCodeBackendError})
+		return
+	}
+
+	otcProjects, err := getProjects(client)
+	if err != nil {
+		log.Errorf("Error listing the projects of domain %v: %v", domain, err)
+		c.JSON(http.StatusBadGateway, common.ApiResponse{Message: genericOTCAPIError, Code: common.ErrorCodeBackendError})
+		return
+	}
+	c.JSON(http.StatusOK, otcProjects)
+}
+
+func getIdentityClient(domain string) (*gophercloud.ServiceClient, error) {
+	to := token.TokenOptions{
+		DomainName: domain,
+	}
+	provider, err := getProvider(&to)
+	if err != nil {
+		log.Errorf("Error while authenticating: %v", err)
+		return nil, errors.New(genericOTCAPIError)
+	}
+
+	client, err := openstack.NewIdentityV3(provider, gophercloud.EndpointOpts{})
+	if err != nil {
+		log.Errorf("Error getting client: %v", err)
+		return nil, errors.New(genericOTCAPIError)
+	}
+	return client, nil
+}
+
+// getProjects returns the projects in the domain of the authenticated user
+func getProjects(client *gophercloud.ServiceClient) ([]OTCProject, error) {
+	user, err := tokens.Get(client, client.TokenID).ExtractUser()
+	if err != nil {
+		return nil, err
+	}
+	if user == nil || user.Domain.ID == "" {
+		return nil, errors.New("Token contains no domain")
+	}
+
+	pages, err := projects.List(client, projects.ListOpts{DomainID: user.Domain.ID}).AllPages()
+	if err != nil {
+		return nil, err
+	}
+	allProjects, err := projects.ExtractProjects(pages)
+	if err != nil {
+		return nil, err
+	}
+
+	otcProjects := []OTCProject{}
+	for _, p := range allProjects {
+		otcProjects = append(otcProjects, OTCProject{
+			ID:      p.ID,
+			Name:    p.Name,
+			Enabled: p.Enabled,
+		})
+	}
+	return otcProjects, nil
+}
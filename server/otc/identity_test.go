@@ -0,0 +1,57 @@
+package otc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	"github.com/gin-gonic/gin"
+	"github.com/gophercloud/gophercloud"
+)
+
+func TestListProjectsWithoutDomain(t *testing.T) {
+	config.Init("bla")
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request, _ = http.NewRequest("GET", "/otc/projects", nil)
+
+	listProjectsHandler(c)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("ERROR: missing domain should return 400, but returned %v", w.Code)
+	}
+}
+
+func TestGetProjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/v3/auth/tokens":
+			w.Write([]byte(`{"token": {"user": {"id": "u1", "name": "user", "domain": {"id": "d1", "name": "domain"}}}}`))
+		case "/v3/projects":
+			if r.URL.Query().Get("domain_id") != "d1" {
+				t.Errorf("ERROR: projects should be listed in domain d1, but query is: %v", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"projects": [
+				{"id": "p1", "name": "eu-ch_managed", "enabled": true, "domain_id": "d1"},
+				{"id": "p2", "name": "eu-ch_old", "enabled": false, "domain_id": "d1"}
+			], "links": {}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &gophercloud.ServiceClient{
+		ProviderClient: &gophercloud.ProviderClient{TokenID: "token"},
+		Endpoint:       server.URL + "/v3/",
+	}
+	otcProjects, err := getProjects(client)
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(otcProjects) != 2 || otcProjects[0].ID != "p1" || !otcProjects[0].Enabled || otcProjects[1].Enabled {
+		t.Errorf("ERROR: unexpected projects: %+v", otcProjects)
+	}
+}
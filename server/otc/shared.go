@@ -3,10 +3,13 @@ package otc
 import (
 	"errors"
 	"fmt"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
 	"github.com/gin-gonic/gin"
 	"github.com/gophercloud/gophercloud"
 	"github.com/gophercloud/gophercloud/auth/token"
 	"github.com/gophercloud/gophercloud/openstack"
+	"net/http"
 	"time"
 )
 
@@ -25,6 +28,21 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	r.GET("/otc/rds/versions", listRDSVersionsHandler)
 	r.GET("/otc/rds/flavors", listRDSFlavorsHandler)
 	r.GET("/otc/rds/instances", listRDSInstancesHandler)
+	r.GET("/otc/evs/volumes", listEVSVolumesHandler)
+	r.POST("/otc/evs/volumes", createEVSVolumeHandler)
+	r.GET("/otc/projects", listProjectsHandler)
+	r.GET("/otc/dns/zones", listDNSZonesHandler)
+	r.GET("/otc/dns/recordsets", listDNSRecordSetsHandler)
+	r.POST("/otc/dns/recordsets", createDNSRecordSetHandler)
+	r.DELETE("/otc/dns/recordsets", deleteDNSRecordSetHandler)
+}
+
+// ValidateProxy checks the openstack.proxy config at startup
+func ValidateProxy() error {
+	if _, err := common.GetProxyFunc(config.Config().GetString("openstack.proxy")); err != nil {
+		return fmt.Errorf("Invalid openstack.proxy: %v", err)
+	}
+	return nil
 }
 
 func getProvider(to *token.TokenOptions) (*gophercloud.ProviderClient, error) {
@@ -33,7 +51,15 @@ func getProvider(to *token.TokenOptions) (*gophercloud.ProviderClient, error) {
 		return nil, err
 	}
 
-	provider, err := openstack.AuthenticatedClient(opts)
+	// OTC is only reachable through the proxy
+	proxy, err := common.GetProxyFunc(config.Config().GetString("openstack.proxy"))
+	if err != nil {
+		return nil, err
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	conf := gophercloud.NewConfig().WithHttpTransport(transport)
+	provider, err := openstack.AuthenticatedClientWithOptions(opts, conf)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,152 @@
+package publisher
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/common"
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+	log "github.com/sirupsen/logrus"
+)
+
+// Actions of the project lifecycle events
+const (
+	ProjectCreated    = "project.created"
+	ProjectUpdated    = "project.updated"
+	ProjectArchived   = "project.archived"
+	ProjectUnarchived = "project.unarchived"
+	ProjectDeleted    = "project.deleted"
+)
+
+// Event is published to the message queue
+type Event struct {
+	Action    string    `json:"action"`
+	Cluster   string    `json:"cluster"`
+	Project   string    `json:"project"`
+	User      string    `json:"user"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Publisher sends events to a message queue
+type Publisher interface {
+	Publish(event Event) error
+}
+
+type publisherConfig struct {
+	// none (default) or kafka-rest
+	Backend string
+	URL     string
+	Topic   string
+	Timeout time.Duration
+}
+
+// noopPublisher drops all events. It is used if no backend is configured.
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(event Event) error {
+	return nil
+}
+
+// kafkaRESTPublisher produces the events to a topic of a Kafka REST proxy
+type kafkaRESTPublisher struct {
+	url    string
+	topic  string
+	client *http.Client
+}
+
+func (p kafkaRESTPublisher) Publish(event Event) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"records": []map[string]interface{}{
+			{"key": event.Cluster + "/" + event.Project, "value": event},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Post(strings.TrimSuffix(p.url, "/")+"/topics/"+p.topic, "application/vnd.kafka.json.v2+json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		errMsg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("Kafka REST proxy returned %v: %v", resp.StatusCode, string(errMsg))
+	}
+	return nil
+}
+
+func getPublisher() (Publisher, error) {
+	cfg := publisherConfig{}
+	if err := config.UnmarshalKey("event_publisher", &cfg); err != nil {
+		return nil, err
+	}
+	switch cfg.Backend {
+	case "", "none":
+		return noopPublisher{}, nil
+	case "kafka-rest":
+		if cfg.URL == "" || cfg.Topic == "" {
+			return nil, fmt.Errorf("event_publisher.url and event_publisher.topic must be set for the kafka-rest backend")
+		}
+		client, err := common.GetOutboundClient(cfg.Timeout)
+		if err != nil {
+			return nil, err
+		}
+		return kafkaRESTPublisher{
+			url:    cfg.URL,
+			topic:  cfg.Topic,
+			client: client,
+		}, nil
+	}
+	return nil, fmt.Errorf("Unknown event_publisher.backend: %v", cfg.Backend)
+}
+
+var (
+	publisherMu sync.Mutex
+	// current is created by ValidateConfig and shared by all events
+	current Publisher = noopPublisher{}
+)
+
+// ValidateConfig checks the event_publisher config at startup and creates the publisher.
+// Until then, events are dropped.
+func ValidateConfig() error {
+	p, err := getPublisher()
+	if err != nil {
+		return err
+	}
+	publisherMu.Lock()
+	current = p
+	publisherMu.Unlock()
+	return nil
+}
+
+// Publish sends a project lifecycle event in the background.
+// Errors are only logged, so the request of the user doesn't fail.
+func Publish(action, clusterId, project, username string) {
+	event := Event{
+		Action:    action,
+		Cluster:   clusterId,
+		Project:   project,
+		User:      username,
+		Timestamp: time.Now().UTC(),
+	}
+	go publish(event)
+}
+
+func publish(event Event) {
+	publisherMu.Lock()
+	p := current
+	publisherMu.Unlock()
+	if err := p.Publish(event); err != nil {
+		log.WithFields(log.Fields{
+			"action":  event.Action,
+			"cluster": event.Cluster,
+			"project": event.Project,
+		}).Errorf("Error publishing event: %v", err)
+	}
+}
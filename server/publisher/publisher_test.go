@@ -0,0 +1,103 @@
+package publisher
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/config"
+)
+
+func TestGetPublisher(t *testing.T) {
+	config.Init("bla")
+	if p, err := getPublisher(); err != nil || p != (noopPublisher{}) {
+		t.Errorf("ERROR: without config the noop publisher should be used, got: %v, %v", p, err)
+	}
+
+	config.Config().Set("event_publisher", map[string]interface{}{"backend": "nats"})
+	if err := ValidateConfig(); err == nil {
+		t.Error("ERROR: unknown backends should be invalid")
+	}
+
+	config.Config().Set("event_publisher", map[string]interface{}{"backend": "kafka-rest"})
+	if err := ValidateConfig(); err == nil {
+		t.Error("ERROR: kafka-rest without url and topic should be invalid")
+	}
+}
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	var records struct {
+		Records []struct {
+			Key   string `json:"key"`
+			Value Event  `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/ssp-events" {
+			t.Errorf("ERROR: event should be sent to the topic, got: %v", r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != "application/vnd.kafka.json.v2+json" {
+			t.Errorf("ERROR: unexpected Content-Type: %v", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+			t.Fatal("Invalid JSON!")
+		}
+		w.Write([]byte(`{"offsets": [{"partition": 0, "offset": 1}]}`))
+	}))
+	defer srv.Close()
+
+	config.Init("bla")
+	config.Config().Set("event_publisher", map[string]interface{}{"backend": "kafka-rest", "url": srv.URL + "/", "topic": "ssp-events"})
+	p, err := getPublisher()
+	if err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+
+	event := Event{Action: ProjectCreated, Cluster: "awsdev", Project: "project", User: "u123456", Timestamp: time.Now().UTC()}
+	if err := p.Publish(event); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	if len(records.Records) != 1 || records.Records[0].Key != "awsdev/project" || records.Records[0].Value.Action != ProjectCreated || records.Records[0].Value.User != "u123456" {
+		t.Errorf("ERROR: unexpected records: %+v", records)
+	}
+
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	if err := p.Publish(event); err == nil {
+		t.Error("ERROR: errors of the REST proxy should be returned")
+	}
+}
+
+func TestPublishUsesPublisherOfStartup(t *testing.T) {
+	events := make(chan string, 2)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events <- r.URL.Path
+	}))
+	defer srv.Close()
+	defer func() {
+		publisherMu.Lock()
+		current = noopPublisher{}
+		publisherMu.Unlock()
+	}()
+
+	config.Init("bla")
+	config.Config().Set("event_publisher", map[string]interface{}{"backend": "kafka-rest", "url": srv.URL, "topic": "ssp-events"})
+	if err := ValidateConfig(); err != nil {
+		t.Fatalf("ERROR: unexpected error: %v", err)
+	}
+	// later changes of the config are not read for every event
+	config.Config().Set("event_publisher", map[string]interface{}{"backend": "nats"})
+
+	Publish(ProjectCreated, "awsdev", "project", "u123456")
+	select {
+	case path := <-events:
+		if path != "/topics/ssp-events" {
+			t.Errorf("ERROR: event should be sent to the topic, got: %v", path)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("ERROR: the event should be sent with the publisher of ValidateConfig")
+	}
+}
@@ -0,0 +1,3174 @@
+package swagger
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes registers the public route with the OpenAPI spec
+func RegisterRoutes(r *gin.Engine) {
+	r.GET("/swagger", specHandler)
+}
+
+func specHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", []byte(spec))
+}
+
+// spec is the OpenAPI spec of the API. The request and response schemas
+// must be kept in sync with the commands in the common package.
+// The tests check the error codes and the OpenShift routes against the code.
+const spec = `{
+  "openapi": "3.0.0",
+  "info": {
+    "title": "Cloud SSP",
+    "version": "1.0"
+  },
+  "servers": [
+    {
+      "url": "/api"
+    }
+  ],
+  "components": {
+    "securitySchemes": {
+      "bearer": {
+        "type": "http",
+        "scheme": "bearer"
+      }
+    },
+    "schemas": {
+      "ApiResponse": {
+        "type": "object",
+        "properties": {
+          "message": {
+            "type": "string"
+          },
+          "code": {
+            "type": "string",
+            "description": "Only set for errors",
+            "enum": [
+              "INVALID_REQUEST",
+              "INVALID_BILLING",
+              "FORBIDDEN",
+              "PROJECT_NOT_FOUND",
+              "PROJECT_EXISTS",
+              "BACKEND_ERROR",
+              "MAINTENANCE",
+              "RATE_LIMITED",
+              "BACKEND_BUSY",
+              "CONFLICT",
+              "CLUSTER_UNAVAILABLE",
+              "PRECONDITION_FAILED",
+              "NOT_FOUND",
+              "QUOTA_EXCEEDED"
+            ]
+          },
+          "warnings": {
+            "type": "array",
+            "description": "Only set for successful requests, e.g. conditions which didn't block a new project",
+            "items": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "OpenshiftBase": {
+        "type": "object",
+        "properties": {
+          "project": {
+            "type": "string"
+          },
+          "clusterid": {
+            "type": "string"
+          }
+        }
+      },
+      "NewServiceAccountTokenCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "serviceAccount": {
+                "type": "string"
+              }
+            }
+          }
+        ]
+      },
+      "ServiceAccountTokenResponse": {
+        "type": "object",
+        "properties": {
+          "serviceAccount": {
+            "type": "string"
+          },
+          "token": {
+            "type": "string",
+            "description": "Only returned in this response, it is never logged"
+          }
+        }
+      },
+      "NewProjectCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "billing": {
+                "type": "string"
+              },
+              "megaId": {
+                "type": "string"
+              },
+              "operators": {
+                "type": "array",
+                "items": {
+                  "type": "string"
+                }
+              },
+              "onBehalfOf": {
+                "type": "string",
+                "description": "Only allowed for members of the service desk group"
+              },
+              "ownerGroup": {
+                "type": "string",
+                "description": "LDAP group of the team which owns the project"
+              },
+              "template": {
+                "type": "string",
+                "description": "Name of a project template. The objects of the template are created in the project"
+              },
+              "quotaTier": {
+                "type": "string",
+                "description": "Name of a quota tier, e.g. small, medium or large"
+              },
+              "classification": {
+                "type": "string",
+                "description": "Data classification, one of data_classifications (default: public, internal, confidential). Required"
+              },
+              "nodeSelector": {
+                "type": "string",
+                "description": "Node selector of the project, e.g. zone=a. Overrides the default of the cluster. Only allowed for members of the power user group"
+              }
+            }
+          }
+        ]
+      },
+      "NewProjectManifestCommand": {
+        "type": "object",
+        "required": [
+          "clusterid",
+          "billing",
+          "classification",
+          "manifest"
+        ],
+        "properties": {
+          "clusterid": {
+            "type": "string"
+          },
+          "billing": {
+            "type": "string"
+          },
+          "megaId": {
+            "type": "string"
+          },
+          "operators": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "At most max_project_operators (default 50) users"
+          },
+          "ownerGroup": {
+            "type": "string"
+          },
+          "classification": {
+            "type": "string"
+          },
+          "manifest": {
+            "type": "object",
+            "description": "ProjectRequest (project.openshift.io/v1). Only metadata.name, metadata.labels, metadata.annotations, displayName and description are allowed. Labels and annotations of manifest_reserved_domains are rejected."
+          }
+        }
+      },
+      "AddProjectAdminCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "username": {
+                "type": "string"
+              }
+            }
+          }
+        ]
+      },
+      "ProjectGroupCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "group": {
+                "type": "string"
+              },
+              "role": {
+                "type": "string",
+                "enum": [
+                  "admin",
+                  "operator"
+                ]
+              }
+            }
+          }
+        ]
+      },
+      "CopyPermissionsCommand": {
+        "type": "object",
+        "properties": {
+          "source": {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          "target": {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          "mode": {
+            "type": "string",
+            "enum": [
+              "merge",
+              "replace"
+            ],
+            "default": "merge",
+            "description": "replace removes the users and groups of the target which are not in the source, except the current user"
+          }
+        }
+      },
+      "PermissionSubject": {
+        "type": "object",
+        "properties": {
+          "role": {
+            "type": "string",
+            "enum": [
+              "admin",
+              "operator"
+            ]
+          },
+          "kind": {
+            "type": "string",
+            "enum": [
+              "User",
+              "Group"
+            ]
+          },
+          "name": {
+            "type": "string"
+          }
+        }
+      },
+      "CopyPermissionsResult": {
+        "type": "object",
+        "properties": {
+          "added": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/PermissionSubject"
+            }
+          },
+          "removed": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/PermissionSubject"
+            }
+          }
+        }
+      },
+      "UpdateProjectInformationCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "billing": {
+                "type": "string"
+              },
+              "megaid": {
+                "type": "string"
+              },
+              "ownerGroup": {
+                "type": "string",
+                "description": "LDAP group of the team which owns the project"
+              },
+              "classification": {
+                "type": "string",
+                "description": "Data classification"
+              }
+            }
+          }
+        ],
+        "description": "Partial update: fields which are missing or empty keep the current value of the project"
+      },
+      "UpdateProjectDisplayNameCommand": {
+        "allOf": [
+          {
+            "$ref": "#/components/schemas/OpenshiftBase"
+          },
+          {
+            "type": "object",
+            "properties": {
+              "displayName": {
+                "type": "string",
+                "maxLength": 200
+              }
+            }
+          }
+        ]
+      },
+      "AdminList": {
+        "type": "object",
+        "properties": {
+          "admins": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "RoleBinding": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "role": {
+            "type": "string"
+          },
+          "users": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "groups": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "ProjectInformation": {
+        "type": "object",
+        "properties": {
+          "kontierungsnummer": {
+            "type": "string"
+          },
+          "megaid": {
+            "type": "string"
+          },
+          "requester": {
+            "type": "string"
+          },
+          "ownerGroup": {
+            "type": "string",
+            "description": "LDAP group of the team which owns the project"
+          },
+          "isTestProject": {
+            "type": "boolean"
+          },
+          "deletionDate": {
+            "type": "string",
+            "format": "date"
+          },
+          "classification": {
+            "type": "string"
+          },
+          "ownership": {
+            "$ref": "#/components/schemas/ProjectOwnership"
+          }
+        }
+      },
+      "ProjectOwnership": {
+        "type": "object",
+        "description": "Team which owns the project according to the CMDB. Only set if cmdb is configured and knows the project",
+        "properties": {
+          "team": {
+            "type": "string"
+          },
+          "owner": {
+            "type": "string"
+          },
+          "email": {
+            "type": "string"
+          }
+        }
+      },
+      "ProjectInformationBatchCommand": {
+        "type": "object",
+        "properties": {
+          "projects": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/OpenshiftBase"
+            }
+          }
+        }
+      },
+      "ProjectInformationResult": {
+        "type": "object",
+        "properties": {
+          "clusterid": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "information": {
+            "$ref": "#/components/schemas/ProjectInformation"
+          },
+          "error": {
+            "type": "string"
+          },
+          "code": {
+            "type": "string",
+            "description": "Error code, e.g. FORBIDDEN"
+          }
+        }
+      },
+      "Quota": {
+        "type": "object",
+        "properties": {
+          "cpuMillicores": {
+            "type": "integer",
+            "format": "int64"
+          },
+          "memoryBytes": {
+            "type": "integer",
+            "format": "int64"
+          }
+        }
+      },
+      "QuotaLimits": {
+        "type": "object",
+        "properties": {
+          "default": {
+            "$ref": "#/components/schemas/Quota"
+          },
+          "max": {
+            "$ref": "#/components/schemas/Quota"
+          }
+        }
+      },
+      "QuotaIncreaseCommand": {
+        "type": "object",
+        "required": [
+          "clusterid",
+          "project",
+          "cpu",
+          "memory",
+          "justification"
+        ],
+        "properties": {
+          "clusterid": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "cpu": {
+            "type": "integer",
+            "description": "CPU cores"
+          },
+          "memory": {
+            "type": "integer",
+            "description": "Memory in GiB"
+          },
+          "justification": {
+            "type": "string"
+          }
+        }
+      },
+      "QuotaRequest": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string"
+          },
+          "command": {
+            "$ref": "#/components/schemas/QuotaIncreaseCommand"
+          },
+          "username": {
+            "type": "string"
+          },
+          "mail": {
+            "type": "string"
+          },
+          "current": {
+            "$ref": "#/components/schemas/Quota"
+          },
+          "requested": {
+            "$ref": "#/components/schemas/Quota"
+          },
+          "requestedAt": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "QuotaChange": {
+        "type": "object",
+        "properties": {
+          "timestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "oldQuota": {
+            "$ref": "#/components/schemas/Quota"
+          },
+          "newQuota": {
+            "$ref": "#/components/schemas/Quota"
+          },
+          "username": {
+            "type": "string"
+          },
+          "reason": {
+            "type": "string",
+            "description": "e.g. the quota tier or the approved quota request"
+          }
+        }
+      },
+      "BillingChange": {
+        "type": "object",
+        "properties": {
+          "timestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "oldBilling": {
+            "type": "string",
+            "description": "Empty if the billing was set for the first time"
+          },
+          "newBilling": {
+            "type": "string"
+          },
+          "username": {
+            "type": "string"
+          }
+        }
+      },
+      "ProjectEvent": {
+        "type": "object",
+        "properties": {
+          "type": {
+            "type": "string"
+          },
+          "reason": {
+            "type": "string"
+          },
+          "message": {
+            "type": "string"
+          },
+          "involvedObject": {
+            "type": "object",
+            "properties": {
+              "kind": {
+                "type": "string"
+              },
+              "name": {
+                "type": "string"
+              }
+            }
+          },
+          "lastTimestamp": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "ProjectSummary": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "creationTimestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "phase": {
+            "type": "string",
+            "description": "Active or Terminating"
+          }
+        }
+      },
+      "BillingProject": {
+        "type": "object",
+        "properties": {
+          "cluster": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "billing": {
+            "type": "string"
+          },
+          "requester": {
+            "type": "string"
+          },
+          "megaId": {
+            "type": "string"
+          }
+        }
+      },
+      "TerminatingProject": {
+        "type": "object",
+        "properties": {
+          "cluster": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "deletionTimestamp": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "terminatingSeconds": {
+            "type": "integer",
+            "description": "Seconds since the deletion has started"
+          },
+          "finalizers": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "conditions": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            },
+            "description": "Messages of the conditions which block the deletion"
+          }
+        }
+      },
+      "ProjectExistence": {
+        "type": "object",
+        "properties": {
+          "project": {
+            "type": "string"
+          },
+          "exists": {
+            "type": "object",
+            "description": "Cluster ID and whether the project exists on the cluster",
+            "additionalProperties": {
+              "type": "boolean"
+            }
+          },
+          "errors": {
+            "type": "object",
+            "description": "Cluster ID and the error of the clusters which couldn't be checked",
+            "additionalProperties": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "ProjectRole": {
+        "type": "object",
+        "properties": {
+          "role": {
+            "type": "string",
+            "enum": [
+              "admin",
+              "edit",
+              "view",
+              "none"
+            ]
+          }
+        }
+      },
+      "CleanupResult": {
+        "type": "object",
+        "properties": {
+          "deleted": {
+            "type": "integer"
+          },
+          "failed": {
+            "type": "integer"
+          },
+          "warned": {
+            "type": "integer",
+            "description": "Test projects whose requester has been warned about the deletion"
+          }
+        }
+      },
+      "CostEstimate": {
+        "type": "object",
+        "properties": {
+          "cluster": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "currency": {
+            "type": "string"
+          },
+          "cpuCores": {
+            "type": "number"
+          },
+          "memoryGiB": {
+            "type": "number"
+          },
+          "storageGiB": {
+            "type": "number"
+          },
+          "cpuCost": {
+            "type": "number",
+            "description": "Monthly cost"
+          },
+          "memoryCost": {
+            "type": "number",
+            "description": "Monthly cost"
+          },
+          "storageCost": {
+            "type": "number",
+            "description": "Monthly cost"
+          },
+          "total": {
+            "type": "number",
+            "description": "Monthly cost"
+          },
+          "note": {
+            "type": "string",
+            "description": "Set if the cost can't be estimated, e.g. if the project has no quota"
+          }
+        }
+      },
+      "ProjectRequestCommand": {
+        "type": "object",
+        "required": [
+          "id"
+        ],
+        "properties": {
+          "id": {
+            "type": "string"
+          },
+          "reason": {
+            "type": "string",
+            "description": "Only used for rejections, the reason is sent to the user"
+          }
+        }
+      },
+      "PendingProject": {
+        "type": "object",
+        "properties": {
+          "id": {
+            "type": "string"
+          },
+          "command": {
+            "$ref": "#/components/schemas/NewProjectCommand"
+          },
+          "username": {
+            "type": "string"
+          },
+          "requester": {
+            "type": "string"
+          },
+          "mail": {
+            "type": "string"
+          },
+          "requestedAt": {
+            "type": "string",
+            "format": "date-time"
+          }
+        }
+      },
+      "ProjectExport": {
+        "type": "object",
+        "properties": {
+          "cluster": {
+            "type": "string"
+          },
+          "project": {
+            "type": "string"
+          },
+          "exportedAt": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "exportedBy": {
+            "type": "string"
+          },
+          "annotations": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "labels": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "quota": {
+            "type": "object",
+            "additionalProperties": {
+              "type": "string"
+            },
+            "description": "Hard limits of the ResourceQuota. Missing if the project has no quota"
+          },
+          "rolebindings": {
+            "type": "array",
+            "items": {
+              "type": "object",
+              "properties": {
+                "name": {
+                  "type": "string"
+                },
+                "role": {
+                  "type": "string"
+                },
+                "subjects": {
+                  "type": "array",
+                  "items": {
+                    "type": "object",
+                    "properties": {
+                      "kind": {
+                        "type": "string"
+                      },
+                      "name": {
+                        "type": "string"
+                      },
+                      "namespace": {
+                        "type": "string"
+                      }
+                    }
+                  }
+                }
+              }
+            }
+          }
+        }
+      },
+      "Subscription": {
+        "type": "object",
+        "properties": {
+          "name": {
+            "type": "string"
+          },
+          "package": {
+            "type": "string"
+          },
+          "channel": {
+            "type": "string"
+          },
+          "source": {
+            "type": "string"
+          },
+          "installedCSV": {
+            "type": "string"
+          },
+          "state": {
+            "type": "string"
+          }
+        }
+      },
+      "ProjectOperators": {
+        "type": "object",
+        "properties": {
+          "subscriptions": {
+            "type": "array",
+            "items": {
+              "$ref": "#/components/schemas/Subscription"
+            }
+          },
+          "note": {
+            "type": "string"
+          }
+        }
+      },
+      "IdleProject": {
+        "type": "object",
+        "properties": {
+          "project": {
+            "type": "string"
+          },
+          "lastActivity": {
+            "type": "string",
+            "format": "date-time"
+          },
+          "source": {
+            "type": "string",
+            "enum": [
+              "event",
+              "annotation",
+              "created"
+            ]
+          },
+          "inactiveSeconds": {
+            "type": "integer"
+          },
+          "inactiveDays": {
+            "type": "integer"
+          },
+          "archived": {
+            "type": "boolean"
+          }
+        }
+      },
+      "ArchiveIdleProjectsCommand": {
+        "type": "object",
+        "properties": {
+          "clusterid": {
+            "type": "string"
+          },
+          "projects": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "minDays": {
+            "type": "integer",
+            "minimum": 1
+          }
+        }
+      },
+      "ArchiveIdleProjectsResult": {
+        "type": "object",
+        "properties": {
+          "archived": {
+            "type": "array",
+            "items": {
+              "type": "string"
+            }
+          },
+          "skipped": {
+            "type": "object",
+            "description": "Reason per project which was not archived",
+            "additionalProperties": {
+              "type": "string"
+            }
+          }
+        }
+      },
+      "ProjectPreview": {
+        "type": "object",
+        "properties": {
+          "projectRequest": {
+            "type": "object",
+            "description": "ProjectRequest which is sent to OpenShift"
+          },
+          "annotations": {
+            "type": "object",
+            "description": "Annotations of the namespace which are written after the creation, e.g. billing, requester and node selector",
+            "additionalProperties": {
+              "type": "string"
+            }
+          },
+          "requester": {
+            "type": "string"
+          },
+          "approvalRequired": {
+            "type": "boolean"
+          },
+          "warnings": {
+            "type": "array",
+            "description": "Warnings which wouldn't block the project",
+            "items": {
+              "type": "string"
+            }
+          }
+        }
+      }
+    }
+  },
+  "security": [
+    {
+      "bearer": []
+    }
+  ],
+  "paths": {
+    "/ose/project": {
+      "post": {
+        "summary": "Create a new project",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NewProjectCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "202": {
+            "description": "The cluster requires an approval, the project has been requested",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid value, e.g. accounting number, data classification or node selector",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "The project already exists (PROJECT_EXISTS)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/manifest": {
+      "post": {
+        "summary": "Create a new project from a ProjectRequest manifest (members of power_user_group only)",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NewProjectManifestCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request, invalid manifest or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid manifest or value, e.g. accounting number or data classification",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Not a power user, cluster not allowed or the cluster requires an approval",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "The project already exists (PROJECT_EXISTS)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/preview": {
+      "post": {
+        "summary": "Validate a new project like /ose/project and return the ProjectRequest and the annotations without creating the project. The new project webhook is not called",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NewProjectCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectPreview"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid value, e.g. accounting number, data classification or node selector",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "Cluster not allowed or project limit reached",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/testproject": {
+      "post": {
+        "summary": "Create a new test project. The project name is prefixed with the username",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/OpenshiftBase"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid project name",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "The project already exists (PROJECT_EXISTS)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/serviceaccount/token": {
+      "post": {
+        "summary": "Create a service account with edit permissions in the project and return its token",
+        "description": "Only for members of the admin_group which are admins of the project. Repeated calls reuse the service account and its token",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/NewServiceAccountTokenCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ServiceAccountTokenResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request, no admin of the project or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not in the admin_group",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/testproject/extend": {
+      "post": {
+        "summary": "Postpone the deletion of a test project to 30 days from today",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/OpenshiftBase"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "The project is not a test project",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects": {
+      "get": {
+        "summary": "List the projects of the user",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "sbb_accounting_number",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "sbb_mega_id",
+            "in": "query",
+            "required": false,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "detailed",
+            "in": "query",
+            "required": false,
+            "description": "Return ProjectSummary objects instead of the names",
+            "schema": {
+              "type": "boolean"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "oneOf": [
+                    {
+                      "type": "array",
+                      "items": {
+                        "type": "string"
+                      }
+                    },
+                    {
+                      "type": "array",
+                      "items": {
+                        "$ref": "#/components/schemas/ProjectSummary"
+                      }
+                    }
+                  ]
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/admins": {
+      "get": {
+        "summary": "List the admins of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/AdminList"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "summary": "Add an admin to a project",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/AddProjectAdminCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid username",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/rolebindings": {
+      "get": {
+        "summary": "List the rolebindings of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/RoleBinding"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/role": {
+      "get": {
+        "summary": "Get the effective role of the current user in a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectRole"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/cost": {
+      "get": {
+        "summary": "Estimate the monthly cost of the quota of a project (cost_model in the config)",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CostEstimate"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/export": {
+      "get": {
+        "summary": "Export the annotations, labels, quota and rolebindings of a project as YAML",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/x-yaml": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectExport"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/operators": {
+      "get": {
+        "summary": "List the operators (OLM subscriptions) of a project. Without OLM, an empty list with a note is returned",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectOperators"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/groups": {
+      "post": {
+        "summary": "Add a LDAP group to a project",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectGroupCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid role or the group does not exist",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "delete": {
+        "summary": "Remove a LDAP group from a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "group",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "role",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            },
+            "description": "admin or operator"
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid role",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/permissions/copy": {
+      "post": {
+        "summary": "Copy the admins and operators (users and groups) of a project to another project. The user must be admin of both projects",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/CopyPermissionsCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CopyPermissionsResult"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid mode or the source and target are the same project",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/info": {
+      "get": {
+        "summary": "Get the billing information of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectInformation"
+                }
+              }
+            },
+            "headers": {
+              "ETag": {
+                "description": "resourceVersion of the namespace, for If-Match on updates",
+                "schema": {
+                  "type": "string"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "summary": "Update the billing information of a project",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/UpdateProjectInformationCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid value, e.g. accounting number or data classification",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "412": {
+            "description": "The project has been changed since the client read it (PRECONDITION_FAILED)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        },
+        "parameters": [
+          {
+            "name": "If-Match",
+            "in": "header",
+            "required": false,
+            "description": "ETag of GET /ose/project/info. The update fails with 412 if the project has been changed since",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ]
+      }
+    },
+    "/ose/projects/info": {
+      "post": {
+        "summary": "Get the project information of multiple projects (at most 100)",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectInformationBatchCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK. Errors of single projects are returned in the result of the project",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/ProjectInformationResult"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Too many projects",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/displayname": {
+      "post": {
+        "summary": "Change the display name of a project. The other metadata is not changed",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/UpdateProjectDisplayNameCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "The display name is too long",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/requests": {
+      "get": {
+        "summary": "List the pending project requests (only for members of project_approval.approver_group)",
+        "tags": [
+          "project"
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/PendingProject"
+                  }
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/requests/approve": {
+      "post": {
+        "summary": "Approve a project request and create the project",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectRequestCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "The request doesn't exist",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/requests/reject": {
+      "post": {
+        "summary": "Reject a project request",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectRequestCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "The request doesn't exist",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/quotas/limits": {
+      "get": {
+        "summary": "Get the default and maximal quota of a cluster",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/QuotaLimits"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/quotas/requests": {
+      "get": {
+        "summary": "List the pending quota requests (only for members of project_approval.approver_group)",
+        "tags": [
+          "project"
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/QuotaRequest"
+                  }
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      },
+      "post": {
+        "summary": "Request a higher quota for a project. The approvers are notified",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/QuotaIncreaseCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "202": {
+            "description": "The quota has been requested and must be approved",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or missing justification",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "The quota is not higher than the current quota or above the limits of the cluster",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "A quota increase for the project has already been requested (CONFLICT)",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/quotas/requests/approve": {
+      "post": {
+        "summary": "Approve a quota request and set the requested quota",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectRequestCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "The requested quota is above the current limits of the cluster",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "The request doesn't exist",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/quotas/requests/reject": {
+      "post": {
+        "summary": "Reject a quota request. The user is notified with the reason",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ProjectRequestCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not an approver",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "404": {
+            "description": "The request doesn't exist",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/quotas/history": {
+      "get": {
+        "summary": "Get the history of the quota changes of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/QuotaChange"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/billinghistory": {
+      "get": {
+        "summary": "Get the history of the billing changes of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/BillingChange"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/events": {
+      "get": {
+        "summary": "Get the newest events of a project",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/ProjectEvent"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/archive": {
+      "post": {
+        "summary": "Archive a project. The workloads are scaled to zero and the project is deleted after the grace period",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/OpenshiftBase"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/project/unarchive": {
+      "post": {
+        "summary": "Remove the archive annotation of a project. The workloads are not scaled up",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/OpenshiftBase"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request or error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/billing": {
+      "get": {
+        "summary": "List the projects of all clusters with an accounting number (only for members of admin_group)",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "billing",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "prefix",
+            "in": "query",
+            "required": false,
+            "description": "Match all accounting numbers starting with billing",
+            "schema": {
+              "type": "boolean"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/BillingProject"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not a portal admin",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/exists": {
+      "get": {
+        "summary": "Check on which clusters a project with the name exists",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "project",
+            "in": "query",
+            "required": true,
+            "description": "The name of the project",
+            "schema": {
+              "type": "string"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK. Clusters which couldn't be checked within project_exists_timeout are in errors",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ProjectExistence"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "The project name is missing",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "Invalid project name",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/terminating": {
+      "get": {
+        "summary": "List the projects of all clusters in the phase Terminating (only for members of admin_group)",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "finalizers",
+            "in": "query",
+            "required": false,
+            "description": "Return the finalizers and conditions which block the deletion",
+            "schema": {
+              "type": "boolean"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/TerminatingProject"
+                  }
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not a portal admin",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "502": {
+            "description": "Error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/cleanup": {
+      "post": {
+        "summary": "Delete the expired archived projects now (only for members of admin_group)",
+        "tags": [
+          "project"
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/CleanupResult"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not a portal admin",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "409": {
+            "description": "The cleanup is already running",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/idle": {
+      "get": {
+        "summary": "List the projects of a cluster by their last activity, the longest inactive first (only for members of admin_group). The last activity is the newest of the events, the openshift.io/last-used annotation and the creation",
+        "tags": [
+          "project"
+        ],
+        "parameters": [
+          {
+            "name": "clusterid",
+            "in": "query",
+            "required": true,
+            "schema": {
+              "type": "string"
+            }
+          },
+          {
+            "name": "mindays",
+            "in": "query",
+            "required": false,
+            "description": "Only return projects which are inactive for at least this number of days",
+            "schema": {
+              "type": "integer"
+            }
+          }
+        ],
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "type": "array",
+                  "items": {
+                    "$ref": "#/components/schemas/IdleProject"
+                  }
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not a portal admin",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "502": {
+            "description": "Error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "/ose/projects/idle/archive": {
+      "post": {
+        "summary": "Archive idle projects, they are deleted by the archive cleanup after the grace period (only for members of admin_group). Projects which were active in the last minDays days are skipped",
+        "tags": [
+          "project"
+        ],
+        "requestBody": {
+          "required": true,
+          "content": {
+            "application/json": {
+              "schema": {
+                "$ref": "#/components/schemas/ArchiveIdleProjectsCommand"
+              }
+            }
+          }
+        },
+        "responses": {
+          "200": {
+            "description": "OK",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ArchiveIdleProjectsResult"
+                }
+              }
+            }
+          },
+          "400": {
+            "description": "Invalid request",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "422": {
+            "description": "minDays is lower than 1",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "403": {
+            "description": "The user is not a portal admin",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          },
+          "502": {
+            "description": "Error from the backend",
+            "content": {
+              "application/json": {
+                "schema": {
+                  "$ref": "#/components/schemas/ApiResponse"
+                }
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
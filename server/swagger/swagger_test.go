@@ -0,0 +1,102 @@
+package swagger
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SchweizerischeBundesbahnen/ssp-backend/server/openshift"
+	"github.com/gin-gonic/gin"
+)
+
+type openAPISpec struct {
+	Paths      map[string]map[string]interface{} `json:"paths"`
+	Components struct {
+		Schemas map[string]struct {
+			Properties map[string]struct {
+				Enum []string `json:"enum"`
+			} `json:"properties"`
+		} `json:"schemas"`
+	} `json:"components"`
+}
+
+func parseSpec(t *testing.T) openAPISpec {
+	var s openAPISpec
+	if err := json.Unmarshal([]byte(spec), &s); err != nil {
+		t.Fatalf("ERROR: the spec is not valid JSON: %v", err)
+	}
+	return s
+}
+
+// errorCodes returns the values of the ErrorCode constants of the common package
+func errorCodes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "../common/errors.go", nil, 0)
+	if err != nil {
+		t.Fatalf("ERROR: can't parse the error codes: %v", err)
+	}
+	var codes []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		if spec, ok := n.(*ast.ValueSpec); ok && strings.HasPrefix(spec.Names[0].Name, "ErrorCode") {
+			code, _ := strconv.Unquote(spec.Values[0].(*ast.BasicLit).Value)
+			codes = append(codes, code)
+		}
+		return true
+	})
+	sort.Strings(codes)
+	return codes
+}
+
+func TestSpecErrorCodes(t *testing.T) {
+	enum := parseSpec(t).Components.Schemas["ApiResponse"].Properties["code"].Enum
+	sort.Strings(enum)
+	codes := errorCodes(t)
+	if len(codes) == 0 || strings.Join(enum, ",") != strings.Join(codes, ",") {
+		t.Errorf("ERROR: the code enum of ApiResponse should be %v, got %v", codes, enum)
+	}
+}
+
+// undocumentedRoutes are the OpenShift routes which are older than the spec. New routes must be documented
+var undocumentedRoutes = map[string]bool{
+	"GET /ose/clusters":            true,
+	"GET /ose/quotas":              true,
+	"POST /ose/quotas":             true,
+	"POST /ose/secret/pull":        true,
+	"POST /ose/serviceaccount":     true,
+	"POST /ose/volume":             true,
+	"POST /ose/volume/grow":        true,
+	"POST /ose/volume/gluster/fix": true,
+	"GET /ose/volume/jobs":         true,
+}
+
+func TestSpecRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	openshift.RegisterRoutes(router.Group("/"))
+	pathParam := regexp.MustCompile(`:([^/]+)`)
+	routes := map[string]bool{}
+	for _, route := range router.Routes() {
+		routes[route.Method+" "+pathParam.ReplaceAllString(route.Path, "{$1}")] = true
+	}
+
+	documented := map[string]bool{}
+	for path, operations := range parseSpec(t).Paths {
+		for method := range operations {
+			route := strings.ToUpper(method) + " " + path
+			documented[route] = true
+			if !routes[route] {
+				t.Errorf("ERROR: %v is documented, but doesn't exist", route)
+			}
+		}
+	}
+	for route := range routes {
+		if !documented[route] && !undocumentedRoutes[route] {
+			t.Errorf("ERROR: %v is not documented", route)
+		}
+	}
+}
@@ -170,9 +170,8 @@ type jobTemplateConfig struct {
 }
 
 func removeBlacklistedParameters(json *gabs.Container) *gabs.Container {
-	cfg := config.Config()
 	var blacklist []string
-	if err := cfg.UnmarshalKey("tower.parameter_blacklist", &blacklist); err != nil {
+	if err := config.UnmarshalKey("tower.parameter_blacklist", &blacklist); err != nil {
 		log.Warn("No Ansible-Tower parameter blacklist found")
 	}
 	for _, p := range blacklist {
@@ -187,10 +186,8 @@ func removeBlacklistedParameters(json *gabs.Container) *gabs.Container {
 }
 
 func checkPermissions(jobTemplate string, json *gabs.Container, username string) error {
-	cfg := config.Config()
-
 	jobTemplateConfigs := []jobTemplateConfig{}
-	if err := cfg.UnmarshalKey("tower.job_templates", &jobTemplateConfigs); err != nil {
+	if err := config.UnmarshalKey("tower.job_templates", &jobTemplateConfigs); err != nil {
 		return err
 	}
 	// Check if the template id is whitelisted in the config file (see sample config)